@@ -0,0 +1,392 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/lusingander/stu/internal/config"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+const (
+	s3Scheme = "s3://"
+	stdinArg = "-"
+)
+
+// runCommand runs a command without the UI, `cp - s3://bucket/key` uploads stdin to the object
+// and `cp path s3://bucket/prefix/` uploads a local file or directory,
+// `cat s3://bucket/key` writes the object to stdout, `get s3://bucket/key [dir]` downloads objects
+// and `credentials s3://bucket/prefix` prints export commands of temporary credentials scoped to the prefix.
+func runCommand(cfg *config.Config, conn *config.ConnectionConfig, args []string) error {
+	switch args[0] {
+	case "cp":
+		return runCopy(cfg, conn, args[1:])
+	case "cat":
+		return runCat(cfg, conn, args[1:])
+	case "get":
+		return runGet(cfg, conn, args[1:])
+	case "credentials":
+		return runCredentials(cfg, conn, args[1:])
+	}
+	return fmt.Errorf("unknown command: %s", args[0])
+}
+
+func runCopy(cfg *config.Config, conn *config.ConnectionConfig, args []string) error {
+	fs := flag.NewFlagSet("cp", flag.ExitOnError)
+	jobs := fs.Int("jobs", cfg.Upload.Concurrency, "number of files uploaded in parallel from a directory")
+	args, err := parseCommandArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 2 {
+		return errors.New("usage: stu cp - s3://bucket/key | stu cp path s3://bucket/prefix/ [--jobs N]")
+	}
+	if args[0] != stdinArg {
+		return uploadPath(cfg, conn, args[0], args[1], *jobs)
+	}
+	bucket, key, err := parseObjectURI(args[1])
+	if err != nil {
+		return err
+	}
+	warnings, err := stu.CheckKey(key)
+	if err != nil {
+		return err
+	}
+	printKeyWarnings(key, warnings)
+	client, err := newS3Client(cfg, conn)
+	if err != nil {
+		return err
+	}
+	// cancel on interrupt so that the multipart upload is aborted
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	_, err = client.UploadObject(ctx, bucket, key, os.Stdin)
+	return err
+}
+
+// printKeyWarnings prints the warnings of stu.CheckKey, the key is used anyway since there is no prompt.
+func printKeyWarnings(key string, warnings []string) {
+	if len(warnings) > 0 {
+		fmt.Fprintf(os.Stderr, "warning: %s: %s\n", key, strings.Join(warnings, ", "))
+	}
+}
+
+// uploadPath uploads a file to the key, or under the prefix if the URI ends with /,
+// and the files of a directory under the prefix keeping the hierarchy.
+func uploadPath(cfg *config.Config, conn *config.ConnectionConfig, path, uri string, jobs int) error {
+	if jobs < 1 {
+		return errors.New("--jobs must be at least 1")
+	}
+	bucket, prefix, err := parseS3URI(uri)
+	if err != nil {
+		return err
+	}
+	files, err := stu.PlanUpload(path)
+	if err != nil {
+		return err
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	switch {
+	case !fi.IsDir() && prefix != "" && !strings.HasSuffix(prefix, "/"):
+		files[0].Rel, prefix = prefix, ""
+	case fi.IsDir() && prefix != "" && !strings.HasSuffix(prefix, "/"):
+		prefix += "/"
+	}
+	for _, w := range stu.UploadKeyWarnings(files, prefix) {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+	}
+	client, err := newS3Client(cfg, conn)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	result, err := stu.UploadFiles(ctx, client, files, bucket, prefix, jobs, func(p stu.Progress) {
+		fmt.Fprintf(os.Stderr, "[%d/%d] %s\n", p.Items, len(files), p.Key)
+	}, nil)
+	for _, f := range result.Failed {
+		fmt.Fprintf(os.Stderr, "failed: %s: %s\n", f.Rel, f.Err)
+	}
+	fmt.Fprintf(os.Stderr, "%d files (%d bytes) uploaded, %d failed\n", result.Uploaded, result.Bytes, len(result.Failed))
+	if err != nil {
+		return err
+	}
+	if len(result.Failed) > 0 {
+		return fmt.Errorf("failed to upload %d files", len(result.Failed))
+	}
+	return nil
+}
+
+func runCat(cfg *config.Config, conn *config.ConnectionConfig, args []string) error {
+	fs := flag.NewFlagSet("cat", flag.ExitOnError)
+	byteRange := fs.String("range", "", "write only the range of the object, e.g. bytes=0-1023")
+	args, err := parseCommandArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return errors.New("usage: stu cat s3://bucket/key [--range bytes=start-end]")
+	}
+	bucket, key, err := parseObjectURI(args[0])
+	if err != nil {
+		return err
+	}
+	client, err := newS3Client(cfg, conn)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var r io.ReadCloser
+	if *byteRange == "" {
+		r, err = client.GetObject(ctx, bucket, key)
+	} else {
+		start, end, perr := parseByteRange(*byteRange)
+		if perr != nil {
+			return perr
+		}
+		if start < 0 || end < 0 {
+			head, err := client.HeadObject(ctx, bucket, key)
+			if err != nil {
+				return err
+			}
+			start, end = resolveByteRange(start, end, head.Size)
+		}
+		if start > end {
+			return nil
+		}
+		r, err = client.GetObjectRange(ctx, bucket, key, start, end-start+1)
+	}
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	_, err = io.Copy(os.Stdout, r)
+	return err
+}
+
+func runGet(cfg *config.Config, conn *config.ConnectionConfig, args []string) error {
+	fs := flag.NewFlagSet("get", flag.ExitOnError)
+	recursive := fs.Bool("recursive", false, "download all objects under the prefix")
+	jobs := fs.Int("jobs", 4, "number of objects downloaded in parallel with --recursive")
+	var include, exclude stringsFlag
+	fs.Var(&include, "include", "download only objects matching the pattern with --recursive, can be repeated")
+	fs.Var(&exclude, "exclude", "skip objects matching the pattern with --recursive, can be repeated")
+	args, err := parseCommandArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 && len(args) != 2 {
+		return errors.New("usage: stu get s3://bucket/key [path] | stu get s3://bucket/prefix/ [dir] --recursive [--jobs N] [--include pattern] [--exclude pattern]")
+	}
+	bucket, key, err := parseS3URI(args[0])
+	if err != nil {
+		return err
+	}
+	dst := "."
+	if len(args) == 2 {
+		dst = args[1]
+	}
+	client, err := newS3Client(cfg, conn)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if !*recursive {
+		if key == "" || strings.HasSuffix(key, "/") {
+			return errors.New("use --recursive to download a prefix")
+		}
+		return getObject(ctx, client, bucket, key, dst)
+	}
+	if key != "" && !strings.HasSuffix(key, "/") {
+		key += "/"
+	}
+	if *jobs < 1 {
+		return errors.New("--jobs must be at least 1")
+	}
+	filter, err := stu.NewKeyFilter(key, include, exclude)
+	if err != nil {
+		return err
+	}
+	result, err := stu.DownloadPrefix(ctx, client, bucket, key, dst, filter.Match, *jobs, nil)
+	for _, f := range result.Failed {
+		fmt.Fprintf(os.Stderr, "failed: %s: %s\n", f.Key, f.Err)
+	}
+	fmt.Fprintf(os.Stderr, "%d files (%d bytes) downloaded, %d failed\n", result.Downloaded, result.Bytes, len(result.Failed))
+	if err != nil {
+		return err
+	}
+	if len(result.Failed) > 0 {
+		return fmt.Errorf("failed to download %d objects", len(result.Failed))
+	}
+	return nil
+}
+
+func runCredentials(cfg *config.Config, conn *config.ConnectionConfig, args []string) error {
+	fs := flag.NewFlagSet("credentials", flag.ExitOnError)
+	write := fs.Bool("write", false, "allow put and delete in addition to list and get")
+	duration := fs.Duration("duration", time.Duration(cfg.Credentials.DurationSeconds)*time.Second, "lifetime of the credentials")
+	roleARN := fs.String("role-arn", cfg.Credentials.RoleARN, "assume the role instead of GetFederationToken")
+	args, err := parseCommandArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return errors.New("usage: stu credentials s3://bucket/prefix [--write] [--duration 1h] [--role-arn arn]")
+	}
+	bucket, prefix, err := parseS3URI(args[0])
+	if err != nil {
+		return err
+	}
+	client, err := newS3Client(cfg, conn)
+	if err != nil {
+		return err
+	}
+	req := &stu.CredentialsRequest{
+		Bucket:   bucket,
+		Prefix:   prefix,
+		Write:    *write,
+		Duration: *duration,
+		RoleARN:  *roleARN,
+	}
+	creds, err := client.IssueCredentials(context.Background(), req)
+	if aerr := stu.NewAuditLog(cfg.Audit.Path).Record("issue-credentials", bucket, prefix, req.Access(), err); aerr != nil && err == nil {
+		err = aerr
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "credentials for s3://%s/%s expire at %s\n", bucket, prefix, creds.Expiration.Local().Format(time.RFC3339))
+	fmt.Print(stu.ExportCommands(creds))
+	return nil
+}
+
+// getObject downloads the object to path, or into path if it is a directory.
+func getObject(ctx context.Context, client stu.Client, bucket, key, path string) error {
+	if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+		path = filepath.Join(path, filepath.Base(filepath.FromSlash(key)))
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	_, err = stu.DownloadObject(ctx, client, bucket, key, f, nil)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return err
+}
+
+// stringsFlag collects the values of a flag which can be repeated.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+// parseCommandArgs parses the flags which may be placed after the arguments, and returns the arguments.
+func parseCommandArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	rest := make([]string, 0)
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return rest, nil
+		}
+		rest = append(rest, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// parseByteRange parses "bytes=start-end" in the form of the Range header, an omitted end or start is -1.
+// "bytes=-n" means the last n bytes, so start is -1 and end is n.
+func parseByteRange(s string) (int64, int64, error) {
+	invalid := fmt.Errorf("invalid range: %s", s)
+	spec := strings.TrimPrefix(s, "bytes=")
+	i := strings.Index(spec, "-")
+	if spec == s || i < 0 || spec == "-" {
+		return 0, 0, invalid
+	}
+	start, end := int64(-1), int64(-1)
+	var err error
+	if i > 0 {
+		if start, err = strconv.ParseInt(spec[:i], 10, 64); err != nil || start < 0 {
+			return 0, 0, invalid
+		}
+	}
+	if i < len(spec)-1 {
+		if end, err = strconv.ParseInt(spec[i+1:], 10, 64); err != nil || end < 0 {
+			return 0, 0, invalid
+		}
+	}
+	if start >= 0 && end >= 0 && start > end {
+		return 0, 0, invalid
+	}
+	return start, end, nil
+}
+
+// resolveByteRange returns the inclusive range in the object of size, start is greater than end if it is empty.
+func resolveByteRange(start, end, size int64) (int64, int64) {
+	if start < 0 {
+		start, end = size-end, size-1
+		if start < 0 {
+			start = 0
+		}
+		return start, end
+	}
+	if end < 0 || end >= size {
+		end = size - 1
+	}
+	return start, end
+}
+
+func parseObjectURI(s string) (string, string, error) {
+	bucket, key, err := parseS3URI(s)
+	if err != nil {
+		return "", "", err
+	}
+	if key == "" || strings.HasSuffix(key, "/") {
+		return "", "", fmt.Errorf("S3 URI must be s3://bucket/key: %s", s)
+	}
+	return bucket, key, nil
+}
+
+// parseS3URI splits s3://bucket/key, the key may be empty or a prefix.
+func parseS3URI(s string) (string, string, error) {
+	if !strings.HasPrefix(s, s3Scheme) {
+		return "", "", fmt.Errorf("not an S3 URI: %s", s)
+	}
+	bucket, key := strings.TrimPrefix(s, s3Scheme), ""
+	if i := strings.Index(bucket, "/"); i >= 0 {
+		bucket, key = bucket[:i], bucket[i+1:]
+	}
+	if bucket == "" {
+		return "", "", fmt.Errorf("bucket is empty: %s", s)
+	}
+	return bucket, key, nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import "testing"
+
+func TestParseByteRange(t *testing.T) {
+	tests := []struct {
+		s       string
+		start   int64
+		end     int64
+		wantErr bool
+	}{
+		{"bytes=0-99", 0, 99, false},
+		{"bytes=100-", 100, -1, false},
+		{"bytes=-500", -1, 500, false},
+		{"bytes=5-5", 5, 5, false},
+		{"bytes=10-5", 0, 0, true},
+		{"bytes=-", 0, 0, true},
+		{"bytes=", 0, 0, true},
+		{"bytes=a-b", 0, 0, true},
+		{"bytes=-1-5", 0, 0, true},
+		{"bytes=0--5", 0, 0, true},
+		{"0-99", 0, 0, true},
+		{"", 0, 0, true},
+	}
+	for _, tt := range tests {
+		start, end, err := parseByteRange(tt.s)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseByteRange(%q) = %d, %d, want error", tt.s, start, end)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseByteRange(%q) returned error: %v", tt.s, err)
+			continue
+		}
+		if start != tt.start || end != tt.end {
+			t.Errorf("parseByteRange(%q) = %d, %d, want %d, %d", tt.s, start, end, tt.start, tt.end)
+		}
+	}
+}
+
+func TestResolveByteRange(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end int64
+		size       int64
+		wantStart  int64
+		wantEnd    int64
+	}{
+		{"within the object", 0, 99, 1000, 0, 99},
+		{"open end", 100, -1, 1000, 100, 999},
+		{"end after the object", 900, 2000, 1000, 900, 999},
+		{"suffix", -1, 100, 1000, 900, 999},
+		{"suffix longer than the object", -1, 2000, 1000, 0, 999},
+		{"empty suffix", -1, 0, 1000, 1000, 999},
+		{"start after the object", 1000, -1, 1000, 1000, 999},
+		{"empty object", 0, -1, 0, 0, -1},
+	}
+	for _, tt := range tests {
+		start, end := resolveByteRange(tt.start, tt.end, tt.size)
+		if start != tt.wantStart || end != tt.wantEnd {
+			t.Errorf("%s: resolveByteRange(%d, %d, %d) = %d, %d, want %d, %d",
+				tt.name, tt.start, tt.end, tt.size, start, end, tt.wantStart, tt.wantEnd)
+		}
+	}
+}
+
+func TestParseS3URI(t *testing.T) {
+	tests := []struct {
+		s       string
+		bucket  string
+		key     string
+		wantErr bool
+	}{
+		{"s3://bucket/dir/file.txt", "bucket", "dir/file.txt", false},
+		{"s3://bucket/dir/", "bucket", "dir/", false},
+		{"s3://bucket/", "bucket", "", false},
+		{"s3://bucket", "bucket", "", false},
+		{"s3://bucket//file.txt", "bucket", "/file.txt", false},
+		{"s3:///file.txt", "", "", true},
+		{"s3://", "", "", true},
+		{"S3://bucket/file.txt", "", "", true},
+		{"bucket/file.txt", "", "", true},
+		{"", "", "", true},
+	}
+	for _, tt := range tests {
+		bucket, key, err := parseS3URI(tt.s)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseS3URI(%q) = %q, %q, want error", tt.s, bucket, key)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseS3URI(%q) returned error: %v", tt.s, err)
+			continue
+		}
+		if bucket != tt.bucket || key != tt.key {
+			t.Errorf("parseS3URI(%q) = %q, %q, want %q, %q", tt.s, bucket, key, tt.bucket, tt.key)
+		}
+	}
+}
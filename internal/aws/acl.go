@@ -0,0 +1,59 @@
+package aws
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+const (
+	errorCodeOwnershipControlsNotFound = "OwnershipControlsNotFoundError"
+)
+
+// PutObjectACL sets the canned ACL of the object, if the object ownership of the bucket allows ACLs.
+func (c *S3Client) PutObjectACL(ctx context.Context, bucket, key, acl string) error {
+	if _, err := c.writableSettings(bucket); err != nil {
+		return err
+	}
+	client := c.bucketClient(ctx, bucket)
+	enabled, err := aclsEnabled(ctx, client, bucket)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return stu.ErrACLsDisabled
+	}
+	_, err = client.PutObjectAcl(ctx, &s3.PutObjectAclInput{
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		ACL:          types.ObjectCannedACL(acl),
+		RequestPayer: c.requestPayer(bucket),
+	})
+	return err
+}
+
+// aclsEnabled is false if the object ownership is BucketOwnerEnforced, which is the default of new buckets.
+// Buckets without ownership controls allow ACLs.
+func aclsEnabled(ctx context.Context, client *s3.Client, bucket string) (bool, error) {
+	output, err := client.GetBucketOwnershipControls(ctx, &s3.GetBucketOwnershipControlsInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		if isErrorCode(err, errorCodeOwnershipControlsNotFound) {
+			return true, nil
+		}
+		return false, err
+	}
+	if output.OwnershipControls == nil {
+		return true, nil
+	}
+	for _, rule := range output.OwnershipControls.Rules {
+		if rule.ObjectOwnership == types.ObjectOwnershipBucketOwnerEnforced {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,121 @@
+package aws
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+const (
+	maxObjectEvents = 50
+)
+
+// TrailOptions locates the log files which a CloudTrail trail with S3 data events delivers to a bucket.
+type TrailOptions struct {
+	Bucket string
+	// key prefix of the trail, without AWSLogs/
+	Prefix string
+	// set for organization trails
+	OrganizationID string
+	// the account of the credentials is used if empty
+	AccountID string
+	// the region of the bucket of the object is used if empty
+	Region string
+	// days of logs to read, from today (UTC)
+	Days int
+}
+
+// ObjectEvents reads the log files of the trail, latest first, until maxObjectEvents events of the object are found.
+// Events are delivered to the trail bucket with a delay of about 5 minutes.
+func (c *S3Client) ObjectEvents(ctx context.Context, bucket, key string) ([]*stu.ObjectEvent, error) {
+	t := c.trail
+	if t == nil {
+		return nil, stu.ErrActivityNotConfigured
+	}
+	accountID := t.AccountID
+	if accountID == "" {
+		output, err := c.sts.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+		if err != nil {
+			return nil, err
+		}
+		accountID = aws.ToString(output.Account)
+	}
+	region := t.Region
+	if region == "" {
+		region = c.bucketRegion(ctx, bucket)
+	}
+	if region == "" {
+		return nil, errors.New("region of the trail is not configured")
+	}
+	base := path.Join(t.Prefix, "AWSLogs", t.OrganizationID, accountID, "CloudTrail", region)
+
+	events := make([]*stu.ObjectEvent, 0)
+	today := time.Now().UTC()
+	for d := 0; d < t.Days && len(events) < maxObjectEvents; d++ {
+		dayPrefix := base + "/" + today.AddDate(0, 0, -d).Format("2006/01/02") + "/"
+		keys, err := c.listKeys(ctx, t.Bucket, dayPrefix)
+		if err != nil {
+			return nil, err
+		}
+		// keys contain the delivery time
+		sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+		for _, k := range keys {
+			found, err := c.readTrailLog(ctx, t.Bucket, k, bucket, key)
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, found...)
+			if len(events) >= maxObjectEvents {
+				break
+			}
+		}
+	}
+	stu.SortObjectEvents(events)
+	if len(events) > maxObjectEvents {
+		events = events[:maxObjectEvents]
+	}
+	return events, nil
+}
+
+func (c *S3Client) listKeys(ctx context.Context, bucket, prefix string) ([]string, error) {
+	p := s3.NewListObjectsV2Paginator(c.bucketClient(ctx, bucket), &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	})
+	keys := make([]string, 0)
+	for p.HasMorePages() {
+		output, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range output.Contents {
+			keys = append(keys, aws.ToString(obj.Key))
+		}
+	}
+	return keys, nil
+}
+
+func (c *S3Client) readTrailLog(ctx context.Context, trailBucket, logKey, bucket, key string) ([]*stu.ObjectEvent, error) {
+	output, err := c.bucketClient(ctx, trailBucket).GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(trailBucket),
+		Key:    aws.String(logKey),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer output.Body.Close()
+	r, err := gzip.NewReader(output.Body)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return stu.ParseTrailEvents(r, bucket, key)
+}
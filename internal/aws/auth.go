@@ -0,0 +1,215 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/ec2rolecreds"
+	"github.com/aws/aws-sdk-go-v2/credentials/endpointcreds"
+	"github.com/aws/aws-sdk-go-v2/credentials/ssocreds"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sso"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/lusingander/stu/internal/config"
+)
+
+const (
+	ecsCredentialsHost = "http://169.254.170.2"
+)
+
+// AuthProvider resolves credentials in one of the supported ways.
+type AuthProvider interface {
+	Name() string
+	// LoadOptions are applied when loading the shared config.
+	LoadOptions() []func(*awsconfig.LoadOptions) error
+	// Credentials returns the provider which replaces the one resolved from the loaded config,
+	// or nil to keep it.
+	Credentials(cfg aws.Config) (aws.CredentialsProvider, error)
+}
+
+func NewAuthProvider(c *config.AuthConfig) (AuthProvider, error) {
+	switch c.Provider {
+	case "", "default":
+		return &defaultAuth{}, nil
+	case "static":
+		if c.AccessKeyID == "" || c.SecretAccessKey == "" {
+			return nil, authConfigError(c.Provider, "access_key_id and secret_access_key are required")
+		}
+		return &staticAuth{c}, nil
+	case "profile":
+		if c.Profile == "" {
+			return nil, authConfigError(c.Provider, "profile is required")
+		}
+		return &profileAuth{c}, nil
+	case "sso":
+		if c.SSOStartURL == "" || c.SSORegion == "" || c.SSOAccountID == "" || c.SSORoleName == "" {
+			return nil, authConfigError(c.Provider, "sso_start_url, sso_region, sso_account_id and sso_role_name are required")
+		}
+		return &ssoAuth{c}, nil
+	case "assume_role":
+		if c.RoleARN == "" {
+			return nil, authConfigError(c.Provider, "role_arn is required")
+		}
+		return &assumeRoleAuth{c}, nil
+	case "web_identity":
+		if c.RoleARN == "" || c.WebIdentityTokenFile == "" {
+			return nil, authConfigError(c.Provider, "role_arn and web_identity_token_file are required")
+		}
+		return &webIdentityAuth{c}, nil
+	case "ec2":
+		return &ec2Auth{}, nil
+	case "ecs":
+		return &ecsAuth{}, nil
+	}
+	return nil, fmt.Errorf("unknown auth provider: %s", c.Provider)
+}
+
+func authConfigError(provider, msg string) error {
+	return fmt.Errorf("invalid %s auth config: %s", provider, msg)
+}
+
+// namedCredentials adds the name of the provider to errors,
+// because the error from the SDK does not tell which way of authentication was tried.
+type namedCredentials struct {
+	name     string
+	provider aws.CredentialsProvider
+}
+
+func (c *namedCredentials) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	creds, err := c.provider.Retrieve(ctx)
+	if err != nil {
+		return aws.Credentials{}, fmt.Errorf("failed to retrieve credentials with %s auth provider: %w", c.name, err)
+	}
+	return creds, nil
+}
+
+func loadConfig(ctx context.Context, auth AuthProvider) (aws.Config, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, auth.LoadOptions()...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("failed to load config for %s auth provider: %w", auth.Name(), err)
+	}
+	provider, err := auth.Credentials(cfg)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("failed to set up %s auth provider: %w", auth.Name(), err)
+	}
+	if provider == nil {
+		provider = cfg.Credentials
+	}
+	if provider != nil {
+		cfg.Credentials = aws.NewCredentialsCache(&namedCredentials{name: auth.Name(), provider: provider})
+	}
+	return cfg, nil
+}
+
+type defaultAuth struct{}
+
+func (*defaultAuth) Name() string { return "default" }
+
+func (*defaultAuth) LoadOptions() []func(*awsconfig.LoadOptions) error { return nil }
+
+func (*defaultAuth) Credentials(aws.Config) (aws.CredentialsProvider, error) { return nil, nil }
+
+type staticAuth struct {
+	c *config.AuthConfig
+}
+
+func (*staticAuth) Name() string { return "static" }
+
+func (*staticAuth) LoadOptions() []func(*awsconfig.LoadOptions) error { return nil }
+
+func (a *staticAuth) Credentials(aws.Config) (aws.CredentialsProvider, error) {
+	return credentials.NewStaticCredentialsProvider(a.c.AccessKeyID, a.c.SecretAccessKey, a.c.SessionToken), nil
+}
+
+type profileAuth struct {
+	c *config.AuthConfig
+}
+
+func (*profileAuth) Name() string { return "profile" }
+
+func (a *profileAuth) LoadOptions() []func(*awsconfig.LoadOptions) error {
+	return []func(*awsconfig.LoadOptions) error{awsconfig.WithSharedConfigProfile(a.c.Profile)}
+}
+
+func (*profileAuth) Credentials(aws.Config) (aws.CredentialsProvider, error) { return nil, nil }
+
+type ssoAuth struct {
+	c *config.AuthConfig
+}
+
+func (*ssoAuth) Name() string { return "sso" }
+
+func (*ssoAuth) LoadOptions() []func(*awsconfig.LoadOptions) error { return nil }
+
+func (a *ssoAuth) Credentials(cfg aws.Config) (aws.CredentialsProvider, error) {
+	client := sso.NewFromConfig(cfg, func(o *sso.Options) {
+		o.Region = a.c.SSORegion
+	})
+	return ssocreds.New(client, a.c.SSOAccountID, a.c.SSORoleName, a.c.SSOStartURL), nil
+}
+
+type assumeRoleAuth struct {
+	c *config.AuthConfig
+}
+
+func (*assumeRoleAuth) Name() string { return "assume_role" }
+
+func (*assumeRoleAuth) LoadOptions() []func(*awsconfig.LoadOptions) error { return nil }
+
+func (a *assumeRoleAuth) Credentials(cfg aws.Config) (aws.CredentialsProvider, error) {
+	// the role is assumed with the credentials resolved by the default chain
+	return stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), a.c.RoleARN, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = a.c.SessionName
+		if a.c.ExternalID != "" {
+			o.ExternalID = aws.String(a.c.ExternalID)
+		}
+	}), nil
+}
+
+type webIdentityAuth struct {
+	c *config.AuthConfig
+}
+
+func (*webIdentityAuth) Name() string { return "web_identity" }
+
+func (*webIdentityAuth) LoadOptions() []func(*awsconfig.LoadOptions) error { return nil }
+
+func (a *webIdentityAuth) Credentials(cfg aws.Config) (aws.CredentialsProvider, error) {
+	token := stscreds.IdentityTokenFile(a.c.WebIdentityTokenFile)
+	return stscreds.NewWebIdentityRoleProvider(sts.NewFromConfig(cfg), a.c.RoleARN, token, func(o *stscreds.WebIdentityRoleOptions) {
+		o.RoleSessionName = a.c.SessionName
+	}), nil
+}
+
+type ec2Auth struct{}
+
+func (*ec2Auth) Name() string { return "ec2" }
+
+func (*ec2Auth) LoadOptions() []func(*awsconfig.LoadOptions) error { return nil }
+
+func (*ec2Auth) Credentials(aws.Config) (aws.CredentialsProvider, error) {
+	return ec2rolecreds.New(), nil
+}
+
+type ecsAuth struct{}
+
+func (*ecsAuth) Name() string { return "ecs" }
+
+func (*ecsAuth) LoadOptions() []func(*awsconfig.LoadOptions) error { return nil }
+
+func (*ecsAuth) Credentials(aws.Config) (aws.CredentialsProvider, error) {
+	endpoint := os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI")
+	if rel := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); rel != "" {
+		endpoint = ecsCredentialsHost + rel
+	}
+	if endpoint == "" {
+		return nil, fmt.Errorf("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI or AWS_CONTAINER_CREDENTIALS_FULL_URI is not set")
+	}
+	return endpointcreds.New(endpoint, func(o *endpointcreds.Options) {
+		o.AuthorizationToken = os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN")
+	}), nil
+}
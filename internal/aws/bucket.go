@@ -0,0 +1,32 @@
+package aws
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+)
+
+const (
+	errorCodeNoSuchBucketPolicy = "NoSuchBucketPolicy"
+)
+
+func (c *S3Client) GetBucketPolicy(ctx context.Context, bucket string) (string, error) {
+	output, err := c.bucketClient(ctx, bucket).GetBucketPolicy(ctx, &s3.GetBucketPolicyInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		if isErrorCode(err, errorCodeNoSuchBucketPolicy) {
+			return "", nil
+		}
+		return "", err
+	}
+	return aws.ToString(output.Policy), nil
+}
+
+func isErrorCode(err error, code string) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == code
+}
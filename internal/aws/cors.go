@@ -0,0 +1,37 @@
+package aws
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+const (
+	errorCodeNoSuchCORSConfiguration = "NoSuchCORSConfiguration"
+)
+
+func (c *S3Client) GetBucketCORS(ctx context.Context, bucket string) ([]*stu.CORSRule, error) {
+	output, err := c.bucketClient(ctx, bucket).GetBucketCors(ctx, &s3.GetBucketCorsInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		if isErrorCode(err, errorCodeNoSuchCORSConfiguration) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	rules := make([]*stu.CORSRule, len(output.CORSRules))
+	for i, r := range output.CORSRules {
+		rules[i] = &stu.CORSRule{
+			ID:             aws.ToString(r.ID),
+			AllowedOrigins: r.AllowedOrigins,
+			AllowedMethods: r.AllowedMethods,
+			AllowedHeaders: r.AllowedHeaders,
+			ExposeHeaders:  r.ExposeHeaders,
+			MaxAgeSeconds:  r.MaxAgeSeconds,
+		}
+	}
+	return rules, nil
+}
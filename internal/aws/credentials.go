@@ -0,0 +1,62 @@
+package aws
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/aws-sdk-go-v2/service/sts/types"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+const (
+	// names of the federated user and the role session
+	credentialsSessionName = "stu-scoped"
+)
+
+// IssueCredentials returns temporary credentials whose permissions are the intersection of the scoped policy
+// and the permissions of the current credentials (or the role).
+func (c *S3Client) IssueCredentials(ctx context.Context, req *stu.CredentialsRequest) (*stu.TemporaryCredentials, error) {
+	if req.Write {
+		if _, err := c.writableSettings(req.Bucket); err != nil {
+			return nil, err
+		}
+	}
+	policy, err := stu.ScopedPolicy(req)
+	if err != nil {
+		return nil, err
+	}
+	var duration *int32
+	if req.Duration > 0 {
+		duration = aws.Int32(int32(req.Duration.Seconds()))
+	}
+	var creds *types.Credentials
+	if req.RoleARN != "" {
+		output, err := c.sts.AssumeRole(ctx, &sts.AssumeRoleInput{
+			RoleArn:         aws.String(req.RoleARN),
+			RoleSessionName: aws.String(credentialsSessionName),
+			Policy:          aws.String(policy),
+			DurationSeconds: duration,
+		})
+		if err != nil {
+			return nil, err
+		}
+		creds = output.Credentials
+	} else {
+		output, err := c.sts.GetFederationToken(ctx, &sts.GetFederationTokenInput{
+			Name:            aws.String(credentialsSessionName),
+			Policy:          aws.String(policy),
+			DurationSeconds: duration,
+		})
+		if err != nil {
+			return nil, err
+		}
+		creds = output.Credentials
+	}
+	return &stu.TemporaryCredentials{
+		AccessKeyID:     aws.ToString(creds.AccessKeyId),
+		SecretAccessKey: aws.ToString(creds.SecretAccessKey),
+		SessionToken:    aws.ToString(creds.SessionToken),
+		Expiration:      aws.ToTime(creds.Expiration),
+	}, nil
+}
@@ -0,0 +1,39 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+func (c *S3Client) DeleteObjects(ctx context.Context, bucket string, keys []string) ([]*stu.DeleteFailure, error) {
+	if _, err := c.writableSettings(bucket); err != nil {
+		return nil, err
+	}
+	objects := make([]types.ObjectIdentifier, len(keys))
+	for i, key := range keys {
+		objects[i] = types.ObjectIdentifier{Key: aws.String(key)}
+	}
+	defer c.cache.deleteObjects(bucket)
+	output, err := c.bucketClient(ctx, bucket).DeleteObjects(ctx, &s3.DeleteObjectsInput{
+		Bucket: aws.String(bucket),
+		// only the failed objects are returned
+		Delete:       &types.Delete{Objects: objects, Quiet: true},
+		RequestPayer: c.requestPayer(bucket),
+	})
+	if err != nil {
+		return nil, err
+	}
+	failures := make([]*stu.DeleteFailure, len(output.Errors))
+	for i, e := range output.Errors {
+		failures[i] = &stu.DeleteFailure{
+			Key: aws.ToString(e.Key),
+			Err: fmt.Errorf("%s: %s", aws.ToString(e.Code), aws.ToString(e.Message)),
+		}
+	}
+	return failures, nil
+}
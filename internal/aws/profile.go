@@ -0,0 +1,68 @@
+package aws
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"sort"
+	"strings"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+)
+
+// Profiles returns the names of the profiles in the shared config and credentials files.
+func Profiles() ([]string, error) {
+	seen := make(map[string]bool)
+	configFile := os.Getenv("AWS_CONFIG_FILE")
+	if configFile == "" {
+		configFile = awsconfig.DefaultSharedConfigFilename()
+	}
+	if err := readProfiles(configFile, "profile ", seen); err != nil {
+		return nil, err
+	}
+	credentialsFile := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if credentialsFile == "" {
+		credentialsFile = awsconfig.DefaultSharedCredentialsFilename()
+	}
+	if err := readProfiles(credentialsFile, "", seen); err != nil {
+		return nil, err
+	}
+	profiles := make([]string, 0, len(seen))
+	for p := range seen {
+		profiles = append(profiles, p)
+	}
+	sort.Strings(profiles)
+	return profiles, nil
+}
+
+// readProfiles adds the section names of the file, prefix is removed from the names except for default.
+// Other sections such as [sso-session name] are skipped.
+func readProfiles(path, prefix string, seen map[string]bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if !strings.HasPrefix(line, "[") || !strings.HasSuffix(line, "]") {
+			continue
+		}
+		name := strings.TrimSpace(line[1 : len(line)-1])
+		if name != awsconfig.DefaultSharedConfigProfile {
+			if !strings.HasPrefix(name, prefix) {
+				continue
+			}
+			name = strings.TrimSpace(strings.TrimPrefix(name, prefix))
+		}
+		if name != "" && !strings.Contains(name, " ") {
+			seen[name] = true
+		}
+	}
+	return sc.Err()
+}
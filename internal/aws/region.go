@@ -0,0 +1,100 @@
+package aws
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// regionClients keeps a client for each region of the buckets,
+// since requests to a bucket in another region fail with a redirect.
+type regionClients struct {
+	defaultClient *s3.Client
+	defaultRegion string
+	newClient     func(region string) *s3.Client
+
+	mu      sync.Mutex
+	clients map[string]*s3.Client
+	// region by bucket, empty if the default client is used
+	buckets map[string]string
+}
+
+func newRegionClients(client *s3.Client, region string, newClient func(region string) *s3.Client) *regionClients {
+	return &regionClients{
+		defaultClient: client,
+		defaultRegion: region,
+		newClient:     newClient,
+		clients:       make(map[string]*s3.Client),
+		buckets:       make(map[string]string),
+	}
+}
+
+func (c *S3Client) bucketClient(ctx context.Context, bucket string) *s3.Client {
+	if c.regions == nil {
+		return c.client
+	}
+	return c.regions.client(ctx, bucket)
+}
+
+// client returns the client of the region of the bucket, which is resolved with GetBucketLocation on first use.
+// The default client is used if the location cannot be read, e.g. without s3:GetBucketLocation permission.
+func (r *regionClients) client(ctx context.Context, bucket string) *s3.Client {
+	r.mu.Lock()
+	region, ok := r.buckets[bucket]
+	r.mu.Unlock()
+	if !ok {
+		region = r.bucketRegion(ctx, bucket)
+		if ctx.Err() != nil {
+			return r.defaultClient
+		}
+		r.mu.Lock()
+		r.buckets[bucket] = region
+		r.mu.Unlock()
+	}
+	if region == "" || region == r.defaultRegion {
+		return r.defaultClient
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	client, ok := r.clients[region]
+	if !ok {
+		client = r.newClient(region)
+		r.clients[region] = client
+	}
+	return client
+}
+
+// bucketRegion returns the region of the bucket, or empty if it is not known such as with a custom endpoint.
+func (c *S3Client) bucketRegion(ctx context.Context, bucket string) string {
+	if c.regions == nil {
+		return ""
+	}
+	c.regions.client(ctx, bucket)
+	c.regions.mu.Lock()
+	defer c.regions.mu.Unlock()
+	if region := c.regions.buckets[bucket]; region != "" {
+		return region
+	}
+	return c.regions.defaultRegion
+}
+
+func (r *regionClients) bucketRegion(ctx context.Context, bucket string) string {
+	output, err := r.defaultClient.GetBucketLocation(ctx, &s3.GetBucketLocationInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		return ""
+	}
+	switch region := string(output.LocationConstraint); region {
+	case "":
+		// buckets in us-east-1 have no location constraint
+		return "us-east-1"
+	case "EU":
+		return "eu-west-1"
+	default:
+		return region
+	}
+}
@@ -2,26 +2,48 @@ package aws
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
 	"github.com/lusingander/stu/internal/stu"
 )
 
 const (
-	localstackUrl = "http://localhost:4572"
-	region        = "ap-northeast-1"
+	appName       = "stu"
+	defaultRegion = "us-east-1"
 	delimiter     = "/"
+
+	maxCopyObjectSize = 5 * 1024 * 1024 * 1024
+	copyPartSize      = 512 * 1024 * 1024
+	copyConcurrency   = 4
 )
 
 type S3Client struct {
-	client *s3.Client
-	ctx    context.Context
-	cache  *cacheMap
+	client  *s3.Client
+	regions *regionClients
+	sts     *sts.Client
+	trail   *TrailOptions
+	ctx     context.Context
+	cache   *cacheMap
+	limiter *stu.RateLimiter
+	buckets *stu.BucketOverrides
 }
 
+// cacheMap is shared by the UI and the workers of recursive operations.
 type cacheMap struct {
+	mu      sync.Mutex
 	buckets []*stu.BucketItem
 	objects map[string][]*stu.ObjectItem
 }
@@ -34,6 +56,8 @@ func newCacheMap() *cacheMap {
 }
 
 func (m *cacheMap) getBuckets() ([]*stu.BucketItem, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.buckets == nil {
 		return nil, false
 	}
@@ -41,58 +65,190 @@ func (m *cacheMap) getBuckets() ([]*stu.BucketItem, bool) {
 }
 
 func (m *cacheMap) putBuckets(items []*stu.BucketItem) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.buckets = items
 }
 
 func (m *cacheMap) getObjects(bucket, prefix string) ([]*stu.ObjectItem, bool) {
 	key := m.objectMapKey(bucket, prefix)
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	is, ok := m.objects[key]
 	return is, ok
 }
 
 func (m *cacheMap) putObjects(bucket, prefix string, items []*stu.ObjectItem) {
 	key := m.objectMapKey(bucket, prefix)
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.objects[key] = items
 }
 
+func (m *cacheMap) deleteObjects(bucket string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key := range m.objects {
+		if strings.HasPrefix(key, bucket+"_") {
+			delete(m.objects, key)
+		}
+	}
+}
+
 func (*cacheMap) objectMapKey(bucket, prefix string) string {
 	return bucket + "_" + prefix
 }
 
-func NewS3Client() (*S3Client, error) {
+type Options struct {
+	Auth    AuthProvider
+	Limiter *stu.RateLimiter
+	// sent as stu/<version> in User-Agent
+	Version string
+	Headers map[string]string
+	// shift the signing time when the server reports RequestTimeTooSkewed
+	CorrectClockSkew bool
+	Buckets          *stu.BucketOverrides
+	// custom endpoint such as LocalStack or MinIO, addressed in path style
+	EndpointURL string
+	// region of the requests which are not sent to a bucket, the shared config or us-east-1 is used if empty
+	Region string
+	// object activity is not available if nil
+	Trail *TrailOptions
+}
+
+func NewS3Client(opts *Options) (*S3Client, error) {
 	ctx := context.Background()
-	customResolver := aws.EndpointResolverWithOptionsFunc(func(service, region string, options ...interface{}) (aws.Endpoint, error) {
-		return aws.Endpoint{
-			URL:           localstackUrl,
-			SigningRegion: region,
-		}, nil
-	})
-	cfg, err := config.LoadDefaultConfig(ctx)
-	cfg.EndpointResolverWithOptions = customResolver
+	cfg, err := loadConfig(ctx, opts.Auth)
 	if err != nil {
 		return nil, err
 	}
-	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
-		o.UsePathStyle = true
+	if opts.Region != "" {
+		cfg.Region = opts.Region
+	}
+	if cfg.Region == "" {
+		cfg.Region = defaultRegion
+	}
+	skew := &skewCorrector{enabled: opts.CorrectClockSkew}
+	optFn := func(o *s3.Options) {
+		if opts.EndpointURL != "" {
+			o.EndpointResolver = s3.EndpointResolverFromURL(opts.EndpointURL, func(e *aws.Endpoint) {
+				e.HostnameImmutable = true
+			})
+			o.UsePathStyle = true
+		}
+		if opts.Limiter != nil {
+			o.APIOptions = append(o.APIOptions, rateLimitMiddleware(opts.Limiter))
+		}
+		o.APIOptions = append(o.APIOptions, skew.middleware)
+		o.APIOptions = append(o.APIOptions, awsmiddleware.AddUserAgentKeyValue(appName, opts.Version))
+		for k, v := range opts.Headers {
+			o.APIOptions = append(o.APIOptions, smithyhttp.SetHeaderValue(k, v))
+		}
+		o.HTTPSignerV4 = &skewSigner{signer: newV4Signer(o), c: skew}
+	}
+	client := s3.NewFromConfig(cfg, optFn)
+	var regions *regionClients
+	// buckets of a custom endpoint do not have regions
+	if opts.EndpointURL == "" {
+		regions = newRegionClients(client, cfg.Region, func(region string) *s3.Client {
+			return s3.NewFromConfig(cfg, optFn, func(o *s3.Options) {
+				o.Region = region
+			})
+		})
+	}
+	stsClient := sts.NewFromConfig(cfg, func(o *sts.Options) {
+		if opts.EndpointURL != "" {
+			o.EndpointResolver = sts.EndpointResolverFromURL(opts.EndpointURL)
+		}
+		for k, v := range opts.Headers {
+			o.APIOptions = append(o.APIOptions, smithyhttp.SetHeaderValue(k, v))
+		}
 	})
 	cache := newCacheMap()
 	return &S3Client{
-		client: client,
-		ctx:    ctx,
-		cache:  cache,
+		client:  client,
+		regions: regions,
+		sts:     stsClient,
+		trail:   opts.Trail,
+		ctx:     ctx,
+		cache:   cache,
+		limiter: opts.Limiter,
+		buckets: opts.Buckets,
 	}, nil
 }
 
+// same as the default signer of s3 package
+func newV4Signer(o *s3.Options) *v4.Signer {
+	return v4.NewSigner(func(so *v4.SignerOptions) {
+		so.Logger = o.Logger
+		so.LogSigning = o.ClientLogMode.IsSigning()
+		so.DisableURIPathEscaping = true
+	})
+}
+
+// rateLimitMiddleware waits for the limiter before each attempt,
+// so pages of paginators and retries are also limited.
+func rateLimitMiddleware(limiter *stu.RateLimiter) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("RateLimit",
+			func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+				if err := limiter.Wait(ctx); err != nil {
+					return middleware.FinalizeOutput{}, middleware.Metadata{}, err
+				}
+				return next.HandleFinalize(ctx, in)
+			}), middleware.After)
+	}
+}
+
+func (c *S3Client) Throttled() bool {
+	return c.limiter.Throttled()
+}
+
+func (c *S3Client) BucketSettings(bucket string) *stu.BucketSettings {
+	return c.buckets.Lookup(bucket)
+}
+
+func (c *S3Client) requestPayer(bucket string) types.RequestPayer {
+	if c.buckets.Lookup(bucket).RequesterPays {
+		return types.RequestPayerRequester
+	}
+	return ""
+}
+
+// copyRequestPayer is set if either the source or the destination is a requester pays bucket.
+func (c *S3Client) copyRequestPayer(srcBucket, dstBucket string) types.RequestPayer {
+	if p := c.requestPayer(srcBucket); p != "" {
+		return p
+	}
+	return c.requestPayer(dstBucket)
+}
+
+func (c *S3Client) writableSettings(bucket string) (*stu.BucketSettings, error) {
+	s := c.buckets.Lookup(bucket)
+	if s.ReadOnly {
+		return nil, stu.ErrReadOnly
+	}
+	return s, nil
+}
+
+func serverSideEncryption(s *stu.BucketSettings) (types.ServerSideEncryption, *string) {
+	if s.SSEKMSKeyID == "" {
+		return "", nil
+	}
+	return types.ServerSideEncryptionAwsKms, aws.String(s.SSEKMSKeyID)
+}
+
 func (c *S3Client) ListObjects(bucket, prefix string) ([]*stu.ObjectItem, error) {
 	if cache, ok := c.cache.getObjects(bucket, prefix); ok {
 		return cache, nil
 	}
 	input := &s3.ListObjectsV2Input{
-		Bucket:    aws.String(bucket),
-		Delimiter: aws.String(delimiter),
-		Prefix:    aws.String(prefix),
+		Bucket:       aws.String(bucket),
+		Delimiter:    aws.String(delimiter),
+		Prefix:       aws.String(prefix),
+		RequestPayer: c.requestPayer(bucket),
 	}
-	p := s3.NewListObjectsV2Paginator(c.client, input, func(o *s3.ListObjectsV2PaginatorOptions) {})
+	p := s3.NewListObjectsV2Paginator(c.bucketClient(c.ctx, bucket), input, func(o *s3.ListObjectsV2PaginatorOptions) {})
 	items := make([]*stu.ObjectItem, 0)
 	for p.HasMorePages() {
 		output, err := p.NextPage(c.ctx)
@@ -100,7 +256,8 @@ func (c *S3Client) ListObjects(bucket, prefix string) ([]*stu.ObjectItem, error)
 			return nil, err
 		}
 		for _, obj := range output.Contents {
-			item := stu.NewFileObjectItem(*obj.Key)
+			item := stu.NewFileObjectItem(*obj.Key, obj.Size, aws.ToTime(obj.LastModified))
+			item.ETag = strings.Trim(aws.ToString(obj.ETag), `"`)
 			items = append(items, item)
 		}
 		for _, cp := range output.CommonPrefixes {
@@ -129,3 +286,356 @@ func (c *S3Client) ListBuckets() ([]*stu.BucketItem, error) {
 	c.cache.putBuckets(items)
 	return items, nil
 }
+
+func (c *S3Client) WalkObjects(ctx context.Context, bucket, prefix string, fn func(*stu.ObjectItem) error) error {
+	input := &s3.ListObjectsV2Input{
+		Bucket:       aws.String(bucket),
+		Prefix:       aws.String(prefix),
+		RequestPayer: c.requestPayer(bucket),
+	}
+	p := s3.NewListObjectsV2Paginator(c.bucketClient(ctx, bucket), input, func(o *s3.ListObjectsV2PaginatorOptions) {})
+	for p.HasMorePages() {
+		output, err := p.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		for _, obj := range output.Contents {
+			item := stu.NewFileObjectItem(*obj.Key, obj.Size, aws.ToTime(obj.LastModified))
+			item.ETag = strings.Trim(aws.ToString(obj.ETag), `"`)
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *S3Client) GetObjectTags(ctx context.Context, bucket, key string) (map[string]string, error) {
+	input := &s3.GetObjectTaggingInput{
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		RequestPayer: c.requestPayer(bucket),
+	}
+	output, err := c.bucketClient(ctx, bucket).GetObjectTagging(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	tags := make(map[string]string)
+	for _, tag := range output.TagSet {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return tags, nil
+}
+
+func (c *S3Client) GetObjectMetadata(ctx context.Context, bucket, key string) (map[string]string, error) {
+	input := &s3.HeadObjectInput{
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		RequestPayer: c.requestPayer(bucket),
+	}
+	output, err := c.bucketClient(ctx, bucket).HeadObject(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	meta := make(map[string]string)
+	for k, v := range output.Metadata {
+		meta[strings.ToLower(k)] = v
+	}
+	if output.ContentType != nil {
+		meta["content-type"] = *output.ContentType
+	}
+	return meta, nil
+}
+
+func (c *S3Client) HeadObject(ctx context.Context, bucket, key string) (*stu.ObjectHead, error) {
+	input := &s3.HeadObjectInput{
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		RequestPayer: c.requestPayer(bucket),
+	}
+	output, err := c.bucketClient(ctx, bucket).HeadObject(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	meta := make(map[string]string)
+	for k, v := range output.Metadata {
+		meta[strings.ToLower(k)] = v
+	}
+	storageClass := string(output.StorageClass)
+	if storageClass == "" {
+		// omitted for STANDARD
+		storageClass = string(types.StorageClassStandard)
+	}
+	return &stu.ObjectHead{
+		Size:                 output.ContentLength,
+		ContentType:          aws.ToString(output.ContentType),
+		ETag:                 strings.Trim(aws.ToString(output.ETag), `"`),
+		StorageClass:         storageClass,
+		ServerSideEncryption: string(output.ServerSideEncryption),
+		ReplicationStatus:    string(output.ReplicationStatus),
+		Metadata:             meta,
+	}, nil
+}
+
+func (c *S3Client) GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	input := &s3.GetObjectInput{
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		RequestPayer: c.requestPayer(bucket),
+	}
+	output, err := c.bucketClient(ctx, bucket).GetObject(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return output.Body, nil
+}
+
+func (c *S3Client) PresignGetObject(ctx context.Context, bucket, key string, expires time.Duration) (string, error) {
+	input := &s3.GetObjectInput{
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		RequestPayer: c.requestPayer(bucket),
+	}
+	req, err := s3.NewPresignClient(c.bucketClient(ctx, bucket)).PresignGetObject(ctx, input, s3.WithPresignExpires(expires))
+	if err != nil {
+		return "", err
+	}
+	return req.URL, nil
+}
+
+func (c *S3Client) GetObjectRange(ctx context.Context, bucket, key string, offset, length int64) (io.ReadCloser, error) {
+	return c.getObjectRange(ctx, bucket, key, "", offset, length)
+}
+
+func (c *S3Client) GetObjectRangeIfMatch(ctx context.Context, bucket, key, etag string, offset, length int64) (io.ReadCloser, error) {
+	r, err := c.getObjectRange(ctx, bucket, key, etag, offset, length)
+	if isErrorCode(err, "PreconditionFailed") {
+		return nil, stu.ErrObjectChanged
+	}
+	return r, err
+}
+
+func (c *S3Client) getObjectRange(ctx context.Context, bucket, key, etag string, offset, length int64) (io.ReadCloser, error) {
+	input := &s3.GetObjectInput{
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		RequestPayer: c.requestPayer(bucket),
+		Range:        aws.String(fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)),
+	}
+	if etag != "" {
+		input.IfMatch = aws.String(`"` + etag + `"`)
+	}
+	output, err := c.bucketClient(ctx, bucket).GetObject(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return output.Body, nil
+}
+
+func (c *S3Client) TouchObject(ctx context.Context, bucket, key string) error {
+	if _, err := c.writableSettings(bucket); err != nil {
+		return err
+	}
+	client := c.bucketClient(ctx, bucket)
+	head, err := client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		RequestPayer: c.requestPayer(bucket),
+	})
+	if err != nil {
+		return err
+	}
+	// copying onto itself requires REPLACE, so the current metadata is passed again
+	// (the storage class and encryption of the object are kept regardless of the bucket settings)
+	input := &s3.CopyObjectInput{
+		Bucket:               aws.String(bucket),
+		Key:                  aws.String(key),
+		RequestPayer:         c.requestPayer(bucket),
+		CopySource:           aws.String(url.PathEscape(bucket + delimiter + key)),
+		MetadataDirective:    types.MetadataDirectiveReplace,
+		Metadata:             head.Metadata,
+		ContentType:          head.ContentType,
+		ContentEncoding:      head.ContentEncoding,
+		ContentDisposition:   head.ContentDisposition,
+		ContentLanguage:      head.ContentLanguage,
+		CacheControl:         head.CacheControl,
+		Expires:              head.Expires,
+		StorageClass:         head.StorageClass,
+		ServerSideEncryption: head.ServerSideEncryption,
+		SSEKMSKeyId:          head.SSEKMSKeyId,
+	}
+	if _, err := client.CopyObject(ctx, input); err != nil {
+		return err
+	}
+	c.cache.deleteObjects(bucket)
+	return nil
+}
+
+func (c *S3Client) CopyObject(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string, size int64) error {
+	settings, err := c.writableSettings(dstBucket)
+	if err != nil {
+		return err
+	}
+	payer := c.copyRequestPayer(srcBucket, dstBucket)
+	defer c.cache.deleteObjects(dstBucket)
+	if size > maxCopyObjectSize {
+		return c.multipartCopyObject(ctx, srcBucket, srcKey, dstBucket, dstKey, size, payer, settings)
+	}
+	source := url.PathEscape(srcBucket + delimiter + srcKey)
+	sse, kmsKeyID := serverSideEncryption(settings)
+	input := &s3.CopyObjectInput{
+		Bucket:               aws.String(dstBucket),
+		Key:                  aws.String(dstKey),
+		CopySource:           aws.String(source),
+		RequestPayer:         payer,
+		StorageClass:         types.StorageClass(settings.StorageClass),
+		ServerSideEncryption: sse,
+		SSEKMSKeyId:          kmsKeyID,
+	}
+	_, err = c.bucketClient(ctx, dstBucket).CopyObject(ctx, input)
+	return err
+}
+
+// multipartCopyObject copies the object larger than the limit of CopyObject by parts in parallel.
+// Unlike CopyObject, UploadPartCopy does not copy the headers and tags, so they are read from the source and set again.
+func (c *S3Client) multipartCopyObject(ctx context.Context, srcBucket, srcKey, bucket, key string, size int64, payer types.RequestPayer, settings *stu.BucketSettings) error {
+	head, err := c.bucketClient(ctx, srcBucket).HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:       aws.String(srcBucket),
+		Key:          aws.String(srcKey),
+		RequestPayer: c.requestPayer(srcBucket),
+	})
+	if err != nil {
+		return err
+	}
+	tags, err := c.GetObjectTags(ctx, srcBucket, srcKey)
+	if err != nil {
+		return err
+	}
+	var tagging *string
+	if len(tags) > 0 {
+		values := make(url.Values)
+		for k, v := range tags {
+			values.Set(k, v)
+		}
+		tagging = aws.String(values.Encode())
+	}
+
+	client := c.bucketClient(ctx, bucket)
+	sse, kmsKeyID := serverSideEncryption(settings)
+	created, err := client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:               aws.String(bucket),
+		Key:                  aws.String(key),
+		RequestPayer:         payer,
+		Metadata:             head.Metadata,
+		ContentType:          head.ContentType,
+		ContentEncoding:      head.ContentEncoding,
+		ContentDisposition:   head.ContentDisposition,
+		ContentLanguage:      head.ContentLanguage,
+		CacheControl:         head.CacheControl,
+		Expires:              head.Expires,
+		Tagging:              tagging,
+		StorageClass:         types.StorageClass(settings.StorageClass),
+		ServerSideEncryption: sse,
+		SSEKMSKeyId:          kmsKeyID,
+	})
+	if err != nil {
+		return err
+	}
+
+	source := url.PathEscape(srcBucket + delimiter + srcKey)
+	count := int((size + copyPartSize - 1) / copyPartSize)
+	parts := make([]types.CompletedPart, count)
+	partCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var mu sync.Mutex
+	var firstErr error
+	numbers := make(chan int32)
+	var wg sync.WaitGroup
+	for i := 0; i < copyConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range numbers {
+				start := int64(n-1) * copyPartSize
+				end := start + copyPartSize - 1
+				if end >= size {
+					end = size - 1
+				}
+				output, err := client.UploadPartCopy(partCtx, &s3.UploadPartCopyInput{
+					Bucket:          aws.String(bucket),
+					Key:             aws.String(key),
+					UploadId:        created.UploadId,
+					PartNumber:      n,
+					CopySource:      aws.String(source),
+					CopySourceRange: aws.String(fmt.Sprintf("bytes=%d-%d", start, end)),
+					RequestPayer:    payer,
+				})
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+					cancel()
+					return
+				}
+				parts[n-1] = types.CompletedPart{ETag: output.CopyPartResult.ETag, PartNumber: n}
+			}
+		}()
+	}
+feed:
+	for n := int32(1); int(n) <= count; n++ {
+		select {
+		case numbers <- n:
+		case <-partCtx.Done():
+			break feed
+		}
+	}
+	close(numbers)
+	wg.Wait()
+	if firstErr == nil {
+		firstErr = partCtx.Err()
+	}
+	if firstErr != nil {
+		c.abortMultipartUpload(bucket, key, created.UploadId)
+		return firstErr
+	}
+
+	_, err = client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(bucket),
+		Key:             aws.String(key),
+		UploadId:        created.UploadId,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+		RequestPayer:    payer,
+	})
+	if err != nil {
+		c.abortMultipartUpload(bucket, key, created.UploadId)
+	}
+	return err
+}
+
+func (c *S3Client) abortMultipartUpload(bucket, key string, uploadID *string) {
+	// use a new context because ctx may have been canceled
+	c.bucketClient(c.ctx, bucket).AbortMultipartUpload(c.ctx, &s3.AbortMultipartUploadInput{
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		UploadId:     uploadID,
+		RequestPayer: c.requestPayer(bucket),
+	})
+}
+
+func (c *S3Client) DeleteObject(ctx context.Context, bucket, key string) error {
+	if _, err := c.writableSettings(bucket); err != nil {
+		return err
+	}
+	input := &s3.DeleteObjectInput{
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		RequestPayer: c.requestPayer(bucket),
+	}
+	if _, err := c.bucketClient(ctx, bucket).DeleteObject(ctx, input); err != nil {
+		return err
+	}
+	c.cache.deleteObjects(bucket)
+	return nil
+}
@@ -0,0 +1,88 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+const (
+	errorCodeRequestTimeTooSkewed = "RequestTimeTooSkewed"
+)
+
+// ClockSkewError is returned instead of the signature error when the local clock is wrong.
+type ClockSkewError struct {
+	// server time - local time
+	Skew      time.Duration
+	Corrected bool
+	Err       error
+}
+
+func (e *ClockSkewError) Error() string {
+	d := e.Skew.Round(time.Second)
+	var s string
+	if d > 0 {
+		s = fmt.Sprintf("local clock is %s behind the server", d)
+	} else {
+		s = fmt.Sprintf("local clock is %s ahead of the server", -d)
+	}
+	if e.Corrected {
+		return s + ", corrected the signing time (try again)"
+	}
+	return s + ", fix the clock or set s3.correct_clock_skew = true"
+}
+
+func (e *ClockSkewError) Unwrap() error {
+	return e.Err
+}
+
+// skewCorrector shifts the signing time by the offset detected from RequestTimeTooSkewed errors.
+type skewCorrector struct {
+	enabled bool
+	// nanoseconds
+	offset int64
+}
+
+type skewSigner struct {
+	signer s3.HTTPSignerV4
+	c      *skewCorrector
+}
+
+func (s *skewSigner) SignHTTP(ctx context.Context, credentials aws.Credentials, r *http.Request, payloadHash string, service string, region string, signingTime time.Time, optFns ...func(*v4.SignerOptions)) error {
+	offset := time.Duration(atomic.LoadInt64(&s.c.offset))
+	return s.signer.SignHTTP(ctx, credentials, r, payloadHash, service, region, signingTime.Add(offset), optFns...)
+}
+
+func (c *skewCorrector) middleware(stack *middleware.Stack) error {
+	return stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc("ClockSkew",
+		func(ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler) (middleware.DeserializeOutput, middleware.Metadata, error) {
+			out, metadata, err := next.HandleDeserialize(ctx, in)
+			var apiErr smithy.APIError
+			if err == nil || !errors.As(err, &apiErr) || apiErr.ErrorCode() != errorCodeRequestTimeTooSkewed {
+				return out, metadata, err
+			}
+			resp, ok := out.RawResponse.(*smithyhttp.Response)
+			if !ok {
+				return out, metadata, err
+			}
+			serverTime, perr := http.ParseTime(resp.Header.Get("Date"))
+			if perr != nil {
+				return out, metadata, err
+			}
+			skew := serverTime.Sub(time.Now())
+			if c.enabled {
+				atomic.StoreInt64(&c.offset, int64(skew))
+			}
+			return out, metadata, &ClockSkewError{Skew: skew, Corrected: c.enabled, Err: err}
+		}), middleware.Before)
+}
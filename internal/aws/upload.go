@@ -0,0 +1,104 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+const (
+	uploadPartSize = 16 * 1024 * 1024
+	maxUploadParts = 10000
+)
+
+// UploadObject uploads r of unknown length, buffering one part at a time.
+// Data which fits in a single part is uploaded with PutObject, and the rest with a multipart upload
+// which is aborted on failure.
+func (c *S3Client) UploadObject(ctx context.Context, bucket, key string, r io.Reader) (int64, error) {
+	settings, err := c.writableSettings(bucket)
+	if err != nil {
+		return 0, err
+	}
+	defer c.cache.deleteObjects(bucket)
+	client := c.bucketClient(ctx, bucket)
+	payer := c.requestPayer(bucket)
+	sse, kmsKeyID := serverSideEncryption(settings)
+
+	buf := make([]byte, uploadPartSize)
+	n, err := io.ReadFull(r, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		_, err = client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket:               aws.String(bucket),
+			Key:                  aws.String(key),
+			Body:                 bytes.NewReader(buf[:n]),
+			RequestPayer:         payer,
+			StorageClass:         types.StorageClass(settings.StorageClass),
+			ServerSideEncryption: sse,
+			SSEKMSKeyId:          kmsKeyID,
+		})
+		if err != nil {
+			return 0, err
+		}
+		return int64(n), nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	created, err := client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:               aws.String(bucket),
+		Key:                  aws.String(key),
+		RequestPayer:         payer,
+		StorageClass:         types.StorageClass(settings.StorageClass),
+		ServerSideEncryption: sse,
+		SSEKMSKeyId:          kmsKeyID,
+	})
+	if err != nil {
+		return 0, err
+	}
+	var written int64
+	parts := make([]types.CompletedPart, 0)
+	for num := int32(1); n > 0; num++ {
+		if num > maxUploadParts {
+			c.abortMultipartUpload(bucket, key, created.UploadId)
+			return written, fmt.Errorf("too large to upload in %d parts of %d bytes", maxUploadParts, uploadPartSize)
+		}
+		output, err := client.UploadPart(ctx, &s3.UploadPartInput{
+			Bucket:       aws.String(bucket),
+			Key:          aws.String(key),
+			UploadId:     created.UploadId,
+			PartNumber:   num,
+			Body:         bytes.NewReader(buf[:n]),
+			RequestPayer: payer,
+		})
+		if err != nil {
+			c.abortMultipartUpload(bucket, key, created.UploadId)
+			return written, err
+		}
+		parts = append(parts, types.CompletedPart{ETag: output.ETag, PartNumber: num})
+		written += int64(n)
+
+		n, err = io.ReadFull(r, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			c.abortMultipartUpload(bucket, key, created.UploadId)
+			return written, err
+		}
+	}
+	_, err = client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(bucket),
+		Key:             aws.String(key),
+		UploadId:        created.UploadId,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+		RequestPayer:    payer,
+	})
+	if err != nil {
+		c.abortMultipartUpload(bucket, key, created.UploadId)
+		return written, err
+	}
+	return written, nil
+}
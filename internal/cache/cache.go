@@ -0,0 +1,193 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/lusingander/stu/internal/stu"
+)
+
+const (
+	appName = "stu"
+)
+
+// Store keeps the listings of a connection on disk, so that they can be browsed with --offline.
+type Store struct {
+	path string
+
+	mu   sync.Mutex
+	data *storeData
+}
+
+type storeData struct {
+	Buckets *bucketsEntry            `json:"buckets,omitempty"`
+	Objects map[string]*objectsEntry `json:"objects"`
+	Metrics map[string]*metricsEntry `json:"metrics,omitempty"`
+}
+
+type metricsEntry struct {
+	Objects   int       `json:"objects"`
+	Size      int64     `json:"size"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type bucketsEntry struct {
+	Names    []string  `json:"names"`
+	CachedAt time.Time `json:"cached_at"`
+}
+
+type objectsEntry struct {
+	Objects  []*objectRecord `json:"objects"`
+	CachedAt time.Time       `json:"cached_at"`
+}
+
+type objectRecord struct {
+	Key          string    `json:"key"`
+	Dir          bool      `json:"dir,omitempty"`
+	Size         int64     `json:"size,omitempty"`
+	LastModified time.Time `json:"last_modified,omitempty"`
+	ETag         string    `json:"etag,omitempty"`
+}
+
+func Dir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, appName), nil
+}
+
+// Open loads the store of the connection, which is empty if nothing has been cached yet.
+func Open(connection string) (*Store, error) {
+	if connection == "" {
+		connection = "default"
+	}
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	s := &Store{
+		path: filepath.Join(dir, connection+".json"),
+		data: &storeData{Objects: make(map[string]*objectsEntry)},
+	}
+	b, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return s, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(b, s.data); err != nil {
+		return nil, err
+	}
+	if s.data.Objects == nil {
+		s.data.Objects = make(map[string]*objectsEntry)
+	}
+	return s, nil
+}
+
+func (*Store) objectsKey(bucket, prefix string) string {
+	return bucket + "/" + prefix
+}
+
+func (s *Store) Buckets() ([]*stu.BucketItem, time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.data.Buckets
+	if e == nil {
+		return nil, time.Time{}, false
+	}
+	items := make([]*stu.BucketItem, len(e.Names))
+	for i, name := range e.Names {
+		items[i] = stu.NewBucketItem(name)
+	}
+	return items, e.CachedAt, true
+}
+
+func (s *Store) PutBuckets(items []*stu.BucketItem) error {
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = item.BucketName()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Buckets = &bucketsEntry{Names: names, CachedAt: time.Now()}
+	return s.save()
+}
+
+func (s *Store) Objects(bucket, prefix string) ([]*stu.ObjectItem, time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.data.Objects[s.objectsKey(bucket, prefix)]
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	items := make([]*stu.ObjectItem, len(e.Objects))
+	for i, r := range e.Objects {
+		if r.Dir {
+			items[i] = stu.NewDirObjectItem(r.Key)
+		} else {
+			items[i] = stu.NewFileObjectItem(r.Key, r.Size, r.LastModified)
+			items[i].ETag = r.ETag
+		}
+	}
+	return items, e.CachedAt, true
+}
+
+func (s *Store) PutObjects(bucket, prefix string, items []*stu.ObjectItem) error {
+	records := make([]*objectRecord, len(items))
+	for i, item := range items {
+		records[i] = &objectRecord{
+			Key:          item.ObjectKey(),
+			Dir:          item.Dir,
+			Size:         item.Size,
+			LastModified: item.LastModified,
+			ETag:         item.ETag,
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Objects[s.objectsKey(bucket, prefix)] = &objectsEntry{Objects: records, CachedAt: time.Now()}
+	return s.save()
+}
+
+func (s *Store) BucketMetrics(bucket string) (*stu.BucketMetrics, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.data.Metrics[bucket]
+	if !ok {
+		return nil, false
+	}
+	return &stu.BucketMetrics{Objects: e.Objects, Size: e.Size, UpdatedAt: e.UpdatedAt}, true
+}
+
+func (s *Store) PutBucketMetrics(bucket string, metrics *stu.BucketMetrics) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data.Metrics == nil {
+		s.data.Metrics = make(map[string]*metricsEntry)
+	}
+	s.data.Metrics[bucket] = &metricsEntry{Objects: metrics.Objects, Size: metrics.Size, UpdatedAt: metrics.UpdatedAt}
+	return s.save()
+}
+
+// save writes to a temporary file first so that an interrupted write does not break the store.
+func (s *Store) save() error {
+	b, err := json.Marshal(s.data)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
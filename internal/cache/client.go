@@ -0,0 +1,229 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/lusingander/stu/internal/stu"
+)
+
+// RecordingClient stores the listings of the wrapped client.
+// Failing to write the store does not fail the listing.
+type RecordingClient struct {
+	stu.Client
+	store *Store
+}
+
+func NewRecordingClient(client stu.Client, store *Store) *RecordingClient {
+	return &RecordingClient{
+		Client: client,
+		store:  store,
+	}
+}
+
+func (c *RecordingClient) ListObjects(bucket, prefix string) ([]*stu.ObjectItem, error) {
+	items, err := c.Client.ListObjects(bucket, prefix)
+	if err != nil {
+		return nil, err
+	}
+	_ = c.store.PutObjects(bucket, prefix, items)
+	return items, nil
+}
+
+func (c *RecordingClient) ListBuckets() ([]*stu.BucketItem, error) {
+	items, err := c.Client.ListBuckets()
+	if err != nil {
+		return nil, err
+	}
+	_ = c.store.PutBuckets(items)
+	return items, nil
+}
+
+func (c *RecordingClient) BucketMetrics(bucket string) (*stu.BucketMetrics, bool) {
+	return c.store.BucketMetrics(bucket)
+}
+
+func (c *RecordingClient) PutBucketMetrics(bucket string, metrics *stu.BucketMetrics) error {
+	return c.store.PutBucketMetrics(bucket, metrics)
+}
+
+// Throttled is forwarded since the embedded interface does not promote it.
+func (c *RecordingClient) Throttled() bool {
+	t, ok := c.Client.(interface{ Throttled() bool })
+	return ok && t.Throttled()
+}
+
+func (c *RecordingClient) BucketSettings(bucket string) *stu.BucketSettings {
+	if s, ok := c.Client.(interface {
+		BucketSettings(bucket string) *stu.BucketSettings
+	}); ok {
+		return s.BucketSettings(bucket)
+	}
+	return &stu.BucketSettings{}
+}
+
+func (c *RecordingClient) PutObjectACL(ctx context.Context, bucket, key, acl string) error {
+	if s, ok := c.Client.(stu.ACLSetter); ok {
+		return s.PutObjectACL(ctx, bucket, key, acl)
+	}
+	return stu.ErrACLsNotSupported
+}
+
+func (c *RecordingClient) GetObjectRangeIfMatch(ctx context.Context, bucket, key, etag string, offset, length int64) (io.ReadCloser, error) {
+	if g, ok := c.Client.(stu.PinnedRangeGetter); ok {
+		return g.GetObjectRangeIfMatch(ctx, bucket, key, etag, offset, length)
+	}
+	return nil, stu.ErrPinnedRangeNotSupported
+}
+
+func (c *RecordingClient) UploadObject(ctx context.Context, bucket, key string, r io.Reader) (int64, error) {
+	if u, ok := c.Client.(stu.Uploader); ok {
+		return u.UploadObject(ctx, bucket, key, r)
+	}
+	return 0, stu.ErrUploadNotSupported
+}
+
+func (c *RecordingClient) DeleteObjects(ctx context.Context, bucket string, keys []string) ([]*stu.DeleteFailure, error) {
+	if d, ok := c.Client.(stu.BatchDeleter); ok {
+		return d.DeleteObjects(ctx, bucket, keys)
+	}
+	return nil, stu.ErrBatchDeleteNotSupported
+}
+
+func (c *RecordingClient) ObjectEvents(ctx context.Context, bucket, key string) ([]*stu.ObjectEvent, error) {
+	if r, ok := c.Client.(stu.ObjectActivityReader); ok {
+		return r.ObjectEvents(ctx, bucket, key)
+	}
+	return nil, stu.ErrActivityNotConfigured
+}
+
+func (c *RecordingClient) GetBucketPolicy(ctx context.Context, bucket string) (string, error) {
+	if r, ok := c.Client.(stu.BucketPolicyReader); ok {
+		return r.GetBucketPolicy(ctx, bucket)
+	}
+	return "", stu.ErrPropertiesNotSupported
+}
+
+func (c *RecordingClient) IssueCredentials(ctx context.Context, req *stu.CredentialsRequest) (*stu.TemporaryCredentials, error) {
+	if i, ok := c.Client.(stu.CredentialsIssuer); ok {
+		return i.IssueCredentials(ctx, req)
+	}
+	return nil, stu.ErrCredentialsNotSupported
+}
+
+func (c *RecordingClient) GetBucketCORS(ctx context.Context, bucket string) ([]*stu.CORSRule, error) {
+	if r, ok := c.Client.(stu.BucketCORSReader); ok {
+		return r.GetBucketCORS(ctx, bucket)
+	}
+	return nil, stu.ErrPropertiesNotSupported
+}
+
+// OfflineClient serves the listings from the store without any requests.
+// Everything else fails with stu.ErrOffline.
+type OfflineClient struct {
+	store *Store
+}
+
+func NewOfflineClient(store *Store) *OfflineClient {
+	return &OfflineClient{
+		store: store,
+	}
+}
+
+func (*OfflineClient) Offline() bool {
+	return true
+}
+
+// CachedAt returns when the listing was stored, prefix is ignored if bucket is empty.
+func (c *OfflineClient) CachedAt(bucket, prefix string) (time.Time, bool) {
+	if bucket == "" {
+		_, t, ok := c.store.Buckets()
+		return t, ok
+	}
+	_, t, ok := c.store.Objects(bucket, prefix)
+	return t, ok
+}
+
+func (c *OfflineClient) BucketMetrics(bucket string) (*stu.BucketMetrics, bool) {
+	return c.store.BucketMetrics(bucket)
+}
+
+func (c *OfflineClient) ListObjects(bucket, prefix string) ([]*stu.ObjectItem, error) {
+	items, _, ok := c.store.Objects(bucket, prefix)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s/%s is not cached", stu.ErrOffline, bucket, prefix)
+	}
+	return items, nil
+}
+
+func (c *OfflineClient) ListBuckets() ([]*stu.BucketItem, error) {
+	items, _, ok := c.store.Buckets()
+	if !ok {
+		return nil, fmt.Errorf("%w: buckets are not cached", stu.ErrOffline)
+	}
+	return items, nil
+}
+
+// WalkObjects walks only the cached listings under the prefix.
+func (c *OfflineClient) WalkObjects(ctx context.Context, bucket, prefix string, fn func(*stu.ObjectItem) error) error {
+	items, err := c.ListObjects(bucket, prefix)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if item.Dir {
+			if _, _, ok := c.store.Objects(bucket, item.ObjectKey()); !ok {
+				continue
+			}
+			if err := c.WalkObjects(ctx, bucket, item.ObjectKey(), fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (*OfflineClient) GetObjectTags(ctx context.Context, bucket, key string) (map[string]string, error) {
+	return nil, stu.ErrOffline
+}
+
+func (*OfflineClient) GetObjectMetadata(ctx context.Context, bucket, key string) (map[string]string, error) {
+	return nil, stu.ErrOffline
+}
+
+func (*OfflineClient) HeadObject(ctx context.Context, bucket, key string) (*stu.ObjectHead, error) {
+	return nil, stu.ErrOffline
+}
+
+func (*OfflineClient) GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	return nil, stu.ErrOffline
+}
+
+func (*OfflineClient) PresignGetObject(ctx context.Context, bucket, key string, expires time.Duration) (string, error) {
+	return "", stu.ErrOffline
+}
+
+func (*OfflineClient) GetObjectRange(ctx context.Context, bucket, key string, offset, length int64) (io.ReadCloser, error) {
+	return nil, stu.ErrOffline
+}
+
+func (*OfflineClient) TouchObject(ctx context.Context, bucket, key string) error {
+	return stu.ErrOffline
+}
+
+func (*OfflineClient) CopyObject(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string, size int64) error {
+	return stu.ErrOffline
+}
+
+func (*OfflineClient) DeleteObject(ctx context.Context, bucket, key string) error {
+	return stu.ErrOffline
+}
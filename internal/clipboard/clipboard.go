@@ -0,0 +1,133 @@
+package clipboard
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	ModeAuto   = "auto"
+	ModeNative = "native"
+	ModeOSC52  = "osc52"
+)
+
+type Clipboard interface {
+	Copy(text string) error
+}
+
+// New returns a clipboard for the mode.
+// In auto mode, the native clipboard is preferred, but OSC52 is used in SSH sessions
+// or when no clipboard command is available.
+// multiplexer specifies how OSC52 sequences are wrapped to pass through tmux or screen.
+func New(mode, multiplexer string, out io.Writer) (Clipboard, error) {
+	mux, err := resolveMultiplexer(multiplexer)
+	if err != nil {
+		return nil, err
+	}
+	switch mode {
+	case ModeNative:
+		c := findNative()
+		if c == nil {
+			return nil, errors.New("no native clipboard command found")
+		}
+		return c, nil
+	case ModeOSC52:
+		return &osc52Clipboard{out: out, mux: mux}, nil
+	case ModeAuto, "":
+		if !isSSH() {
+			if c := findNative(); c != nil {
+				return c, nil
+			}
+		}
+		return &osc52Clipboard{out: out, mux: mux}, nil
+	}
+	return nil, fmt.Errorf("unknown clipboard mode: %s", mode)
+}
+
+func isSSH() bool {
+	return os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != ""
+}
+
+type nativeClipboard struct {
+	name   string
+	args   []string
+	encode func(string) []byte
+}
+
+func findNative() *nativeClipboard {
+	for _, c := range nativeCandidates() {
+		if _, err := exec.LookPath(c.name); err == nil {
+			return c
+		}
+	}
+	return nil
+}
+
+func nativeCandidates() []*nativeClipboard {
+	switch runtime.GOOS {
+	case "darwin":
+		return []*nativeClipboard{
+			{name: "pbcopy"},
+		}
+	case "windows":
+		return []*nativeClipboard{
+			{name: "clip.exe", encode: encodeUTF16LE},
+		}
+	}
+	cs := make([]*nativeClipboard, 0)
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		cs = append(cs, &nativeClipboard{name: "wl-copy"})
+	}
+	if os.Getenv("DISPLAY") != "" {
+		cs = append(cs,
+			&nativeClipboard{name: "xclip", args: []string{"-selection", "clipboard"}},
+			&nativeClipboard{name: "xsel", args: []string{"--clipboard", "--input"}},
+		)
+	}
+	return cs
+}
+
+func (c *nativeClipboard) Copy(text string) error {
+	input := []byte(text)
+	if c.encode != nil {
+		input = c.encode(text)
+	}
+	cmd := exec.Command(c.name, c.args...)
+	cmd.Stdin = bytes.NewReader(input)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %w: %s", c.name, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// clip.exe reads the input in the console code page unless it starts with a UTF-16LE BOM
+func encodeUTF16LE(s string) []byte {
+	u := utf16.Encode([]rune(s))
+	b := make([]byte, 2*(len(u)+1))
+	binary.LittleEndian.PutUint16(b, 0xfeff)
+	for i, r := range u {
+		binary.LittleEndian.PutUint16(b[2*(i+1):], r)
+	}
+	return b
+}
+
+type osc52Clipboard struct {
+	out io.Writer
+	mux string
+}
+
+// Copy writes the sequence in one write, so that it is not split by other writes to the same file.
+func (c *osc52Clipboard) Copy(text string) error {
+	s := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\x07"
+	_, err := io.WriteString(c.out, wrapPassthrough(s, c.mux))
+	return err
+}
@@ -0,0 +1,59 @@
+package clipboard
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	MultiplexerAuto   = "auto"
+	MultiplexerTmux   = "tmux"
+	MultiplexerScreen = "screen"
+	MultiplexerNone   = "none"
+)
+
+const (
+	// GNU screen truncates long DCS strings, so the sequence is split into chunks
+	screenChunkSize = 768
+)
+
+func resolveMultiplexer(mux string) (string, error) {
+	switch mux {
+	case MultiplexerAuto, "":
+		return detectMultiplexer(), nil
+	case MultiplexerTmux, MultiplexerScreen, MultiplexerNone:
+		return mux, nil
+	}
+	return "", fmt.Errorf("unknown multiplexer: %s", mux)
+}
+
+func detectMultiplexer() string {
+	if os.Getenv("TMUX") != "" {
+		return MultiplexerTmux
+	}
+	if os.Getenv("STY") != "" || strings.HasPrefix(os.Getenv("TERM"), "screen") {
+		return MultiplexerScreen
+	}
+	return MultiplexerNone
+}
+
+// wrapPassthrough wraps the escape sequence so that the multiplexer passes it to the outer terminal.
+func wrapPassthrough(seq, mux string) string {
+	switch mux {
+	case MultiplexerTmux:
+		return "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	case MultiplexerScreen:
+		var b strings.Builder
+		for len(seq) > 0 {
+			n := screenChunkSize
+			if n > len(seq) {
+				n = len(seq)
+			}
+			b.WriteString("\x1bP" + seq[:n] + "\x1b\\")
+			seq = seq[n:]
+		}
+		return b.String()
+	}
+	return seq
+}
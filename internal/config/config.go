@@ -0,0 +1,311 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+)
+
+const (
+	appName         = "stu"
+	configFileName  = "config.toml"
+	keybindFileName = "keybind.toml"
+)
+
+type Config struct {
+	// name of the connection to use, the default credential chain is used if empty
+	Connection  string             `toml:"connection"`
+	Connections []ConnectionConfig `toml:"connections"`
+	// settings applied inside buckets, the first matching entry is used
+	BucketOverrides []BucketOverrideConfig `toml:"bucket_overrides"`
+	// disable touch, rename, copy, ACL changes and delete in all buckets
+	ReadOnly bool `toml:"read_only"`
+
+	Clipboard ClipboardConfig `toml:"clipboard"`
+	Terminal  TerminalConfig  `toml:"terminal"`
+	UI        UIConfig        `toml:"ui"`
+	Format    FormatConfig    `toml:"format"`
+	Hooks     []HookConfig    `toml:"hooks"`
+	Control   ControlConfig   `toml:"control"`
+	S3        S3Config        `toml:"s3"`
+	Preview   PreviewConfig   `toml:"preview"`
+	Download  DownloadConfig  `toml:"download"`
+	Upload    UploadConfig    `toml:"upload"`
+	Temp      TempConfig      `toml:"temp"`
+	Audit     AuditConfig     `toml:"audit"`
+
+	Credentials CredentialsConfig `toml:"credentials"`
+	CloudTrail  CloudTrailConfig  `toml:"cloudtrail"`
+
+	// loaded from keybind.toml
+	Keybind KeybindConfig `toml:"-"`
+}
+
+type ConnectionConfig struct {
+	Name string     `toml:"name"`
+	Auth AuthConfig `toml:"auth"`
+	// added to every request, e.g. to identify the traffic in proxies
+	Headers map[string]string `toml:"headers"`
+	Buckets BucketsConfig     `toml:"buckets"`
+	// custom endpoint such as LocalStack or MinIO, the AWS endpoints are used if empty
+	EndpointURL string `toml:"endpoint_url"`
+	// region of the requests which are not sent to a bucket, each bucket is accessed in its own region
+	Region string `toml:"region"`
+}
+
+// BucketsConfig hides buckets from the bucket list by default (path.Match patterns).
+// If Include is empty, all buckets which do not match Exclude are shown.
+type BucketsConfig struct {
+	Include []string `toml:"include"`
+	Exclude []string `toml:"exclude"`
+}
+
+type BucketOverrideConfig struct {
+	// bucket name or path.Match pattern
+	Name string `toml:"name"`
+	// storage class of written objects, e.g. "STANDARD_IA"
+	StorageClass  string `toml:"storage_class"`
+	RequesterPays bool   `toml:"requester_pays"`
+	// written objects are encrypted with SSE-KMS using this key
+	SSEKMSKeyID string `toml:"sse_kms_key_id"`
+	// disable touch, rename, copy into the bucket, ACL changes and delete
+	ReadOnly bool `toml:"read_only"`
+}
+
+type AuthConfig struct {
+	// "default", "static", "profile", "sso", "assume_role", "web_identity", "ec2" or "ecs"
+	Provider string `toml:"provider"`
+
+	// static
+	AccessKeyID     string `toml:"access_key_id"`
+	SecretAccessKey string `toml:"secret_access_key"`
+	SessionToken    string `toml:"session_token"`
+
+	// profile
+	Profile string `toml:"profile"`
+
+	// sso, uses the token cached by `aws sso login`
+	SSOStartURL  string `toml:"sso_start_url"`
+	SSORegion    string `toml:"sso_region"`
+	SSOAccountID string `toml:"sso_account_id"`
+	SSORoleName  string `toml:"sso_role_name"`
+
+	// assume_role and web_identity
+	RoleARN              string `toml:"role_arn"`
+	SessionName          string `toml:"session_name"`
+	ExternalID           string `toml:"external_id"`
+	WebIdentityTokenFile string `toml:"web_identity_token_file"`
+}
+
+type ClipboardConfig struct {
+	// "auto", "native" or "osc52"
+	Mode string `toml:"mode"`
+}
+
+type TerminalConfig struct {
+	// "auto", "tmux", "screen" or "none"
+	Multiplexer string `toml:"multiplexer"`
+}
+
+type UIConfig struct {
+	// avoid color-only signaling and box-drawing characters
+	Accessible bool `toml:"accessible"`
+	// list zero-byte folder marker objects (e.g. foo/) as files
+	ShowFolderMarkers bool `toml:"show_folder_markers"`
+	// fetch HeadObject of listed files in the background to show
+	// storage class, encryption and replication status, one request per file
+	Enrich bool `toml:"enrich"`
+	// show object counts and sizes of buckets from previous stats runs in the bucket list
+	BucketMetrics bool `toml:"bucket_metrics"`
+	// "key" (press y) or "name" (type the filename) to confirm deleting objects
+	DeleteConfirm string `toml:"delete_confirm"`
+}
+
+type FormatConfig struct {
+	// "binary" (KiB, MiB, ...) or "decimal" (KB, MB, ...)
+	SizeUnit           string `toml:"size_unit"`
+	ThousandsSeparator string `toml:"thousands_separator"`
+	DecimalSeparator   string `toml:"decimal_separator"`
+	// "24h" or "12h"
+	Clock string `toml:"clock"`
+	// Go time layout
+	DateFormat string `toml:"date_format"`
+}
+
+type HookConfig struct {
+	Name string `toml:"name"`
+	// optional, hooks without key are available from the hook menu only
+	Key string `toml:"key"`
+	// {bucket}, {key} and {path} (downloaded temporary file) are replaced,
+	// run by sh, or without a shell on Windows.
+	// The values are quoted by stu, so the placeholders need no quotes.
+	// The object is downloaded only if {path} is used.
+	Command string `toml:"command"`
+	// wait for enter after the command exits to keep its output visible
+	Wait bool `toml:"wait"`
+}
+
+type ControlConfig struct {
+	// unix socket path to accept JSON-RPC requests, disabled if empty
+	Socket string `toml:"socket"`
+}
+
+type S3Config struct {
+	// max requests per second across all operations, unlimited if 0
+	RateLimit float64 `toml:"rate_limit"`
+	// shift the signing time by the difference from the server clock
+	// when requests fail with RequestTimeTooSkewed
+	CorrectClockSkew bool `toml:"correct_clock_skew"`
+}
+
+type PreviewConfig struct {
+	// max bytes of an object read into memory for preview
+	MaxBytes int64          `toml:"max_bytes"`
+	Schemas  []SchemaConfig `toml:"schemas"`
+}
+
+// SchemaConfig decodes objects whose key matches Pattern (path.Match) with the schema in File,
+// or with the schemas of Registry.
+type SchemaConfig struct {
+	Pattern string `toml:"pattern"`
+	// avro (File is a .avsc) or protobuf (File is a descriptor set)
+	Format string `toml:"format"`
+	File   string `toml:"file"`
+	// URL of a Confluent compatible schema registry, for avro objects in its wire format
+	// (a zero byte and the schema id before each datum)
+	Registry string `toml:"registry"`
+	// full name of the protobuf message
+	Message string `toml:"message"`
+}
+
+type DownloadConfig struct {
+	// objects larger than PartSize are downloaded with Concurrency ranged requests of PartSize in parallel
+	PartSize    int64 `toml:"part_size"`
+	Concurrency int   `toml:"concurrency"`
+}
+
+type UploadConfig struct {
+	// files of a directory uploaded in parallel
+	Concurrency int `toml:"concurrency"`
+}
+
+// KeybindConfig maps the actions of each page to keys, e.g. `download = ["d"]` in [list].
+// Actions which are not set keep the default keys, an empty list disables the action.
+type KeybindConfig struct {
+	List    map[string][]string `toml:"list"`
+	Detail  map[string][]string `toml:"detail"`
+	Preview map[string][]string `toml:"preview"`
+}
+
+type TempConfig struct {
+	// keep temporary files on exit, orphaned files are not removed on startup either
+	Keep bool `toml:"keep"`
+}
+
+type AuditConfig struct {
+	// changes such as ACLs are appended as JSON lines, audit.log in the cache directory if empty
+	Path string `toml:"path"`
+}
+
+// CredentialsConfig is used to issue temporary credentials scoped to a bucket prefix.
+type CredentialsConfig struct {
+	// the role is assumed with the scoped policy, GetFederationToken is used if empty
+	RoleARN         string `toml:"role_arn"`
+	DurationSeconds int32  `toml:"duration_seconds"`
+}
+
+// CloudTrailConfig is the trail delivering S3 data events to a bucket, which is read to show the activity of objects.
+// The activity is not available if Bucket is empty.
+type CloudTrailConfig struct {
+	Bucket string `toml:"bucket"`
+	// key prefix of the trail, without AWSLogs/
+	Prefix         string `toml:"prefix"`
+	OrganizationID string `toml:"organization_id"`
+	// the account of the credentials is used if empty
+	AccountID string `toml:"account_id"`
+	// the region of the bucket of the object is used if empty
+	Region string `toml:"region"`
+	// days of logs to read, from today (UTC)
+	Days int `toml:"days"`
+}
+
+func Default() *Config {
+	return &Config{
+		Clipboard: ClipboardConfig{
+			Mode: "auto",
+		},
+		Terminal: TerminalConfig{
+			Multiplexer: "auto",
+		},
+		UI: UIConfig{
+			DeleteConfirm: "key",
+		},
+		Format: FormatConfig{
+			SizeUnit:           "binary",
+			ThousandsSeparator: ",",
+			DecimalSeparator:   ".",
+			Clock:              "24h",
+			DateFormat:         "2006-01-02",
+		},
+		Preview: PreviewConfig{
+			MaxBytes: 1024 * 1024,
+		},
+		Download: DownloadConfig{
+			PartSize:    8 * 1024 * 1024,
+			Concurrency: 4,
+		},
+		Upload: UploadConfig{
+			Concurrency: 4,
+		},
+		Credentials: CredentialsConfig{
+			DurationSeconds: 3600,
+		},
+		CloudTrail: CloudTrailConfig{
+			Days: 1,
+		},
+	}
+}
+
+// CurrentConnection returns the connection selected by Connection.
+func (c *Config) CurrentConnection() (*ConnectionConfig, error) {
+	if c.Connection == "" {
+		return &ConnectionConfig{Auth: AuthConfig{Provider: "default"}}, nil
+	}
+	for i := range c.Connections {
+		if c.Connections[i].Name == c.Connection {
+			return &c.Connections[i], nil
+		}
+	}
+	return nil, fmt.Errorf("connection not found: %s", c.Connection)
+}
+
+func Dir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, appName), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", appName), nil
+}
+
+func Load() (*Config, error) {
+	cfg := Default()
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	_, err = toml.DecodeFile(filepath.Join(dir, configFileName), cfg)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	_, err = toml.DecodeFile(filepath.Join(dir, keybindFileName), &cfg.Keybind)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return cfg, nil
+}
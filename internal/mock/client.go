@@ -0,0 +1,302 @@
+package mock
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lusingander/stu/internal/stu"
+)
+
+const (
+	delimiter = "/"
+)
+
+var (
+	ErrNoSuchBucket = errors.New("no such bucket")
+	ErrNoSuchKey    = errors.New("no such key")
+)
+
+type Object struct {
+	Key          string
+	Content      []byte
+	LastModified time.Time
+	Tags         map[string]string
+	Metadata     map[string]string
+}
+
+// Client is an in-memory stu.Client with deterministic contents.
+type Client struct {
+	buckets map[string][]*Object
+}
+
+func NewClient() *Client {
+	return &Client{
+		buckets: make(map[string][]*Object),
+	}
+}
+
+// NewFixtureClient returns a client with a small fixed set of buckets and objects.
+func NewFixtureClient() *Client {
+	c := NewClient()
+	t := time.Date(2021, 12, 1, 10, 0, 0, 0, time.UTC)
+	c.AddBucket("empty-bucket")
+	c.AddBucket("test-bucket")
+	c.PutObject("test-bucket", &Object{Key: "README.md", Content: []byte("# test\n"), LastModified: t})
+	c.PutObject("test-bucket", &Object{Key: "dir1/file1.txt", Content: []byte("hello\n"), LastModified: t.Add(time.Hour)})
+	c.PutObject("test-bucket", &Object{Key: "dir1/file2.json", Content: []byte(`{"a": 1}`), LastModified: t.Add(2 * time.Hour),
+		Tags: map[string]string{"env": "prod"}})
+	c.PutObject("test-bucket", &Object{Key: "dir1/dir2/large.bin", Content: bytes.Repeat([]byte{0}, 2048), LastModified: t.AddDate(0, -6, 0),
+		Metadata: map[string]string{"owner": "alice"}})
+	c.PutObject("test-bucket", &Object{Key: "dir3/image.png", Content: []byte{0x89, 'P', 'N', 'G'}, LastModified: t.AddDate(-1, 0, 0)})
+	c.PutObject("test-bucket", &Object{Key: "dir3/image-copy.png", Content: []byte{0x89, 'P', 'N', 'G'}, LastModified: t})
+	// folder marker created by the console
+	c.PutObject("test-bucket", &Object{Key: "dir4/", LastModified: t})
+	c.PutObject("test-bucket", &Object{Key: "preview/small-html.html", LastModified: t, Content: []byte(
+		"<html><head><title>small</title></head><body>\n<h1>Report</h1>\n<p>Generated by <a href=\"https://example.com\">a tool</a>.</p>\n<ul><li>first</li><li>second</li></ul>\n</body></html>\n")})
+	c.PutObject("test-bucket", &Object{Key: "preview/rust-code.rs", LastModified: t, Content: []byte(
+		"// greets\nfn main() {\n    let name: &str = \"stu\";\n    println!(\"hello, {}\", name);\n}\n")})
+	c.PutObject("test-bucket", &Object{Key: "preview/small-text.txt", LastModified: t, Content: []byte("small text\n")})
+	c.PutObject("test-bucket", &Object{Key: "preview/medium-text.txt", LastModified: t, Content: numberedLines(200)})
+	// larger than the default max bytes of preview
+	c.PutObject("test-bucket", &Object{Key: "preview/large-text.txt", LastModified: t, Content: numberedLines(100000)})
+	return c
+}
+
+func numberedLines(n int) []byte {
+	var b bytes.Buffer
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, "line %d: the quick brown fox jumps over the lazy dog\n", i)
+	}
+	return b.Bytes()
+}
+
+func (c *Client) AddBucket(name string) {
+	if _, ok := c.buckets[name]; !ok {
+		c.buckets[name] = make([]*Object, 0)
+	}
+}
+
+func (c *Client) PutObject(bucket string, obj *Object) {
+	c.AddBucket(bucket)
+	objs := c.buckets[bucket]
+	for i, o := range objs {
+		if o.Key == obj.Key {
+			objs[i] = obj
+			return
+		}
+	}
+	objs = append(objs, obj)
+	sort.Slice(objs, func(i, j int) bool { return objs[i].Key < objs[j].Key })
+	c.buckets[bucket] = objs
+}
+
+func (c *Client) findObject(bucket, key string) (*Object, error) {
+	objs, ok := c.buckets[bucket]
+	if !ok {
+		return nil, ErrNoSuchBucket
+	}
+	for _, o := range objs {
+		if o.Key == key {
+			return o, nil
+		}
+	}
+	return nil, ErrNoSuchKey
+}
+
+func (c *Client) newFileObjectItem(o *Object) *stu.ObjectItem {
+	item := stu.NewFileObjectItem(o.Key, int64(len(o.Content)), o.LastModified)
+	item.ETag = fmt.Sprintf("%x", md5.Sum(o.Content))
+	return item
+}
+
+func (c *Client) ListObjects(bucket, prefix string) ([]*stu.ObjectItem, error) {
+	objs, ok := c.buckets[bucket]
+	if !ok {
+		return nil, ErrNoSuchBucket
+	}
+	files := make([]*stu.ObjectItem, 0)
+	dirs := make([]*stu.ObjectItem, 0)
+	seen := make(map[string]bool)
+	for _, o := range objs {
+		if !strings.HasPrefix(o.Key, prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(o.Key, prefix)
+		if i := strings.Index(rest, delimiter); i >= 0 {
+			dir := prefix + rest[:i+1]
+			if !seen[dir] {
+				seen[dir] = true
+				dirs = append(dirs, stu.NewDirObjectItem(dir))
+			}
+			continue
+		}
+		files = append(files, c.newFileObjectItem(o))
+	}
+	// same order as ListObjectsV2: contents first, then common prefixes
+	return append(files, dirs...), nil
+}
+
+func (c *Client) ListBuckets() ([]*stu.BucketItem, error) {
+	names := make([]string, 0, len(c.buckets))
+	for name := range c.buckets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	items := make([]*stu.BucketItem, len(names))
+	for i, name := range names {
+		items[i] = stu.NewBucketItem(name)
+	}
+	return items, nil
+}
+
+func (c *Client) WalkObjects(ctx context.Context, bucket, prefix string, fn func(*stu.ObjectItem) error) error {
+	objs, ok := c.buckets[bucket]
+	if !ok {
+		return ErrNoSuchBucket
+	}
+	// fn may modify the bucket
+	objs = append([]*Object(nil), objs...)
+	for _, o := range objs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !strings.HasPrefix(o.Key, prefix) {
+			continue
+		}
+		if err := fn(c.newFileObjectItem(o)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Client) GetObjectTags(ctx context.Context, bucket, key string) (map[string]string, error) {
+	o, err := c.findObject(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	return copyMap(o.Tags), nil
+}
+
+func (c *Client) GetObjectMetadata(ctx context.Context, bucket, key string) (map[string]string, error) {
+	o, err := c.findObject(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	return copyMap(o.Metadata), nil
+}
+
+func (c *Client) HeadObject(ctx context.Context, bucket, key string) (*stu.ObjectHead, error) {
+	o, err := c.findObject(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	return &stu.ObjectHead{
+		Size:         int64(len(o.Content)),
+		ContentType:  o.Metadata["content-type"],
+		ETag:         fmt.Sprintf("%x", md5.Sum(o.Content)),
+		StorageClass: "STANDARD",
+		Metadata:     copyMap(o.Metadata),
+	}, nil
+}
+
+func (c *Client) GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	o, err := c.findObject(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.NopCloser(bytes.NewReader(o.Content)), nil
+}
+
+// PresignGetObject returns a fake URL, which cannot be opened.
+func (c *Client) PresignGetObject(ctx context.Context, bucket, key string, expires time.Duration) (string, error) {
+	if _, err := c.findObject(bucket, key); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("https://%s.s3.example.com/%s?X-Amz-Expires=%d", bucket, key, int(expires.Seconds())), nil
+}
+
+func (c *Client) GetObjectRange(ctx context.Context, bucket, key string, offset, length int64) (io.ReadCloser, error) {
+	o, err := c.findObject(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	size := int64(len(o.Content))
+	if offset > size {
+		offset = size
+	}
+	end := offset + length
+	if end > size {
+		end = size
+	}
+	return ioutil.NopCloser(bytes.NewReader(o.Content[offset:end])), nil
+}
+
+func (c *Client) GetObjectRangeIfMatch(ctx context.Context, bucket, key, etag string, offset, length int64) (io.ReadCloser, error) {
+	o, err := c.findObject(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	if fmt.Sprintf("%x", md5.Sum(o.Content)) != etag {
+		return nil, stu.ErrObjectChanged
+	}
+	return c.GetObjectRange(ctx, bucket, key, offset, length)
+}
+
+func (c *Client) TouchObject(ctx context.Context, bucket, key string) error {
+	o, err := c.findObject(bucket, key)
+	if err != nil {
+		return err
+	}
+	o.LastModified = time.Now()
+	return nil
+}
+
+func (c *Client) CopyObject(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string, size int64) error {
+	o, err := c.findObject(srcBucket, srcKey)
+	if err != nil {
+		return err
+	}
+	if _, ok := c.buckets[dstBucket]; !ok {
+		return ErrNoSuchBucket
+	}
+	c.PutObject(dstBucket, &Object{
+		Key:          dstKey,
+		Content:      o.Content,
+		LastModified: time.Now(),
+		Tags:         copyMap(o.Tags),
+		Metadata:     copyMap(o.Metadata),
+	})
+	return nil
+}
+
+func (c *Client) DeleteObject(ctx context.Context, bucket, key string) error {
+	objs, ok := c.buckets[bucket]
+	if !ok {
+		return ErrNoSuchBucket
+	}
+	for i, o := range objs {
+		if o.Key == key {
+			c.buckets[bucket] = append(objs[:i], objs[i+1:]...)
+			return nil
+		}
+	}
+	// same as S3, deleting a missing key is not an error
+	return nil
+}
+
+func copyMap(m map[string]string) map[string]string {
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
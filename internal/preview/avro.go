@@ -0,0 +1,492 @@
+package preview
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"strings"
+)
+
+var avroMode = Mode{
+	Name: "avro",
+	render: func(data []byte) string {
+		return renderDecoded(decodeAvroContainer(data))
+	},
+}
+
+// avroSchemaMode decodes objects which are a sequence of datums without the container header.
+func avroSchemaMode(s *avroSchema) Mode {
+	return Mode{
+		Name: "avro",
+		render: func(data []byte) string {
+			return renderDecoded(decodeAvroDatums(s, bytes.NewReader(data)))
+		},
+	}
+}
+
+// renderDecoded shows each value as a JSON document, followed by the error which stopped decoding.
+func renderDecoded(values []interface{}, err error) string {
+	var sb strings.Builder
+	for _, v := range values {
+		b, merr := json.MarshalIndent(v, "", "  ")
+		if merr != nil {
+			err = merr
+			break
+		}
+		sb.Write(b)
+		sb.WriteString("\n")
+	}
+	if err != nil {
+		sb.WriteString(fmt.Sprintf("\nfailed to decode: %s\n", err))
+	}
+	// JSON escapes C0 controls but not DEL and C1 controls
+	return sanitize(sb.String())
+}
+
+type avroSchema struct {
+	typ     string
+	name    string
+	fields  []*avroField
+	symbols []string
+	items   *avroSchema
+	values  *avroSchema
+	types   []*avroSchema
+	size    int
+}
+
+type avroField struct {
+	name   string
+	schema *avroSchema
+}
+
+type avroNames map[string]*avroSchema
+
+func parseAvroSchema(b []byte) (*avroSchema, error) {
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return avroNames{}.parse(v, "")
+}
+
+func (names avroNames) parse(v interface{}, namespace string) (*avroSchema, error) {
+	switch v := v.(type) {
+	case string:
+		switch v {
+		case "null", "boolean", "int", "long", "float", "double", "bytes", "string":
+			return &avroSchema{typ: v}, nil
+		}
+		if s, ok := names[v]; ok {
+			return s, nil
+		}
+		if s, ok := names[namespace+"."+v]; ok {
+			return s, nil
+		}
+		return nil, fmt.Errorf("unknown avro type: %s", v)
+	case []interface{}:
+		s := &avroSchema{typ: "union"}
+		for _, t := range v {
+			ts, err := names.parse(t, namespace)
+			if err != nil {
+				return nil, err
+			}
+			s.types = append(s.types, ts)
+		}
+		return s, nil
+	case map[string]interface{}:
+		typ, _ := v["type"].(string)
+		name, _ := v["name"].(string)
+		if ns, ok := v["namespace"].(string); ok {
+			namespace = ns
+		}
+		s := &avroSchema{typ: typ, name: name}
+		switch typ {
+		case "record", "error", "enum", "fixed":
+			names.register(s, namespace)
+		}
+		switch typ {
+		case "record", "error":
+			s.typ = "record"
+			fields, _ := v["fields"].([]interface{})
+			for _, f := range fields {
+				fm, ok := f.(map[string]interface{})
+				if !ok {
+					return nil, errors.New("invalid avro record field")
+				}
+				fs, err := names.parse(fm["type"], namespace)
+				if err != nil {
+					return nil, err
+				}
+				fname, _ := fm["name"].(string)
+				s.fields = append(s.fields, &avroField{name: fname, schema: fs})
+			}
+		case "enum":
+			symbols, _ := v["symbols"].([]interface{})
+			for _, sym := range symbols {
+				str, _ := sym.(string)
+				s.symbols = append(s.symbols, str)
+			}
+		case "fixed":
+			size, _ := v["size"].(float64)
+			s.size = int(size)
+		case "array":
+			items, err := names.parse(v["items"], namespace)
+			if err != nil {
+				return nil, err
+			}
+			s.items = items
+		case "map":
+			values, err := names.parse(v["values"], namespace)
+			if err != nil {
+				return nil, err
+			}
+			s.values = values
+		default:
+			// primitive types with attributes such as logicalType
+			return names.parse(v["type"], namespace)
+		}
+		return s, nil
+	}
+	return nil, fmt.Errorf("invalid avro schema: %v", v)
+}
+
+func (names avroNames) register(s *avroSchema, namespace string) {
+	names[s.name] = s
+	if namespace != "" && !strings.Contains(s.name, ".") {
+		names[namespace+"."+s.name] = s
+	}
+}
+
+const (
+	avroMaxEmptyItems = 1 << 16
+	avroMaxDepth      = 100
+)
+
+type avroReader struct {
+	r *bytes.Reader
+	// nesting of the datum being read, a record containing itself is read without consuming bytes
+	depth int
+}
+
+func (r *avroReader) long() (int64, error) {
+	return binary.ReadVarint(r.r)
+}
+
+func (r *avroReader) bytes() ([]byte, error) {
+	n, err := r.long()
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 || n > int64(r.r.Len()) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := make([]byte, n)
+	_, err = io.ReadFull(r.r, b)
+	return b, err
+}
+
+func (r *avroReader) fixed(n int) ([]byte, error) {
+	b := make([]byte, n)
+	_, err := io.ReadFull(r.r, b)
+	return b, err
+}
+
+// read decodes a datum into a value which can be marshaled to JSON in the field order of the schema.
+func (r *avroReader) read(s *avroSchema) (interface{}, error) {
+	r.depth++
+	defer func() { r.depth-- }()
+	if r.depth > avroMaxDepth {
+		return nil, errors.New("avro datum is nested too deeply")
+	}
+	switch s.typ {
+	case "null":
+		return nil, nil
+	case "boolean":
+		b, err := r.r.ReadByte()
+		return b != 0, err
+	case "int", "long":
+		return r.long()
+	case "float":
+		b, err := r.fixed(4)
+		if err != nil {
+			return nil, err
+		}
+		return jsonFloat(math.Float32frombits(binary.LittleEndian.Uint32(b))), nil
+	case "double":
+		b, err := r.fixed(8)
+		if err != nil {
+			return nil, err
+		}
+		return jsonFloat(math.Float64frombits(binary.LittleEndian.Uint64(b))), nil
+	case "bytes":
+		return r.bytes()
+	case "string":
+		b, err := r.bytes()
+		return string(b), err
+	case "fixed":
+		return r.fixed(s.size)
+	case "enum":
+		n, err := r.long()
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 || int(n) >= len(s.symbols) {
+			return nil, fmt.Errorf("invalid enum index: %d", n)
+		}
+		return s.symbols[n], nil
+	case "union":
+		n, err := r.long()
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 || int(n) >= len(s.types) {
+			return nil, fmt.Errorf("invalid union index: %d", n)
+		}
+		return r.read(s.types[n])
+	case "record":
+		obj := make(orderedObject, 0, len(s.fields))
+		for _, f := range s.fields {
+			v, err := r.read(f.schema)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, orderedField{key: f.name, value: v})
+		}
+		return obj, nil
+	case "array":
+		arr := make([]interface{}, 0)
+		err := r.blocks(avroMinSize(s.items), func() error {
+			v, err := r.read(s.items)
+			arr = append(arr, v)
+			return err
+		})
+		return arr, err
+	case "map":
+		obj := make(orderedObject, 0)
+		// the key takes at least a byte
+		err := r.blocks(1, func() error {
+			k, err := r.bytes()
+			if err != nil {
+				return err
+			}
+			v, err := r.read(s.values)
+			obj = append(obj, orderedField{key: string(k), value: v})
+			return err
+		})
+		return obj, err
+	}
+	return nil, fmt.Errorf("unsupported avro type: %s", s.typ)
+}
+
+// blocks reads the items of arrays and maps, which are encoded in blocks with their count.
+// min is the minimum size of an item to reject the counts which do not fit in the remaining bytes.
+func (r *avroReader) blocks(min int64, fn func() error) error {
+	for {
+		n, err := r.long()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+		if n < 0 {
+			// followed by the size of the block in bytes
+			n = -n
+			if _, err := r.long(); err != nil {
+				return err
+			}
+		}
+		if err := checkAvroCount(n, min, r.r.Len()); err != nil {
+			return err
+		}
+		for i := int64(0); i < n; i++ {
+			if err := fn(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// checkAvroCount rejects the count of items which cannot be in the remaining bytes.
+// Items of zero width like null take no bytes, so their count is limited separately.
+func checkAvroCount(n, min int64, remaining int) error {
+	if n < 0 {
+		return fmt.Errorf("invalid avro item count: %d", n)
+	}
+	if min == 0 {
+		if n > avroMaxEmptyItems {
+			return fmt.Errorf("too many avro items without data: %d", n)
+		}
+		return nil
+	}
+	if n > int64(remaining)/min {
+		return fmt.Errorf("avro item count exceeds the data: %d", n)
+	}
+	return nil
+}
+
+// avroMinSize returns the minimum bytes of a datum of the schema.
+func avroMinSize(s *avroSchema) int64 {
+	return s.minSize(make(map[*avroSchema]int64))
+}
+
+// minSize memoizes the sizes of records, which are shared and can be recursive.
+func (s *avroSchema) minSize(memo map[*avroSchema]int64) int64 {
+	switch s.typ {
+	case "null":
+		return 0
+	case "float":
+		return 4
+	case "double":
+		return 8
+	case "fixed":
+		return int64(s.size)
+	case "record":
+		if n, ok := memo[s]; ok {
+			return n
+		}
+		// a record within itself is counted as empty
+		memo[s] = 0
+		var n int64
+		for _, f := range s.fields {
+			n += f.schema.minSize(memo)
+		}
+		memo[s] = n
+		return n
+	}
+	// varints, including the lengths, the counts and the indexes of unions
+	return 1
+}
+
+var avroMagic = []byte("Obj\x01")
+
+// decodeAvroContainer decodes an object container file, which contains its schema in the header.
+func decodeAvroContainer(data []byte) ([]interface{}, error) {
+	if !bytes.HasPrefix(data, avroMagic) {
+		return nil, errors.New("not an avro object container file")
+	}
+	r := &avroReader{r: bytes.NewReader(data[len(avroMagic):])}
+	meta, err := r.read(&avroSchema{typ: "map", values: &avroSchema{typ: "bytes"}})
+	if err != nil {
+		return nil, err
+	}
+	var schemaJSON []byte
+	codec := "null"
+	for _, f := range meta.(orderedObject) {
+		switch f.key {
+		case "avro.schema":
+			schemaJSON = f.value.([]byte)
+		case "avro.codec":
+			codec = string(f.value.([]byte))
+		}
+	}
+	s, err := parseAvroSchema(schemaJSON)
+	if err != nil {
+		return nil, err
+	}
+	sync, err := r.fixed(16)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, 0)
+	for r.r.Len() > 0 {
+		count, err := r.long()
+		if err != nil {
+			return values, err
+		}
+		block, err := r.bytes()
+		if err != nil {
+			return values, err
+		}
+		switch codec {
+		case "null":
+		case "deflate":
+			if block, err = ioutil.ReadAll(flate.NewReader(bytes.NewReader(block))); err != nil {
+				return values, err
+			}
+		default:
+			return values, fmt.Errorf("unsupported avro codec: %s", codec)
+		}
+		if err := checkAvroCount(count, avroMinSize(s), len(block)); err != nil {
+			return values, err
+		}
+		br := &avroReader{r: bytes.NewReader(block)}
+		for i := int64(0); i < count; i++ {
+			v, err := br.read(s)
+			if err != nil {
+				return values, err
+			}
+			values = append(values, v)
+		}
+		marker, err := r.fixed(16)
+		if err != nil {
+			return values, err
+		}
+		if !bytes.Equal(marker, sync) {
+			return values, errors.New("invalid avro sync marker")
+		}
+	}
+	return values, nil
+}
+
+func decodeAvroDatums(s *avroSchema, br *bytes.Reader) ([]interface{}, error) {
+	r := &avroReader{r: br}
+	values := make([]interface{}, 0)
+	for r.r.Len() > 0 {
+		v, err := r.read(s)
+		if err != nil {
+			return values, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+type orderedField struct {
+	key   string
+	value interface{}
+}
+
+// orderedObject is marshaled as a JSON object keeping the order of the fields.
+type orderedObject []orderedField
+
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(f.key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// jsonFloat is marshaled as a string if it is not a number, which encoding/json rejects.
+type jsonFloat float64
+
+func (f jsonFloat) MarshalJSON() ([]byte, error) {
+	v := float64(f)
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return json.Marshal(fmt.Sprint(v))
+	}
+	return json.Marshal(v)
+}
@@ -0,0 +1,168 @@
+package preview
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// avroLong encodes the zigzag varint of avro.
+func avroLong(n int64) []byte {
+	b := make([]byte, binary.MaxVarintLen64)
+	return b[:binary.PutVarint(b, n)]
+}
+
+func avroString(s string) []byte {
+	return append(avroLong(int64(len(s))), s...)
+}
+
+func concat(bs ...[]byte) []byte {
+	return bytes.Join(bs, nil)
+}
+
+func marshalValues(t *testing.T, values []interface{}) string {
+	t.Helper()
+	b, err := json.Marshal(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+const avroTestSchema = `{
+	"type": "record", "name": "User", "namespace": "test",
+	"fields": [
+		{"name": "name", "type": "string"},
+		{"name": "age", "type": ["null", "int"]},
+		{"name": "tags", "type": {"type": "array", "items": "string"}},
+		{"name": "kind", "type": {"type": "enum", "name": "Kind", "symbols": ["A", "B"]}}
+	]
+}`
+
+func TestDecodeAvroDatums(t *testing.T) {
+	user := concat(avroString("alice"), avroLong(1), avroLong(30), avroLong(2), avroString("x"), avroString("y"), avroLong(0), avroLong(1))
+	tests := []struct {
+		name    string
+		schema  string
+		data    []byte
+		want    string
+		wantErr bool
+	}{
+		{name: "record", schema: avroTestSchema, data: user, want: `[{"name":"alice","age":30,"tags":["x","y"],"kind":"B"}]`},
+		{name: "two records", schema: avroTestSchema, data: concat(user, avroString("bob"), avroLong(0), avroLong(0), avroLong(0)),
+			want: `[{"name":"alice","age":30,"tags":["x","y"],"kind":"B"},{"name":"bob","age":null,"tags":[],"kind":"A"}]`},
+		{name: "map", schema: `{"type": "map", "values": "long"}`, data: concat(avroLong(2), avroString("b"), avroLong(-1), avroString("a"), avroLong(2), avroLong(0)),
+			want: `[{"b":-1,"a":2}]`},
+		{name: "negative block count", schema: `{"type": "array", "items": "int"}`, data: concat(avroLong(-2), avroLong(2), avroLong(1), avroLong(2), avroLong(0)),
+			want: `[[1,2]]`},
+		{name: "truncated", schema: avroTestSchema, data: user[:3], want: `[]`, wantErr: true},
+		{name: "invalid enum", schema: avroTestSchema, data: concat(avroString("a"), avroLong(0), avroLong(0), avroLong(5)), want: `[]`, wantErr: true},
+		{name: "count beyond data", schema: `{"type": "array", "items": "int"}`, data: concat(avroLong(1<<40), avroLong(1)), want: `[]`, wantErr: true},
+		{name: "huge count of nulls", schema: `{"type": "array", "items": "null"}`, data: avroLong(1 << 62), want: `[]`, wantErr: true},
+		{name: "huge map", schema: `{"type": "map", "values": "null"}`, data: avroLong(1 << 62), want: `[]`, wantErr: true},
+		{name: "record in itself", schema: `{"type": "record", "name": "R", "fields": [{"name": "r", "type": "R"}]}`, data: []byte{0}, want: `[]`, wantErr: true},
+	}
+	for _, tt := range tests {
+		s, err := parseAvroSchema([]byte(tt.schema))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		values, err := decodeAvroDatums(s, bytes.NewReader(tt.data))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if got := marshalValues(t, values); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func avroContainer(codec string, count int64, block []byte) []byte {
+	sync := bytes.Repeat([]byte{0xab}, 16)
+	if codec == "deflate" {
+		var buf bytes.Buffer
+		w, _ := flate.NewWriter(&buf, flate.DefaultCompression)
+		w.Write(block)
+		w.Close()
+		block = buf.Bytes()
+	}
+	return concat(avroMagic,
+		avroLong(2), avroString("avro.schema"), avroString(`{"type": "array", "items": "long"}`), avroString("avro.codec"), avroString(codec), avroLong(0),
+		sync, avroLong(count), avroLong(int64(len(block))), block, sync)
+}
+
+func TestDecodeAvroContainer(t *testing.T) {
+	block := concat(avroLong(2), avroLong(1), avroLong(2), avroLong(0), avroLong(0))
+	tests := []struct {
+		name    string
+		data    []byte
+		want    string
+		wantErr bool
+	}{
+		{name: "null codec", data: avroContainer("null", 2, block), want: `[[1,2],[]]`},
+		{name: "deflate codec", data: avroContainer("deflate", 2, block), want: `[[1,2],[]]`},
+		{name: "unsupported codec", data: avroContainer("snappy", 2, block), want: `[]`, wantErr: true},
+		{name: "block count beyond data", data: avroContainer("null", 1<<62, block), want: `[]`, wantErr: true},
+		{name: "not a container", data: []byte("Obj"), want: `null`, wantErr: true},
+	}
+	for _, tt := range tests {
+		values, err := decodeAvroContainer(tt.data)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if got := marshalValues(t, values); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSchemaRegistry(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/schemas/ids/1":
+			json.NewEncoder(w).Encode(map[string]string{"schema": `{"type": "string"}`})
+		case "/schemas/ids/2":
+			json.NewEncoder(w).Encode(map[string]string{"schema": `syntax = "proto3";`, "schemaType": "PROTOBUF"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    string
+		wantErr string
+	}{
+		{name: "datums", data: concat([]byte{0, 0, 0, 0, 1}, avroString("a"), []byte{0, 0, 0, 0, 1}, avroString("b")), want: `["a","b"]`},
+		{name: "unknown id", data: concat([]byte{0, 0, 0, 0, 3}, avroString("a")), want: `[]`, wantErr: "404"},
+		{name: "protobuf schema", data: concat([]byte{0, 0, 0, 0, 2}, avroString("a")), want: `[]`, wantErr: "PROTOBUF"},
+		{name: "no magic byte", data: concat([]byte{1, 0, 0, 0, 1}, avroString("a")), want: `[]`, wantErr: "wire format"},
+		{name: "truncated header", data: []byte{0, 0}, want: `[]`, wantErr: "EOF"},
+	}
+	r := newSchemaRegistry(server.URL + "/")
+	for _, tt := range tests {
+		values, err := r.decode(tt.data)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("%s: err = %v, want %q", tt.name, err, tt.wantErr)
+		}
+		if got := marshalValues(t, values); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+	// the schema 1 is fetched only once
+	if requests != 3 {
+		t.Errorf("requests = %d, want 3", requests)
+	}
+}
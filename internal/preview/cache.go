@@ -0,0 +1,97 @@
+package preview
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Cache keeps rendered previews of objects while their ETags are unchanged.
+// The least recently used ones are dropped when the total size exceeds the limit.
+type Cache struct {
+	mu       sync.Mutex
+	maxBytes int
+	bytes    int
+	ll       *list.List
+	entries  map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key      string
+	etag     string
+	rendered string
+}
+
+func NewCache(maxBytes int) *Cache {
+	return &Cache{
+		maxBytes: maxBytes,
+		ll:       list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+func (*Cache) cacheKey(bucket, key string, mode Mode) string {
+	return bucket + "/" + key + "\x00" + mode.Name
+}
+
+func (c *Cache) Get(bucket, key, etag string, mode Mode) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[c.cacheKey(bucket, key, mode)]
+	if !ok {
+		return "", false
+	}
+	entry := e.Value.(*cacheEntry)
+	if entry.etag != etag {
+		c.remove(e)
+		return "", false
+	}
+	c.ll.MoveToFront(e)
+	return entry.rendered, true
+}
+
+func (c *Cache) Put(bucket, key, etag string, mode Mode, rendered string) {
+	if len(rendered) > c.maxBytes {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	k := c.cacheKey(bucket, key, mode)
+	if e, ok := c.entries[k]; ok {
+		c.remove(e)
+	}
+	c.entries[k] = c.ll.PushFront(&cacheEntry{key: k, etag: etag, rendered: rendered})
+	c.bytes += len(rendered)
+	for c.bytes > c.maxBytes {
+		c.remove(c.ll.Back())
+	}
+}
+
+// Render returns the cached preview or renders it with data. Failed renders are not cached.
+func (c *Cache) Render(bucket, key, etag string, mode Mode, data []byte) (string, error) {
+	if rendered, ok := c.Get(bucket, key, etag, mode); ok {
+		return rendered, nil
+	}
+	rendered, err := mode.Render(data)
+	if err != nil {
+		return "", err
+	}
+	c.Put(bucket, key, etag, mode, rendered)
+	return rendered, nil
+}
+
+// Purge drops all entries and returns the number of freed bytes.
+func (c *Cache) Purge() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	freed := c.bytes
+	c.ll.Init()
+	c.entries = make(map[string]*list.Element)
+	c.bytes = 0
+	return freed
+}
+
+func (c *Cache) remove(e *list.Element) {
+	entry := c.ll.Remove(e).(*cacheEntry)
+	delete(c.entries, entry.key)
+	c.bytes -= len(entry.rendered)
+}
@@ -0,0 +1,195 @@
+package preview
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"unicode/utf8"
+)
+
+// windows1252 maps 0x80-0x9f of windows-1252, which is used for latin1 labels as browsers and mail clients do.
+var windows1252 = [32]rune{
+	0x20AC, 0x0081, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021, 0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0x008D, 0x017D, 0x008F,
+	0x0090, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014, 0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0x009D, 0x017E, 0x0178,
+}
+
+var charsetDecoders = map[string]func([]byte) string{
+	"utf-8":        decodeUTF8,
+	"utf8":         decodeUTF8,
+	"us-ascii":     decodeUTF8,
+	"ascii":        decodeUTF8,
+	"iso-8859-1":   decodeLatin1,
+	"iso8859-1":    decodeLatin1,
+	"latin1":       decodeLatin1,
+	"windows-1252": decodeLatin1,
+	"cp1252":       decodeLatin1,
+	"shift_jis":    decodeShiftJIS,
+	"shift-jis":    decodeShiftJIS,
+	"sjis":         decodeShiftJIS,
+	"x-sjis":       decodeShiftJIS,
+	"windows-31j":  decodeShiftJIS,
+	"cp932":        decodeShiftJIS,
+	"iso-2022-jp":  decodeISO2022JP,
+	"csiso2022jp":  decodeISO2022JP,
+}
+
+// decodeCharset converts the text in the charset to UTF-8, false if the charset is not supported.
+// Characters which cannot be converted are replaced with U+FFFD.
+func decodeCharset(charset string, b []byte) (string, bool) {
+	decode, ok := charsetDecoders[strings.ToLower(strings.TrimSpace(charset))]
+	if !ok {
+		return "", false
+	}
+	return decode(b), true
+}
+
+// charsetReader is the CharsetReader of mime.WordDecoder.
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	b, err := ioutil.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := decodeCharset(charset, b)
+	if !ok {
+		return nil, fmt.Errorf("unsupported charset: %s", charset)
+	}
+	return strings.NewReader(s), nil
+}
+
+func decodeUTF8(b []byte) string {
+	return string(bytes.ToValidUTF8(b, []byte(string(utf8.RuneError))))
+}
+
+func decodeLatin1(b []byte) string {
+	var sb strings.Builder
+	sb.Grow(len(b))
+	for _, c := range b {
+		if c >= 0x80 && c < 0xa0 {
+			sb.WriteRune(windows1252[c-0x80])
+		} else {
+			sb.WriteRune(rune(c))
+		}
+	}
+	return sb.String()
+}
+
+// jisRune returns the character of JIS X 0208 at the row and cell (1-94).
+func jisRune(row, cell int) rune {
+	if row < 1 || row > 94 || cell < 1 || cell > 94 {
+		return utf8.RuneError
+	}
+	if r := jis0208[(row-1)*94+cell-1]; r != 0 {
+		return rune(r)
+	}
+	return utf8.RuneError
+}
+
+func decodeShiftJIS(b []byte) string {
+	var sb strings.Builder
+	sb.Grow(len(b))
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		switch {
+		case c < 0x80:
+			sb.WriteByte(c)
+		case c >= 0xa1 && c <= 0xdf:
+			// halfwidth katakana
+			sb.WriteRune(rune(c) - 0xa1 + 0xff61)
+		case (c >= 0x81 && c <= 0x9f) || (c >= 0xe0 && c <= 0xef):
+			if i+1 >= len(b) {
+				sb.WriteRune(utf8.RuneError)
+				break
+			}
+			t := b[i+1]
+			if t < 0x40 || t == 0x7f || t > 0xfc {
+				// the second byte is read again as it may be ASCII
+				sb.WriteRune(utf8.RuneError)
+				break
+			}
+			i++
+			row := int(c-0x81)*2 + 1
+			if c >= 0xe0 {
+				row = int(c-0xc1)*2 + 1
+			}
+			var cell int
+			switch {
+			case t >= 0x9f:
+				row++
+				cell = int(t) - 0x9e
+			case t >= 0x80:
+				cell = int(t) - 0x40
+			default:
+				cell = int(t) - 0x3f
+			}
+			sb.WriteRune(jisRune(row, cell))
+		case c >= 0xf0 && c <= 0xfc:
+			// user-defined and vendor extensions
+			if i+1 < len(b) && b[i+1] >= 0x40 && b[i+1] != 0x7f && b[i+1] <= 0xfc {
+				i++
+			}
+			sb.WriteRune(utf8.RuneError)
+		default:
+			sb.WriteRune(utf8.RuneError)
+		}
+	}
+	return sb.String()
+}
+
+// decodeISO2022JP switches the character sets by the escape sequences,
+// which start in ASCII and should return to it at the end of each line.
+func decodeISO2022JP(b []byte) string {
+	const (
+		ascii = iota
+		roman
+		katakana
+		jis
+	)
+	var sb strings.Builder
+	sb.Grow(len(b) * 3 / 2)
+	set := ascii
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		if c == 0x1b && i+2 < len(b) {
+			switch string(b[i+1 : i+3]) {
+			case "(B":
+				set = ascii
+			case "(J":
+				set = roman
+			case "(I":
+				set = katakana
+			case "$@", "$B":
+				set = jis
+			default:
+				sb.WriteRune(utf8.RuneError)
+				continue
+			}
+			i += 2
+			continue
+		}
+		switch {
+		case c == '\n' || c == '\r':
+			set = ascii
+			sb.WriteByte(c)
+		case c >= 0x80:
+			sb.WriteRune(utf8.RuneError)
+		case set == jis && c >= 0x21 && c <= 0x7e:
+			if i+1 >= len(b) {
+				sb.WriteRune(utf8.RuneError)
+				break
+			}
+			sb.WriteRune(jisRune(int(c)-0x20, int(b[i+1])-0x20))
+			i++
+		case set == katakana && c >= 0x21 && c <= 0x5f:
+			sb.WriteRune(rune(c) - 0x21 + 0xff61)
+		case set == roman && c == 0x5c:
+			sb.WriteRune('¥')
+		case set == roman && c == 0x7e:
+			sb.WriteRune('‾')
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,35 @@
+package preview
+
+import "testing"
+
+func TestDecodeCharset(t *testing.T) {
+	tests := []struct {
+		name    string
+		charset string
+		data    string
+		want    string
+		wantOK  bool
+	}{
+		{name: "utf-8", charset: "UTF-8", data: "あ\xffい", want: "あ�い", wantOK: true},
+		{name: "latin1", charset: "ISO-8859-1", data: "caf\xe9", want: "café", wantOK: true},
+		{name: "windows-1252 range", charset: "latin1", data: "\x80 \x93q\x94", want: "€ “q”", wantOK: true},
+		{name: "shift_jis", charset: "Shift_JIS", data: "\x93\xfa\x96{\x8c\xea\x82\xcc\x83e\x83L\x83X\x83g \xb6\xc5 \x87@", want: "日本語のテキスト ｶﾅ ①", wantOK: true},
+		{name: "shift_jis truncated", charset: "shift_jis", data: "a\x82", want: "a�", wantOK: true},
+		{name: "shift_jis invalid trail", charset: "shift_jis", data: "\x82\x20b", want: "� b", wantOK: true},
+		{name: "iso-2022-jp", charset: "ISO-2022-JP", data: "\x1b$B$3$s$K$A$O!\"@$3&\x1b(B ABC", want: "こんにちは、世界 ABC", wantOK: true},
+		{name: "iso-2022-jp katakana", charset: "iso-2022-jp", data: "\x1b(I6@6E\x1b(B", want: "ｶﾀｶﾅ", wantOK: true},
+		{name: "iso-2022-jp reset by newline", charset: "iso-2022-jp", data: "\x1b$B$\"\nab", want: "あ\nab", wantOK: true},
+		{name: "iso-2022-jp truncated", charset: "iso-2022-jp", data: "\x1b$B$", want: "�", wantOK: true},
+		{name: "unsupported", charset: "koi8-r", data: "abc"},
+	}
+	for _, tt := range tests {
+		got, ok := decodeCharset(tt.charset, []byte(tt.data))
+		if ok != tt.wantOK {
+			t.Errorf("%s: ok = %v, want %v", tt.name, ok, tt.wantOK)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package preview
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	emlHeaderNameStyle = lipgloss.NewStyle().Bold(true)
+	emlSectionStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+)
+
+var emlMode = Mode{
+	Name:   "eml",
+	render: renderEML,
+}
+
+var emlHeaders = []string{"From", "To", "Cc", "Subject", "Date"}
+
+const (
+	emlMaxDepth = 10
+)
+
+type emlMessage struct {
+	body        string
+	html        string
+	attachments []string
+}
+
+// renderEML shows the main headers, the text body and the list of attachments of the message.
+func renderEML(data []byte) string {
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Sprintf("failed to parse the message: %s\n\n%s", err, sanitize(string(data)))
+	}
+	var sb strings.Builder
+	dec := &mime.WordDecoder{CharsetReader: charsetReader}
+	for _, name := range emlHeaders {
+		v := msg.Header.Get(name)
+		if v == "" {
+			continue
+		}
+		if decoded, err := dec.DecodeHeader(v); err == nil {
+			v = decoded
+		}
+		sb.WriteString(emlHeaderNameStyle.Render(name+":") + " " + sanitize(v) + "\n")
+	}
+
+	m := &emlMessage{}
+	m.readPart(msg.Header, msg.Body, 0)
+
+	sb.WriteString("\n")
+	switch {
+	case m.body != "":
+		sb.WriteString(strings.TrimRight(sanitize(m.body), "\n") + "\n")
+	case m.html != "":
+		sb.WriteString(renderHTML([]byte(m.html)) + "\n")
+	}
+	if len(m.attachments) > 0 {
+		sb.WriteString("\n" + emlSectionStyle.Render(fmt.Sprintf("Attachments (%d)", len(m.attachments))) + "\n")
+		for _, a := range m.attachments {
+			sb.WriteString("  " + a + "\n")
+		}
+	}
+	return sb.String()
+}
+
+type emlHeader interface {
+	Get(key string) string
+}
+
+// readPart walks the parts recursively, the first text part is used as the body.
+func (m *emlMessage) readPart(h emlHeader, r io.Reader, depth int) {
+	mediaType, params, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		mediaType = "text/plain"
+	}
+	disposition, dparams, _ := mime.ParseMediaType(h.Get("Content-Disposition"))
+
+	if strings.HasPrefix(mediaType, "multipart/") && depth < emlMaxDepth {
+		mr := multipart.NewReader(r, params["boundary"])
+		for {
+			p, err := mr.NextRawPart()
+			if err != nil {
+				return
+			}
+			m.readPart(p.Header, p, depth+1)
+		}
+	}
+
+	filename := dparams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+	if disposition == "attachment" || filename != "" || !strings.HasPrefix(mediaType, "text/") {
+		if filename == "" {
+			filename = "(unnamed)"
+		} else if decoded, err := (&mime.WordDecoder{CharsetReader: charsetReader}).DecodeHeader(filename); err == nil {
+			filename = decoded
+		}
+		m.attachments = append(m.attachments, sanitize(fmt.Sprintf("%s (%s)", filename, mediaType)))
+		return
+	}
+
+	b, err := ioutil.ReadAll(decodeTransfer(h.Get("Content-Transfer-Encoding"), r))
+	if err != nil {
+		return
+	}
+	text, ok := decodeCharset(params["charset"], b)
+	if !ok {
+		// utf-8 is assumed for unknown and missing charsets
+		text = string(b)
+	}
+	switch {
+	case mediaType == "text/html" && m.html == "":
+		m.html = text
+	case mediaType != "text/html" && m.body == "":
+		m.body = text
+	}
+}
+
+func decodeTransfer(encoding string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, r)
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	}
+	return r
+}
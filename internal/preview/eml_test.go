@@ -0,0 +1,66 @@
+package preview
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderEML(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{
+			name: "plain",
+			data: "From: alice@example.com\r\nSubject: hello\r\n\r\nbody\r\n",
+			want: []string{"From: alice@example.com", "Subject: hello", "body"},
+		},
+		{
+			name: "encoded headers",
+			data: "From: =?ISO-2022-JP?B?GyRCOzNFRBsoQg==?= <yamada@example.jp>\r\nSubject: =?UTF-8?Q?caf=C3=A9?=\r\n\r\nbody\r\n",
+			want: []string{"From: 山田 <yamada@example.jp>", "Subject: café"},
+		},
+		{
+			name: "iso-2022-jp body",
+			data: "Subject: =?ISO-2022-JP?B?GyRCN29MPiROJUYlOSVIGyhC?=\r\nContent-Type: text/plain; charset=ISO-2022-JP\r\nContent-Transfer-Encoding: 7bit\r\n\r\n\x1b$B$3$s$K$A$O!\"@$3&\x1b(B ABC\r\n",
+			want: []string{"Subject: 件名のテスト", "こんにちは、世界 ABC"},
+		},
+		{
+			name: "shift_jis base64 body",
+			data: "Content-Type: text/plain; charset=Shift_JIS\r\nContent-Transfer-Encoding: base64\r\n\r\nlnuVtoLFgreBQg0Kk/GNc5baDQo=\r\n",
+			want: []string{"本文です。\n二行目"},
+		},
+		{
+			name: "latin1 quoted-printable body",
+			data: "Content-Type: text/plain; charset=iso-8859-1\r\nContent-Transfer-Encoding: quoted-printable\r\n\r\ncaf=E9 =80\r\n",
+			want: []string{"café €"},
+		},
+		{
+			name: "multipart",
+			data: "Subject: files\r\nContent-Type: multipart/mixed; boundary=b\r\n\r\n" +
+				"--b\r\nContent-Type: text/plain; charset=utf-8\r\n\r\nsee attached\r\n" +
+				"--b\r\nContent-Type: application/pdf\r\nContent-Disposition: attachment; filename=\"=?UTF-8?Q?r=C3=A9sum=C3=A9.pdf?=\"\r\n\r\n%PDF\r\n" +
+				"--b--\r\n",
+			want: []string{"see attached", "Attachments (1)", "résumé.pdf (application/pdf)"},
+		},
+		{
+			name: "html only",
+			data: "Content-Type: text/html; charset=utf-8\r\n\r\n<html><body><h1>Title</h1><p>text</p></body></html>\r\n",
+			want: []string{"# Title", "text"},
+		},
+		{
+			name: "not a message",
+			data: "no headers",
+			want: []string{"failed to parse the message"},
+		},
+	}
+	for _, tt := range tests {
+		got := plain(renderEML([]byte(tt.data)))
+		for _, w := range tt.want {
+			if !strings.Contains(got, w) {
+				t.Errorf("%s: %q is not in\n%s", tt.name, w, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package preview
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	codeKeywordStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("170"))
+	codeTypeStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+	codeStringStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("114"))
+	codeNumberStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("173"))
+	codeCommentStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+)
+
+// language is the lexical rules for highlighting, which are much simpler than the grammar
+// but enough to tell keywords, strings and comments apart.
+type language struct {
+	name         string
+	keywords     []string
+	types        []string
+	lineComments []string
+	blockComment [2]string
+	quotes       string
+}
+
+var (
+	langGo = &language{
+		name: "go",
+		keywords: []string{"break", "case", "chan", "const", "continue", "default", "defer", "else", "fallthrough", "for", "func",
+			"go", "goto", "if", "import", "interface", "map", "package", "range", "return", "select", "struct", "switch", "type", "var",
+			"true", "false", "nil", "iota"},
+		types: []string{"bool", "byte", "complex64", "complex128", "error", "float32", "float64", "int", "int8", "int16", "int32", "int64",
+			"rune", "string", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "any"},
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'`",
+	}
+	langRust = &language{
+		name: "rust",
+		keywords: []string{"as", "async", "await", "break", "const", "continue", "crate", "dyn", "else", "enum", "extern", "false", "fn",
+			"for", "if", "impl", "in", "let", "loop", "match", "mod", "move", "mut", "pub", "ref", "return", "self", "Self", "static",
+			"struct", "super", "trait", "true", "type", "unsafe", "use", "where", "while"},
+		types: []string{"bool", "char", "f32", "f64", "i8", "i16", "i32", "i64", "i128", "isize", "str", "u8", "u16", "u32", "u64", "u128",
+			"usize", "String", "Vec", "Option", "Result", "Box", "Some", "None", "Ok", "Err"},
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"",
+	}
+	langPython = &language{
+		name: "python",
+		keywords: []string{"and", "as", "assert", "async", "await", "break", "class", "continue", "def", "del", "elif", "else", "except",
+			"finally", "for", "from", "global", "if", "import", "in", "is", "lambda", "nonlocal", "not", "or", "pass", "raise", "return",
+			"try", "while", "with", "yield", "True", "False", "None"},
+		types:        []string{"bool", "bytes", "dict", "float", "int", "list", "object", "set", "str", "tuple"},
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+	}
+	langJavaScript = &language{
+		name: "javascript",
+		keywords: []string{"async", "await", "break", "case", "catch", "class", "const", "continue", "default", "delete", "do", "else",
+			"export", "extends", "false", "finally", "for", "from", "function", "if", "import", "in", "instanceof", "let", "new", "null",
+			"return", "super", "switch", "this", "throw", "true", "try", "typeof", "undefined", "var", "void", "while", "yield",
+			"interface", "type", "enum", "implements"},
+		types:        []string{"any", "boolean", "number", "string", "object", "unknown", "never"},
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'`",
+	}
+	langJSON = &language{
+		name:     "json",
+		keywords: []string{"true", "false", "null"},
+		quotes:   "\"",
+	}
+	langShell = &language{
+		name: "shell",
+		keywords: []string{"case", "do", "done", "elif", "else", "esac", "export", "fi", "for", "function", "if", "in", "local",
+			"return", "then", "until", "while"},
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+	}
+	langYAML = &language{
+		name:         "yaml",
+		keywords:     []string{"true", "false", "null", "yes", "no"},
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+	}
+	langSQL = &language{
+		name: "sql",
+		keywords: []string{"select", "from", "where", "and", "or", "not", "insert", "into", "values", "update", "set", "delete",
+			"create", "table", "drop", "alter", "join", "left", "right", "inner", "outer", "on", "group", "by", "order", "having",
+			"limit", "as", "null", "is", "in", "distinct", "union", "all", "primary", "key"},
+		types:        []string{"int", "integer", "bigint", "text", "varchar", "char", "boolean", "date", "timestamp", "real", "float"},
+		lineComments: []string{"--"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "'\"",
+	}
+)
+
+var langsByExt = map[string]*language{
+	".go":   langGo,
+	".rs":   langRust,
+	".py":   langPython,
+	".js":   langJavaScript,
+	".ts":   langJavaScript,
+	".json": langJSON,
+	".sh":   langShell,
+	".bash": langShell,
+	".yaml": langYAML,
+	".yml":  langYAML,
+	".toml": langYAML,
+	".sql":  langSQL,
+}
+
+func codeMode(lang *language) Mode {
+	words := make(map[string]lipgloss.Style)
+	for _, t := range lang.types {
+		words[t] = codeTypeStyle
+	}
+	for _, k := range lang.keywords {
+		words[k] = codeKeywordStyle
+	}
+	// keywords of SQL are case insensitive
+	fold := lang == langSQL
+	return Mode{
+		Name: lang.name,
+		render: func(data []byte) string {
+			return highlight(lang, words, fold, sanitize(string(data)))
+		},
+	}
+}
+
+// highlight styles each line separately so that the lines can be scrolled independently,
+// a block comment continues to the following lines.
+func highlight(lang *language, words map[string]lipgloss.Style, fold bool, src string) string {
+	lines := strings.Split(src, "\n")
+	inBlock := false
+	for i, line := range lines {
+		var sb strings.Builder
+		for len(line) > 0 {
+			if inBlock {
+				end := strings.Index(line, lang.blockComment[1])
+				if end < 0 {
+					sb.WriteString(codeCommentStyle.Render(line))
+					line = ""
+					break
+				}
+				end += len(lang.blockComment[1])
+				sb.WriteString(codeCommentStyle.Render(line[:end]))
+				line = line[end:]
+				inBlock = false
+				continue
+			}
+			if lang.blockComment[0] != "" && strings.HasPrefix(line, lang.blockComment[0]) {
+				inBlock = true
+				sb.WriteString(codeCommentStyle.Render(lang.blockComment[0]))
+				line = line[len(lang.blockComment[0]):]
+				continue
+			}
+			if hasAnyPrefix(line, lang.lineComments) {
+				sb.WriteString(codeCommentStyle.Render(line))
+				break
+			}
+			c := rune(line[0])
+			switch {
+			case strings.ContainsRune(lang.quotes, c):
+				n := scanString(line)
+				sb.WriteString(codeStringStyle.Render(line[:n]))
+				line = line[n:]
+			case unicode.IsDigit(c):
+				n := scanWord(line)
+				sb.WriteString(codeNumberStyle.Render(line[:n]))
+				line = line[n:]
+			case isWordChar(c):
+				n := scanWord(line)
+				word := line[:n]
+				key := word
+				if fold {
+					key = strings.ToLower(word)
+				}
+				if style, ok := words[key]; ok {
+					word = style.Render(word)
+				}
+				sb.WriteString(word)
+				line = line[n:]
+			default:
+				sb.WriteByte(line[0])
+				line = line[1:]
+			}
+		}
+		lines[i] = sb.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// scanString returns the length of the string literal at the beginning, or the rest of the line if it is not closed.
+func scanString(s string) int {
+	quote := s[0]
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+func scanWord(s string) int {
+	for i, c := range s {
+		if !isWordChar(c) && !(c == '.' && i > 0 && unicode.IsDigit(rune(s[0]))) {
+			return i
+		}
+	}
+	return len(s)
+}
+
+func isWordChar(c rune) bool {
+	return c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c)
+}
+
+// HighlightJSON highlights JSON outside of the preview, e.g. bucket policies.
+func HighlightJSON(src string) string {
+	return codeMode(langJSON).render([]byte(src))
+}
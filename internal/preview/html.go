@@ -0,0 +1,194 @@
+package preview
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	htmlHeadingStyle = lipgloss.NewStyle().Bold(true)
+	htmlLinkStyle    = lipgloss.NewStyle().Underline(true).Foreground(lipgloss.Color("39"))
+	htmlURLStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+)
+
+var htmlMode = Mode{
+	Name:   "html",
+	render: renderHTML,
+}
+
+var (
+	htmlSkipElements = map[string]bool{"head": true, "script": true, "style": true, "noscript": true, "template": true}
+	// elements which start a new line
+	htmlBlockElements = map[string]bool{
+		"p": true, "div": true, "br": true, "tr": true, "section": true, "article": true, "header": true, "footer": true,
+		"ul": true, "ol": true, "li": true, "pre": true, "blockquote": true, "table": true, "hr": true,
+		"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	}
+)
+
+type htmlRenderer struct {
+	lines   []string
+	line    strings.Builder
+	skip    int
+	pre     int
+	heading string
+	lists   []*htmlList
+	href    string
+	space   bool
+}
+
+type htmlList struct {
+	ordered bool
+	n       int
+}
+
+// renderHTML lays out headings, lists and links of the document as text.
+// The parser is lenient, so broken documents are rendered as far as they can be read.
+func renderHTML(data []byte) string {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.Strict = false
+	d.AutoClose = xml.HTMLAutoClose
+	d.Entity = xml.HTMLEntity
+
+	r := &htmlRenderer{}
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			r.start(strings.ToLower(t.Name.Local), t.Attr)
+		case xml.EndElement:
+			r.end(strings.ToLower(t.Name.Local))
+		case xml.CharData:
+			r.text(sanitize(string(t)))
+		}
+	}
+	r.flush()
+	return strings.Join(r.lines, "\n")
+}
+
+func (r *htmlRenderer) start(name string, attrs []xml.Attr) {
+	if htmlSkipElements[name] {
+		r.skip++
+		return
+	}
+	if r.skip > 0 {
+		return
+	}
+	if htmlBlockElements[name] {
+		r.flush()
+	}
+	switch name {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		r.heading = strings.Repeat("#", int(name[1]-'0')) + " "
+	case "ul", "ol":
+		r.lists = append(r.lists, &htmlList{ordered: name == "ol"})
+	case "li":
+		marker := "• "
+		if len(r.lists) > 0 {
+			l := r.lists[len(r.lists)-1]
+			if l.ordered {
+				l.n++
+				marker = strconv.Itoa(l.n) + ". "
+			}
+			marker = strings.Repeat("  ", len(r.lists)-1) + marker
+		}
+		r.line.WriteString(marker)
+	case "pre":
+		r.pre++
+	case "hr":
+		r.lines = append(r.lines, strings.Repeat("─", 40))
+	case "a":
+		for _, attr := range attrs {
+			if strings.ToLower(attr.Name.Local) == "href" {
+				r.href = sanitize(attr.Value)
+			}
+		}
+	}
+}
+
+func (r *htmlRenderer) end(name string) {
+	if htmlSkipElements[name] {
+		if r.skip > 0 {
+			r.skip--
+		}
+		return
+	}
+	if r.skip > 0 {
+		return
+	}
+	switch name {
+	case "ul", "ol":
+		if len(r.lists) > 0 {
+			r.lists = r.lists[:len(r.lists)-1]
+		}
+	case "pre":
+		if r.pre > 0 {
+			r.pre--
+		}
+	case "a":
+		if r.href != "" {
+			r.line.WriteString(" " + htmlURLStyle.Render("<"+r.href+">"))
+			r.href = ""
+		}
+	}
+	if htmlBlockElements[name] {
+		r.flush()
+	}
+}
+
+func (r *htmlRenderer) text(s string) {
+	if r.skip > 0 {
+		return
+	}
+	if r.pre > 0 {
+		for i, l := range strings.Split(s, "\n") {
+			if i > 0 {
+				r.flush()
+			}
+			r.line.WriteString(l)
+		}
+		return
+	}
+	// collapse whitespaces but keep the separation from the adjacent text
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		if s != "" && r.line.Len() > 0 {
+			r.space = true
+		}
+		return
+	}
+	if (r.space || strings.TrimLeftFunc(s, unicode.IsSpace) != s) && r.line.Len() > 0 {
+		r.line.WriteString(" ")
+	}
+	r.space = strings.TrimRightFunc(s, unicode.IsSpace) != s
+	s = strings.Join(words, " ")
+	switch {
+	case r.heading != "":
+		s = htmlHeadingStyle.Render(s)
+	case r.href != "":
+		s = htmlLinkStyle.Render(s)
+	}
+	r.line.WriteString(s)
+}
+
+func (r *htmlRenderer) flush() {
+	if r.line.Len() == 0 {
+		return
+	}
+	line := r.line.String()
+	if r.heading != "" {
+		line = htmlHeadingStyle.Render(r.heading) + line
+		r.heading = ""
+	}
+	r.lines = append(r.lines, line)
+	r.line.Reset()
+	r.space = false
+}
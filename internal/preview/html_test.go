@@ -0,0 +1,62 @@
+package preview
+
+import "testing"
+
+func TestRenderHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			"headings and paragraphs",
+			"<html><head><title>t</title></head><body><h1>Title</h1><p>first\n  paragraph</p><h3>Sub</h3><p>second</p></body></html>",
+			"# Title\nfirst paragraph\n### Sub\nsecond",
+		},
+		{
+			"lists",
+			"<ul><li>a</li><li>b<ol><li>c</li><li>d</li></ol></li></ul>",
+			"• a\n• b\n  1. c\n  2. d",
+		},
+		{
+			"links",
+			`<p>see <a href="https://example.com">the site</a>.</p>`,
+			"see the site <https://example.com>.",
+		},
+		{
+			"inline elements keep spaces",
+			"<p><b>bold</b> and <i>italic</i></p>",
+			"bold and italic",
+		},
+		{
+			"pre keeps lines",
+			"<pre>a  b\n  c</pre>",
+			"a  b\n  c",
+		},
+		{
+			"skipped elements",
+			"<script>alert(1)</script><style>p {}</style><p>text</p>",
+			"text",
+		},
+		{
+			"entities",
+			"<p>a &amp; b &lt;c&gt; &copy;</p>",
+			"a & b <c> ©",
+		},
+		{
+			"broken document",
+			"<p>unclosed <b>bold<p>next",
+			"unclosed bold\nnext",
+		},
+		{
+			"empty",
+			"",
+			"",
+		},
+	}
+	for _, tt := range tests {
+		if got := plain(renderHTML([]byte(tt.html))); got != tt.want {
+			t.Errorf("%s: renderHTML() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
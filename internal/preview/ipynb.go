@@ -0,0 +1,144 @@
+package preview
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	ipynbPromptStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+	ipynbCodeStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
+	ipynbOutputStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	ipynbErrorStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	ipynbHeadingStyle = lipgloss.NewStyle().Bold(true)
+)
+
+var ipynbMode = Mode{
+	Name:   "ipynb",
+	render: renderIPYNB,
+}
+
+type ipynbNotebook struct {
+	Cells []*ipynbCell `json:"cells"`
+}
+
+type ipynbCell struct {
+	CellType       string         `json:"cell_type"`
+	Source         ipynbText      `json:"source"`
+	ExecutionCount *int           `json:"execution_count"`
+	Outputs        []*ipynbOutput `json:"outputs"`
+}
+
+type ipynbOutput struct {
+	OutputType string               `json:"output_type"`
+	Text       ipynbText            `json:"text"`
+	Data       map[string]ipynbText `json:"data"`
+	EName      string               `json:"ename"`
+	EValue     string               `json:"evalue"`
+}
+
+// ipynbText is a multiline string, which is a list of lines or a single string in notebooks.
+type ipynbText string
+
+func (t *ipynbText) UnmarshalJSON(b []byte) error {
+	var lines []string
+	if err := json.Unmarshal(b, &lines); err == nil {
+		*t = ipynbText(strings.Join(lines, ""))
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		// such as JSON outputs, which are not shown
+		*t = ""
+		return nil
+	}
+	*t = ipynbText(s)
+	return nil
+}
+
+// renderIPYNB shows markdown cells as text with headings, and code cells with their text outputs.
+// Images and other rich outputs are shown by their types.
+func renderIPYNB(data []byte) string {
+	var nb ipynbNotebook
+	if err := json.Unmarshal(data, &nb); err != nil {
+		return fmt.Sprintf("failed to parse the notebook: %s", err)
+	}
+	blocks := make([]string, 0, len(nb.Cells))
+	for _, cell := range nb.Cells {
+		source := strings.TrimRight(sanitize(string(cell.Source)), "\n")
+		switch cell.CellType {
+		case "markdown":
+			blocks = append(blocks, renderIPYNBMarkdown(source))
+		case "code":
+			blocks = append(blocks, renderIPYNBCode(cell, source))
+		default:
+			blocks = append(blocks, source)
+		}
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+func renderIPYNBMarkdown(source string) string {
+	lines := strings.Split(source, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "#") {
+			lines[i] = ipynbHeadingStyle.Render(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func renderIPYNBCode(cell *ipynbCell, source string) string {
+	count := " "
+	if cell.ExecutionCount != nil {
+		count = fmt.Sprint(*cell.ExecutionCount)
+	}
+	prompt := fmt.Sprintf("In [%s]: ", count)
+	indent := strings.Repeat(" ", len(prompt))
+	lines := strings.Split(source, "\n")
+	for i, line := range lines {
+		p := indent
+		if i == 0 {
+			p = ipynbPromptStyle.Render(prompt)
+		}
+		lines[i] = p + ipynbCodeStyle.Render(line)
+	}
+	for _, out := range cell.Outputs {
+		lines = append(lines, renderIPYNBOutput(out)...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func renderIPYNBOutput(out *ipynbOutput) []string {
+	var text string
+	style := ipynbOutputStyle
+	switch out.OutputType {
+	case "stream":
+		text = string(out.Text)
+	case "execute_result", "display_data":
+		if plain, ok := out.Data["text/plain"]; ok {
+			text = string(plain)
+		}
+		for typ := range out.Data {
+			if strings.HasPrefix(typ, "image/") {
+				text = fmt.Sprintf("<%s>", typ)
+				break
+			}
+		}
+	case "error":
+		text = out.EName + ": " + out.EValue
+		style = ipynbErrorStyle
+	}
+	text = strings.TrimRight(sanitize(text), "\n")
+	if text == "" {
+		return nil
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = style.Render("  " + line)
+	}
+	return lines
+}
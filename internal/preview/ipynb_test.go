@@ -0,0 +1,64 @@
+package preview
+
+import "testing"
+
+func TestRenderIPYNB(t *testing.T) {
+	tests := []struct {
+		name string
+		nb   string
+		want string
+	}{
+		{
+			"markdown",
+			`{"cells": [{"cell_type": "markdown", "source": ["# Title\n", "text\n"]}]}`,
+			"# Title\ntext",
+		},
+		{
+			"code with a stream output",
+			`{"cells": [{"cell_type": "code", "execution_count": 3, "source": "print(1)\nprint(2)",
+				"outputs": [{"output_type": "stream", "text": ["1\n", "2\n"]}]}]}`,
+			"In [3]: print(1)\n        print(2)\n  1\n  2",
+		},
+		{
+			"code not executed",
+			`{"cells": [{"cell_type": "code", "execution_count": null, "source": "x", "outputs": []}]}`,
+			"In [ ]: x",
+		},
+		{
+			"execute result",
+			`{"cells": [{"cell_type": "code", "execution_count": 1, "source": "x",
+				"outputs": [{"output_type": "execute_result", "data": {"text/plain": ["42"], "application/json": {"a": 1}}}]}]}`,
+			"In [1]: x\n  42",
+		},
+		{
+			"image",
+			`{"cells": [{"cell_type": "code", "execution_count": 1, "source": "plot()",
+				"outputs": [{"output_type": "display_data", "data": {"image/png": "iVBOR", "text/plain": ["<Figure>"]}}]}]}`,
+			"In [1]: plot()\n  <image/png>",
+		},
+		{
+			"error",
+			`{"cells": [{"cell_type": "code", "execution_count": 2, "source": "1/0",
+				"outputs": [{"output_type": "error", "ename": "ZeroDivisionError", "evalue": "division by zero", "traceback": []}]}]}`,
+			"In [2]: 1/0\n  ZeroDivisionError: division by zero",
+		},
+		{
+			"raw cells and separation",
+			`{"cells": [{"cell_type": "raw", "source": "raw"}, {"cell_type": "markdown", "source": "md"}]}`,
+			"raw\n\nmd",
+		},
+		{
+			"empty notebook",
+			`{"cells": []}`,
+			"",
+		},
+	}
+	for _, tt := range tests {
+		if got := plain(renderIPYNB([]byte(tt.nb))); got != tt.want {
+			t.Errorf("%s: renderIPYNB() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if got := renderIPYNB([]byte("{")); got == "" {
+		t.Error("renderIPYNB() of broken JSON is empty")
+	}
+}
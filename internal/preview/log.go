@@ -0,0 +1,94 @@
+package preview
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	logLevelStyles = map[string]lipgloss.Style{
+		"FATAL": lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true),
+		"ERROR": lipgloss.NewStyle().Foreground(lipgloss.Color("196")),
+		"WARN":  lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
+		"INFO":  lipgloss.NewStyle().Foreground(lipgloss.Color("252")),
+		"DEBUG": lipgloss.NewStyle().Foreground(lipgloss.Color("244")),
+		"TRACE": lipgloss.NewStyle().Foreground(lipgloss.Color("240")),
+	}
+
+	logLevelPattern = regexp.MustCompile(`\b(?i:FATAL|CRITICAL|ERROR|ERR|WARNING|WARN|INFO|DEBUG|TRACE)\b`)
+)
+
+var logMode = Mode{
+	Name:   "log",
+	render: renderLog,
+	Tail:   true,
+}
+
+// renderLog colors each line by its level, which is the "level" field of JSON lines
+// or the first level name found in the line.
+func renderLog(data []byte) string {
+	lines := strings.Split(strings.TrimSuffix(sanitize(string(data)), "\n"), "\n")
+	for i, line := range lines {
+		if style, ok := logLevelStyles[logLevel(line)]; ok {
+			lines[i] = style.Render(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func logLevel(line string) string {
+	var level string
+	if strings.HasPrefix(strings.TrimSpace(line), "{") {
+		var v struct {
+			Level    string `json:"level"`
+			Severity string `json:"severity"`
+		}
+		if err := json.Unmarshal([]byte(line), &v); err == nil {
+			level = v.Level
+			if level == "" {
+				level = v.Severity
+			}
+		}
+	}
+	if level == "" {
+		level = logLevelPattern.FindString(line)
+	}
+	switch level = strings.ToUpper(level); level {
+	case "CRITICAL":
+		return "FATAL"
+	case "ERR":
+		return "ERROR"
+	case "WARNING":
+		return "WARN"
+	}
+	return level
+}
+
+// TailLines returns the last n lines of data.
+// If truncated, the first line is dropped since it may start in the middle.
+func TailLines(data []byte, truncated bool, n int) []byte {
+	if truncated {
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+		} else {
+			return []byte{}
+		}
+	}
+	end := len(data)
+	if end > 0 && data[end-1] == '\n' {
+		end--
+	}
+	start := end
+	for count := 0; count < n; count++ {
+		i := bytes.LastIndexByte(data[:start], '\n')
+		if i < 0 {
+			return data
+		}
+		start = i
+	}
+	return data[start+1:]
+}
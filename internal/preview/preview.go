@@ -0,0 +1,64 @@
+package preview
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Mode is a way to render the preview of an object.
+type Mode struct {
+	Name   string
+	render func(data []byte) string
+	// read the end of the object instead of the beginning
+	Tail bool
+	// the whole object is needed regardless of its size
+	Full bool
+}
+
+// Render renders the preview of data. The decoders read untrusted objects,
+// so a malformed one which makes them panic is reported as an error instead of crashing the UI.
+func (m Mode) Render(data []byte) (s string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to render the %s preview: %v", m.Name, r)
+		}
+	}()
+	return m.render(data), nil
+}
+
+var raw = Mode{
+	Name: "raw",
+	render: func(data []byte) string {
+		return sanitize(string(data))
+	},
+}
+
+var modesByExt = map[string][]Mode{
+	".avro":   {avroMode},
+	".html":   {htmlMode},
+	".htm":    {htmlMode},
+	".eml":    {emlMode},
+	".log":    {logMode},
+	".jsonl":  {logMode},
+	".db":     {sqliteMode},
+	".sqlite": {sqliteMode},
+	".ipynb":  {ipynbMode},
+}
+
+// Modes returns the modes available for the object, the first one is the default.
+// The raw source is always available as the last one.
+func Modes(key string) []Mode {
+	modes := make([]Mode, 0)
+	for _, s := range schemas {
+		if ok, _ := path.Match(s.pattern, key); ok {
+			modes = append(modes, s.mode)
+		}
+	}
+	ext := strings.ToLower(path.Ext(key))
+	modes = append(modes, modesByExt[ext]...)
+	if lang, ok := langsByExt[ext]; ok {
+		modes = append(modes, codeMode(lang))
+	}
+	return append(modes, raw)
+}
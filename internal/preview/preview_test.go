@@ -0,0 +1,25 @@
+package preview
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestModeRenderRecover(t *testing.T) {
+	mode := Mode{
+		Name: "broken",
+		render: func(data []byte) string {
+			return string(data[:len(data)+1])
+		},
+	}
+	if got, err := mode.Render([]byte("abc")); err == nil {
+		t.Errorf("Render() = %q, want error", got)
+	}
+}
+
+var ansiPattern = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// plain removes the styles, which depend on the terminal running the tests.
+func plain(s string) string {
+	return ansiPattern.ReplaceAllString(s, "")
+}
@@ -0,0 +1,324 @@
+package preview
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// protobufMode decodes the message with the schema read from a descriptor set
+// (protoc --descriptor_set_out --include_imports).
+func protobufMode(msgs map[string]*protoMessage, name string) Mode {
+	return Mode{
+		Name: "protobuf",
+		render: func(data []byte) string {
+			msg, ok := msgs[name]
+			if !ok {
+				return renderDecoded(nil, fmt.Errorf("message not found in the descriptor set: %s", name))
+			}
+			v, err := decodeProtobuf(msgs, msg, data, 0)
+			if err != nil {
+				return renderDecoded(nil, err)
+			}
+			return renderDecoded([]interface{}{v}, nil)
+		},
+	}
+}
+
+const (
+	protoWireVarint  = 0
+	protoWireFixed64 = 1
+	protoWireBytes   = 2
+	protoWireFixed32 = 5
+
+	protoMaxDepth = 64
+)
+
+// field types of FieldDescriptorProto
+const (
+	protoTypeDouble   = 1
+	protoTypeFloat    = 2
+	protoTypeInt64    = 3
+	protoTypeUint64   = 4
+	protoTypeInt32    = 5
+	protoTypeFixed64  = 6
+	protoTypeFixed32  = 7
+	protoTypeBool     = 8
+	protoTypeString   = 9
+	protoTypeGroup    = 10
+	protoTypeMessage  = 11
+	protoTypeBytes    = 12
+	protoTypeUint32   = 13
+	protoTypeEnum     = 14
+	protoTypeSfixed32 = 15
+	protoTypeSfixed64 = 16
+	protoTypeSint32   = 17
+	protoTypeSint64   = 18
+)
+
+type protoMessage struct {
+	name   string
+	fields map[uint64]*protoField
+	order  []uint64
+}
+
+type protoField struct {
+	name     string
+	typ      uint64
+	typeName string
+	repeated bool
+}
+
+type protoRecord struct {
+	num  uint64
+	wire uint64
+	// value of varint and fixed fields
+	n uint64
+	// value of length-delimited fields
+	b []byte
+}
+
+func readProtoRecords(data []byte) ([]*protoRecord, error) {
+	records := make([]*protoRecord, 0)
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, errors.New("invalid protobuf field key")
+		}
+		data = data[n:]
+		r := &protoRecord{num: key >> 3, wire: key & 7}
+		switch r.wire {
+		case protoWireVarint:
+			r.n, n = binary.Uvarint(data)
+			if n <= 0 {
+				return nil, errors.New("invalid protobuf varint")
+			}
+			data = data[n:]
+		case protoWireFixed64:
+			if len(data) < 8 {
+				return nil, errors.New("unexpected end of protobuf fixed64")
+			}
+			r.n, data = binary.LittleEndian.Uint64(data), data[8:]
+		case protoWireFixed32:
+			if len(data) < 4 {
+				return nil, errors.New("unexpected end of protobuf fixed32")
+			}
+			r.n, data = uint64(binary.LittleEndian.Uint32(data)), data[4:]
+		case protoWireBytes:
+			l, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < l {
+				return nil, errors.New("invalid protobuf length")
+			}
+			r.b, data = data[n:n+int(l)], data[n+int(l):]
+		default:
+			return nil, fmt.Errorf("unsupported protobuf wire type: %d", r.wire)
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+// parseDescriptorSet reads the messages of a FileDescriptorSet keyed by their full names.
+func parseDescriptorSet(data []byte) (map[string]*protoMessage, error) {
+	files, err := readProtoRecords(data)
+	if err != nil {
+		return nil, err
+	}
+	msgs := make(map[string]*protoMessage)
+	for _, f := range files {
+		if f.num != 1 {
+			continue
+		}
+		records, err := readProtoRecords(f.b)
+		if err != nil {
+			return nil, err
+		}
+		pkg := ""
+		for _, r := range records {
+			if r.num == 2 {
+				pkg = string(r.b)
+			}
+		}
+		for _, r := range records {
+			if r.num == 4 {
+				if err := parseDescriptor(msgs, pkg, r.b); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return msgs, nil
+}
+
+func parseDescriptor(msgs map[string]*protoMessage, scope string, data []byte) error {
+	records, err := readProtoRecords(data)
+	if err != nil {
+		return err
+	}
+	msg := &protoMessage{fields: make(map[uint64]*protoField)}
+	for _, r := range records {
+		if r.num == 1 {
+			msg.name = string(r.b)
+		}
+	}
+	if scope != "" {
+		msg.name = scope + "." + msg.name
+	}
+	for _, r := range records {
+		switch r.num {
+		case 2:
+			fr, err := readProtoRecords(r.b)
+			if err != nil {
+				return err
+			}
+			f := &protoField{}
+			var num uint64
+			for _, x := range fr {
+				switch x.num {
+				case 1:
+					f.name = string(x.b)
+				case 3:
+					num = x.n
+				case 4:
+					f.repeated = x.n == 3
+				case 5:
+					f.typ = x.n
+				case 6:
+					f.typeName = strings.TrimPrefix(string(x.b), ".")
+				}
+			}
+			msg.fields[num] = f
+			msg.order = append(msg.order, num)
+		case 3:
+			if err := parseDescriptor(msgs, msg.name, r.b); err != nil {
+				return err
+			}
+		}
+	}
+	msgs[msg.name] = msg
+	return nil
+}
+
+// decodeProtobuf decodes the message in the field order of the schema, unknown fields are keyed by their numbers.
+func decodeProtobuf(msgs map[string]*protoMessage, msg *protoMessage, data []byte, depth int) (interface{}, error) {
+	if depth > protoMaxDepth {
+		return nil, errors.New("protobuf message is nested too deeply")
+	}
+	records, err := readProtoRecords(data)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[uint64][]interface{})
+	order := append([]uint64{}, msg.order...)
+	for _, r := range records {
+		f, ok := msg.fields[r.num]
+		if !ok {
+			if _, seen := values[r.num]; !seen {
+				order = append(order, r.num)
+			}
+			values[r.num] = append(values[r.num], decodeProtoUnknown(r))
+			continue
+		}
+		vs, err := decodeProtoField(msgs, f, r, depth)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.name, err)
+		}
+		values[r.num] = append(values[r.num], vs...)
+	}
+
+	obj := make(orderedObject, 0, len(order))
+	for _, num := range order {
+		vs, ok := values[num]
+		if !ok {
+			continue
+		}
+		f, known := msg.fields[num]
+		switch {
+		case !known:
+			obj = append(obj, orderedField{key: strconv.FormatUint(num, 10), value: vs})
+		case f.repeated:
+			obj = append(obj, orderedField{key: f.name, value: vs})
+		default:
+			obj = append(obj, orderedField{key: f.name, value: vs[len(vs)-1]})
+		}
+	}
+	return obj, nil
+}
+
+func decodeProtoField(msgs map[string]*protoMessage, f *protoField, r *protoRecord, depth int) ([]interface{}, error) {
+	switch f.typ {
+	case protoTypeString:
+		return []interface{}{string(r.b)}, nil
+	case protoTypeBytes:
+		return []interface{}{base64.StdEncoding.EncodeToString(r.b)}, nil
+	case protoTypeMessage, protoTypeGroup:
+		sub, ok := msgs[f.typeName]
+		if !ok {
+			return []interface{}{decodeProtoUnknown(r)}, nil
+		}
+		v, err := decodeProtobuf(msgs, sub, r.b, depth+1)
+		return []interface{}{v}, err
+	}
+	if r.wire != protoWireBytes {
+		return []interface{}{decodeProtoScalar(f.typ, r.n)}, nil
+	}
+	// packed repeated scalars
+	vs := make([]interface{}, 0)
+	data := r.b
+	for len(data) > 0 {
+		var n uint64
+		switch f.typ {
+		case protoTypeDouble, protoTypeFixed64, protoTypeSfixed64:
+			if len(data) < 8 {
+				return nil, errors.New("unexpected end of packed field")
+			}
+			n, data = binary.LittleEndian.Uint64(data), data[8:]
+		case protoTypeFloat, protoTypeFixed32, protoTypeSfixed32:
+			if len(data) < 4 {
+				return nil, errors.New("unexpected end of packed field")
+			}
+			n, data = uint64(binary.LittleEndian.Uint32(data)), data[4:]
+		default:
+			var l int
+			n, l = binary.Uvarint(data)
+			if l <= 0 {
+				return nil, errors.New("invalid packed varint")
+			}
+			data = data[l:]
+		}
+		vs = append(vs, decodeProtoScalar(f.typ, n))
+	}
+	return vs, nil
+}
+
+func decodeProtoScalar(typ uint64, n uint64) interface{} {
+	switch typ {
+	case protoTypeDouble:
+		return jsonFloat(math.Float64frombits(n))
+	case protoTypeFloat:
+		return jsonFloat(math.Float32frombits(uint32(n)))
+	case protoTypeInt32, protoTypeSfixed32:
+		return int32(n)
+	case protoTypeInt64, protoTypeSfixed64:
+		return int64(n)
+	case protoTypeSint32, protoTypeSint64:
+		return int64(n>>1) ^ -int64(n&1)
+	case protoTypeBool:
+		return n != 0
+	case protoTypeEnum:
+		// the names of enum values are not resolved
+		return int32(n)
+	}
+	return n
+}
+
+func decodeProtoUnknown(r *protoRecord) interface{} {
+	if r.wire == protoWireBytes {
+		return base64.StdEncoding.EncodeToString(r.b)
+	}
+	return r.n
+}
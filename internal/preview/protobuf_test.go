@@ -0,0 +1,126 @@
+package preview
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func uvarint(v uint64) []byte {
+	b := make([]byte, binary.MaxVarintLen64)
+	return b[:binary.PutUvarint(b, v)]
+}
+
+func pbKey(num, wire uint64) []byte {
+	return uvarint(num<<3 | wire)
+}
+
+func pbVarint(num, v uint64) []byte {
+	return concat(pbKey(num, protoWireVarint), uvarint(v))
+}
+
+func pbBytes(num uint64, b []byte) []byte {
+	return concat(pbKey(num, protoWireBytes), uvarint(uint64(len(b))), b)
+}
+
+func pbFieldDescriptor(name string, num, label, typ uint64, typeName string) []byte {
+	b := concat(pbBytes(1, []byte(name)), pbVarint(3, num), pbVarint(4, label), pbVarint(5, typ))
+	if typeName != "" {
+		b = concat(b, pbBytes(6, []byte(typeName)))
+	}
+	return pbBytes(2, b)
+}
+
+// testDescriptorSet is the descriptor set of
+//
+//	package test;
+//	message Person {
+//	  message Address { string city = 1; }
+//	  string name = 1;
+//	  int32 id = 2;
+//	  repeated string tags = 3;
+//	  repeated sint64 scores = 4;
+//	  Address address = 5;
+//	  double ratio = 6;
+//	  Person parent = 7;
+//	}
+func testDescriptorSet() []byte {
+	address := concat(pbBytes(1, []byte("Address")), pbFieldDescriptor("city", 1, 1, protoTypeString, ""))
+	person := concat(pbBytes(1, []byte("Person")),
+		pbFieldDescriptor("name", 1, 1, protoTypeString, ""),
+		pbFieldDescriptor("id", 2, 1, protoTypeInt32, ""),
+		pbFieldDescriptor("tags", 3, 3, protoTypeString, ""),
+		pbFieldDescriptor("scores", 4, 3, protoTypeSint64, ""),
+		pbFieldDescriptor("address", 5, 1, protoTypeMessage, ".test.Person.Address"),
+		pbFieldDescriptor("ratio", 6, 1, protoTypeDouble, ""),
+		pbFieldDescriptor("parent", 7, 1, protoTypeMessage, ".test.Person"),
+		pbBytes(3, address))
+	file := concat(pbBytes(1, []byte("test.proto")), pbBytes(2, []byte("test")), pbBytes(4, person))
+	return pbBytes(1, file)
+}
+
+func TestDecodeProtobuf(t *testing.T) {
+	msgs, err := parseDescriptorSet(testDescriptorSet())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"test.Person", "test.Person.Address"} {
+		if _, ok := msgs[name]; !ok {
+			t.Fatalf("message not found: %s", name)
+		}
+	}
+
+	ratio := make([]byte, 8)
+	binary.LittleEndian.PutUint64(ratio, math.Float64bits(0.5))
+	nested := pbBytes(7, pbBytes(7, pbBytes(7, nil)))
+	deep := []byte{}
+	for i := 0; i < protoMaxDepth+2; i++ {
+		deep = pbBytes(7, deep)
+	}
+	tests := []struct {
+		name    string
+		data    []byte
+		want    string
+		wantErr bool
+	}{
+		{name: "empty", data: nil, want: `{}`},
+		{name: "scalars", data: concat(pbBytes(1, []byte("alice")), pbVarint(2, math.MaxUint64), pbKey(6, protoWireFixed64), ratio),
+			want: `{"name":"alice","id":-1,"ratio":0.5}`},
+		{name: "field order of the schema", data: concat(pbVarint(2, 7), pbBytes(1, []byte("bob"))), want: `{"name":"bob","id":7}`},
+		{name: "repeated", data: concat(pbBytes(3, []byte("a")), pbBytes(3, []byte("b"))), want: `{"tags":["a","b"]}`},
+		{name: "packed sint64", data: pbBytes(4, []byte{1, 2, 3}), want: `{"scores":[-1,1,-2]}`},
+		{name: "nested", data: pbBytes(5, pbBytes(1, []byte("Tokyo"))), want: `{"address":{"city":"Tokyo"}}`},
+		{name: "recursive", data: nested, want: `{"parent":{"parent":{"parent":{}}}}`},
+		{name: "unknown field", data: concat(pbVarint(1, 0), pbVarint(99, 5)), want: `{"name":"","99":[5]}`},
+		{name: "last value wins", data: concat(pbVarint(2, 1), pbVarint(2, 2)), want: `{"id":2}`},
+		{name: "truncated", data: pbBytes(1, []byte("alice"))[:3], wantErr: true},
+		{name: "invalid wire type", data: pbKey(1, 3), wantErr: true},
+		{name: "too deep", data: deep, wantErr: true},
+	}
+	for _, tt := range tests {
+		v, err := decodeProtobuf(msgs, msgs["test.Person"], tt.data, 0)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(b); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseDescriptorSetMalformed(t *testing.T) {
+	set := testDescriptorSet()
+	for i := range set {
+		// must not panic
+		parseDescriptorSet(set[:i])
+	}
+}
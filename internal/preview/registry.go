@@ -0,0 +1,109 @@
+package preview
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	registryTimeout = 10 * time.Second
+)
+
+// schemaRegistry fetches the Avro schemas of a Confluent compatible schema registry by their ids.
+type schemaRegistry struct {
+	url    string
+	client *http.Client
+
+	mu      sync.Mutex
+	schemas map[uint32]*avroSchema
+}
+
+func newSchemaRegistry(url string) *schemaRegistry {
+	return &schemaRegistry{
+		url:     strings.TrimSuffix(url, "/"),
+		client:  &http.Client{Timeout: registryTimeout},
+		schemas: make(map[uint32]*avroSchema),
+	}
+}
+
+// avroRegistryMode decodes objects of the registry's wire format,
+// which is a sequence of datums each prefixed with a zero byte and the 4 bytes id of its schema.
+func avroRegistryMode(r *schemaRegistry) Mode {
+	return Mode{
+		Name: "avro",
+		render: func(data []byte) string {
+			return renderDecoded(r.decode(data))
+		},
+	}
+}
+
+func (r *schemaRegistry) decode(data []byte) ([]interface{}, error) {
+	br := bytes.NewReader(data)
+	ar := &avroReader{r: br}
+	values := make([]interface{}, 0)
+	for br.Len() > 0 {
+		var header [5]byte
+		if _, err := io.ReadFull(br, header[:]); err != nil {
+			return values, err
+		}
+		if header[0] != 0 {
+			return values, errors.New("not in the wire format of the schema registry")
+		}
+		s, err := r.schema(binary.BigEndian.Uint32(header[1:]))
+		if err != nil {
+			return values, err
+		}
+		v, err := ar.read(s)
+		if err != nil {
+			return values, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func (r *schemaRegistry) schema(id uint32) (*avroSchema, error) {
+	r.mu.Lock()
+	s, ok := r.schemas[id]
+	r.mu.Unlock()
+	if ok {
+		return s, nil
+	}
+
+	resp, err := r.client.Get(fmt.Sprintf("%s/schemas/ids/%d", r.url, id))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get the schema %d from the registry: %s", id, resp.Status)
+	}
+	var body struct {
+		Schema     string `json:"schema"`
+		SchemaType string `json:"schemaType"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	// the type is omitted for avro
+	if body.SchemaType != "" && body.SchemaType != "AVRO" {
+		return nil, fmt.Errorf("unsupported schema type of the registry: %s", body.SchemaType)
+	}
+	s, err = parseAvroSchema([]byte(body.Schema))
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.schemas[id] = s
+	r.mu.Unlock()
+	return s, nil
+}
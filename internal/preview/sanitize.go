@@ -0,0 +1,48 @@
+package preview
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// sanitize makes the text from an object safe to write to the terminal.
+// Control characters other than newlines and tabs are shown in caret notation (ESC as ^[)
+// so that the object cannot move the cursor, change the title or write the clipboard (OSC 52).
+// C1 controls and invalid UTF-8 are replaced with U+FFFD, and CRLF is normalized to LF.
+// It has to be applied to the text before it is styled.
+func sanitize(s string) string {
+	if isSafeText(s) {
+		return s
+	}
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == '\r' && strings.HasPrefix(s[i+1:], "\n"):
+			// dropped, the following LF ends the line
+		case r == '\n' || r == '\t':
+			sb.WriteRune(r)
+		case r < 0x20:
+			sb.WriteByte('^')
+			sb.WriteByte(byte(r) + '@')
+		case r == 0x7f:
+			sb.WriteString("^?")
+		case r >= 0x80 && r < 0xa0, r == utf8.RuneError && size == 1:
+			sb.WriteRune(utf8.RuneError)
+		default:
+			sb.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return sb.String()
+}
+
+func isSafeText(s string) bool {
+	for _, r := range s {
+		if (r < 0x20 && r != '\n' && r != '\t') || (r >= 0x7f && r < 0xa0) || r == utf8.RuneError {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,55 @@
+package preview
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{name: "plain", s: "abc\tdef\nあいう", want: "abc\tdef\nあいう"},
+		{name: "crlf", s: "a\r\nb\r\n", want: "a\nb\n"},
+		{name: "lone cr", s: "a\rb", want: "a^Mb"},
+		{name: "csi", s: "\x1b[2J\x1b[31mred", want: "^[[2J^[[31mred"},
+		{name: "osc 52", s: "\x1b]52;c;ZXZpbA==\x07", want: "^[]52;c;ZXZpbA==^G"},
+		{name: "del", s: "a\x7fb", want: "a^?b"},
+		{name: "c1 csi", s: "a\u009b2Jb", want: "a�2Jb"},
+		{name: "invalid utf-8", s: "a\x9b2Jb", want: "a�2Jb"},
+		{name: "nul", s: "a\x00b", want: "a^@b"},
+	}
+	for _, tt := range tests {
+		if got := sanitize(tt.s); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestModesEscapeControls(t *testing.T) {
+	const evil = "\x1b]52;c;ZXZpbA==\x07\x1b[2J"
+	tests := []struct {
+		name string
+		mode Mode
+		data string
+	}{
+		{name: "raw", mode: raw, data: evil},
+		{name: "code", mode: codeMode(langGo), data: "// " + evil + "\nfunc main() {}\r\n"},
+		{name: "log", mode: logMode, data: "ERROR " + evil + "\r\n"},
+		{name: "html", mode: htmlMode, data: "<p>" + evil + "</p><a href=\"" + evil + "\">x</a>"},
+		{name: "eml", mode: emlMode, data: "Subject: " + evil + "\r\n\r\nbody " + evil + "\r\n"},
+		{name: "ipynb", mode: ipynbMode, data: `{"cells":[{"cell_type":"code","source":"\u001b[2J","outputs":[{"output_type":"stream","text":"\u001b]52;c;x\u0007"}]}]}`},
+	}
+	for _, tt := range tests {
+		got, err := tt.mode.Render([]byte(tt.data))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if s := plain(got); strings.ContainsAny(s, "\x1b\x07\r") {
+			t.Errorf("%s: control characters are not escaped: %q", tt.name, s)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package preview
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+
+	"github.com/lusingander/stu/internal/config"
+)
+
+type schema struct {
+	pattern string
+	mode    Mode
+}
+
+var schemas []*schema
+
+// LoadSchemas reads the configured schema files, which are used by Modes.
+func LoadSchemas(cfgs []config.SchemaConfig) error {
+	ss := make([]*schema, 0, len(cfgs))
+	for _, cfg := range cfgs {
+		if _, err := path.Match(cfg.Pattern, ""); err != nil {
+			return fmt.Errorf("invalid schema pattern %q: %w", cfg.Pattern, err)
+		}
+		if cfg.Registry != "" {
+			if cfg.Format != "avro" {
+				return fmt.Errorf("schema registry supports only avro: %s", cfg.Registry)
+			}
+			ss = append(ss, &schema{pattern: cfg.Pattern, mode: avroRegistryMode(newSchemaRegistry(cfg.Registry))})
+			continue
+		}
+		b, err := ioutil.ReadFile(cfg.File)
+		if err != nil {
+			return err
+		}
+		var mode Mode
+		switch cfg.Format {
+		case "avro":
+			s, err := parseAvroSchema(b)
+			if err != nil {
+				return fmt.Errorf("%s: %w", cfg.File, err)
+			}
+			mode = avroSchemaMode(s)
+		case "protobuf":
+			msgs, err := parseDescriptorSet(b)
+			if err != nil {
+				return fmt.Errorf("%s: %w", cfg.File, err)
+			}
+			if _, ok := msgs[cfg.Message]; !ok {
+				return fmt.Errorf("%s: message not found: %s", cfg.File, cfg.Message)
+			}
+			mode = protobufMode(msgs, cfg.Message)
+		default:
+			return fmt.Errorf("unknown schema format: %s", cfg.Format)
+		}
+		ss = append(ss, &schema{pattern: cfg.Pattern, mode: mode})
+	}
+	schemas = ss
+	return nil
+}
@@ -0,0 +1,396 @@
+package preview
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	sqliteTableStyle = lipgloss.NewStyle().Bold(true)
+	sqliteSQLStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+)
+
+var sqliteMode = Mode{
+	Name:   "sqlite",
+	render: renderSQLite,
+	Full:   true,
+}
+
+const (
+	sqlitePreviewRows   = 10
+	sqliteMaxValueWidth = 80
+	sqliteMaxDepth      = 32
+)
+
+var sqliteMagic = []byte("SQLite format 3\x00")
+
+// sqliteFile reads tables of a database file directly from its b-trees, which needs no driver.
+// Only reading rows in the order of the table is supported, so the preview shows the tables
+// with their row counts and first rows but cannot run queries.
+type sqliteFile struct {
+	data     []byte
+	pageSize int
+	usable   int
+}
+
+type sqliteTable struct {
+	name     string
+	sql      string
+	rootPage int
+}
+
+// renderSQLite shows the tables with their row counts and first rows.
+func renderSQLite(data []byte) string {
+	f, err := openSQLite(data)
+	if err != nil {
+		return fmt.Sprintf("failed to read the database: %s", err)
+	}
+	tables, err := f.tables()
+	if err != nil {
+		return fmt.Sprintf("failed to read the schema: %s", err)
+	}
+	var sb strings.Builder
+	for _, t := range tables {
+		rows := make([][]interface{}, 0)
+		count := 0
+		alias := sqliteRowidAlias(t.sql)
+		err := f.walk(t.rootPage, func(rowid int64, values []interface{}) {
+			if len(rows) < sqlitePreviewRows {
+				if alias >= 0 && alias < len(values) && values[alias] == nil {
+					values[alias] = rowid
+				}
+				rows = append(rows, values)
+			}
+			count++
+		})
+		sb.WriteString(sqliteTableStyle.Render(sanitize(t.name)))
+		if err != nil {
+			sb.WriteString(fmt.Sprintf(" (failed to read: %s)\n", err))
+		} else {
+			sb.WriteString(fmt.Sprintf(" (%d rows)\n", count))
+		}
+		sb.WriteString(sqliteSQLStyle.Render(sanitize(t.sql)) + "\n")
+		for _, row := range rows {
+			cols := make([]string, len(row))
+			for i, v := range row {
+				cols[i] = formatSQLiteValue(v)
+			}
+			sb.WriteString("  " + strings.Join(cols, " | ") + "\n")
+		}
+		if count > len(rows) {
+			sb.WriteString("  ...\n")
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// sqliteRowidAlias returns the index of the INTEGER PRIMARY KEY column, or -1.
+// The column is an alias of the rowid and is stored as NULL in the records.
+func sqliteRowidAlias(sql string) int {
+	start, end := strings.Index(sql, "("), strings.LastIndex(sql, ")")
+	if start < 0 || end < start {
+		return -1
+	}
+	depth, col := 0, 0
+	for _, field := range strings.FieldsFunc(strings.ToUpper(sql[start+1:end]), func(r rune) bool {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			return depth == 0
+		}
+		return false
+	}) {
+		words := strings.Fields(field)
+		if len(words) >= 2 && words[1] == "INTEGER" && strings.Contains(strings.Join(words[2:], " "), "PRIMARY KEY") {
+			return col
+		}
+		col++
+	}
+	return -1
+}
+
+func formatSQLiteValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return fmt.Sprintf("<blob %d bytes>", len(v))
+	case string:
+		if len(v) > sqliteMaxValueWidth {
+			v = v[:sqliteMaxValueWidth] + "..."
+		}
+		return sanitize(v)
+	}
+	return fmt.Sprint(v)
+}
+
+func openSQLite(data []byte) (*sqliteFile, error) {
+	if len(data) < 100 || !bytes.HasPrefix(data, sqliteMagic) {
+		return nil, errors.New("not a sqlite database")
+	}
+	pageSize := int(binary.BigEndian.Uint16(data[16:18]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	if pageSize < 512 || pageSize&(pageSize-1) != 0 {
+		return nil, fmt.Errorf("invalid page size: %d", pageSize)
+	}
+	usable := pageSize - int(data[20])
+	if usable < 480 {
+		return nil, fmt.Errorf("invalid reserved space: %d", data[20])
+	}
+	return &sqliteFile{
+		data:     data,
+		pageSize: pageSize,
+		usable:   usable,
+	}, nil
+}
+
+func (f *sqliteFile) page(n int) ([]byte, error) {
+	start := (n - 1) * f.pageSize
+	if n < 1 || start+f.pageSize > len(f.data) {
+		return nil, fmt.Errorf("page %d is out of the file (it may be truncated)", n)
+	}
+	return f.data[start : start+f.pageSize], nil
+}
+
+func (f *sqliteFile) tables() ([]*sqliteTable, error) {
+	tables := make([]*sqliteTable, 0)
+	err := f.walk(1, func(_ int64, values []interface{}) {
+		if len(values) < 5 || values[0] != "table" {
+			return
+		}
+		name, _ := values[1].(string)
+		root, _ := values[3].(int64)
+		sql, _ := values[4].(string)
+		tables = append(tables, &sqliteTable{
+			name:     name,
+			sql:      sql,
+			rootPage: int(root),
+		})
+	})
+	return tables, err
+}
+
+// walk calls fn with the rowid and the values of each row of the b-tree in order, the rowid is zero for indexes.
+// The file is untrusted, so every offset is checked and each page is read at most once,
+// which stops cyclic pointers from making the walk endless.
+func (f *sqliteFile) walk(root int, fn func(int64, []interface{})) error {
+	return f.walkPage(root, 0, make(map[int]bool), fn)
+}
+
+func (f *sqliteFile) walkPage(pageNum, depth int, visited map[int]bool, fn func(int64, []interface{})) error {
+	if depth > sqliteMaxDepth {
+		return errors.New("b-tree is too deep")
+	}
+	if visited[pageNum] {
+		return fmt.Errorf("page %d is referenced more than once", pageNum)
+	}
+	visited[pageNum] = true
+	p, err := f.page(pageNum)
+	if err != nil {
+		return err
+	}
+	hdr := 0
+	if pageNum == 1 {
+		hdr = 100
+	}
+	typ := p[hdr]
+	interior := typ == 0x02 || typ == 0x05
+	ptrs := hdr + 8
+	if interior {
+		ptrs = hdr + 12
+	}
+	cells := int(binary.BigEndian.Uint16(p[hdr+3 : hdr+5]))
+	if ptrs+2*cells > len(p) {
+		return fmt.Errorf("invalid cell count: %d", cells)
+	}
+	for i := 0; i < cells; i++ {
+		off := int(binary.BigEndian.Uint16(p[ptrs+2*i:]))
+		if off < ptrs+2*cells || off >= len(p) {
+			return errors.New("invalid cell pointer")
+		}
+		cell := p[off:]
+		switch typ {
+		case 0x05: // table interior
+			if len(cell) < 4 {
+				return errors.New("invalid cell")
+			}
+			if err := f.walkPage(int(binary.BigEndian.Uint32(cell)), depth+1, visited, fn); err != nil {
+				return err
+			}
+		case 0x0d: // table leaf
+			size, n := sqliteVarint(cell)
+			rowid, m := sqliteVarint(cell[n:])
+			if n == 0 || m == 0 {
+				return errors.New("invalid cell")
+			}
+			payload, err := f.payload(cell[n+m:], size, f.usable-35)
+			if err != nil {
+				return err
+			}
+			values, err := sqliteRecord(payload)
+			if err != nil {
+				return err
+			}
+			fn(rowid, values)
+		case 0x02, 0x0a: // index interior, index leaf
+			if typ == 0x02 {
+				if len(cell) < 4 {
+					return errors.New("invalid cell")
+				}
+				if err := f.walkPage(int(binary.BigEndian.Uint32(cell)), depth+1, visited, fn); err != nil {
+					return err
+				}
+				cell = cell[4:]
+			}
+			size, n := sqliteVarint(cell)
+			if n == 0 {
+				return errors.New("invalid cell")
+			}
+			payload, err := f.payload(cell[n:], size, ((f.usable-12)*64/255)-23)
+			if err != nil {
+				return err
+			}
+			values, err := sqliteRecord(payload)
+			if err != nil {
+				return err
+			}
+			fn(0, values)
+		default:
+			return fmt.Errorf("invalid b-tree page type: %d", typ)
+		}
+	}
+	if interior {
+		return f.walkPage(int(binary.BigEndian.Uint32(p[hdr+8:])), depth+1, visited, fn)
+	}
+	return nil
+}
+
+// payload reads the payload of a cell following the overflow pages if it does not fit in the page.
+func (f *sqliteFile) payload(cell []byte, size int64, maxLocal int) ([]byte, error) {
+	// a payload cannot be larger than the file, which also bounds the buffer allocated for it
+	if size < 0 || size > int64(len(f.data)) {
+		return nil, errors.New("invalid cell size")
+	}
+	n := int(size)
+	if n <= maxLocal {
+		if n > len(cell) {
+			return nil, errors.New("invalid cell size")
+		}
+		return cell[:n], nil
+	}
+	minLocal := ((f.usable-12)*32/255 - 23)
+	local := minLocal + (n-minLocal)%(f.usable-4)
+	if local > maxLocal {
+		local = minLocal
+	}
+	if local+4 > len(cell) {
+		return nil, errors.New("invalid cell size")
+	}
+	payload := make([]byte, 0, n)
+	payload = append(payload, cell[:local]...)
+	next := int(binary.BigEndian.Uint32(cell[local:]))
+	visited := make(map[int]bool)
+	for len(payload) < n {
+		if visited[next] {
+			return nil, fmt.Errorf("overflow page %d is referenced more than once", next)
+		}
+		visited[next] = true
+		p, err := f.page(next)
+		if err != nil {
+			return nil, err
+		}
+		m := n - len(payload)
+		if m > f.usable-4 {
+			m = f.usable - 4
+		}
+		payload = append(payload, p[4:4+m]...)
+		next = int(binary.BigEndian.Uint32(p))
+	}
+	return payload, nil
+}
+
+// sqliteVarint returns the value and its length, which is zero if b ends in the middle of the varint.
+func sqliteVarint(b []byte) (int64, int) {
+	var v uint64
+	for i := 0; i < 9 && i < len(b); i++ {
+		if i == 8 {
+			return int64(v<<8 | uint64(b[i])), 9
+		}
+		v = v<<7 | uint64(b[i]&0x7f)
+		if b[i]&0x80 == 0 {
+			return int64(v), i + 1
+		}
+	}
+	return 0, 0
+}
+
+func sqliteRecord(b []byte) ([]interface{}, error) {
+	hdrSize, n := sqliteVarint(b)
+	if n == 0 || hdrSize < int64(n) || hdrSize > int64(len(b)) {
+		return nil, errors.New("invalid record header")
+	}
+	types := make([]int64, 0)
+	for pos := n; pos < int(hdrSize); {
+		t, m := sqliteVarint(b[pos:hdrSize])
+		if m == 0 {
+			return nil, errors.New("invalid record header")
+		}
+		types = append(types, t)
+		pos += m
+	}
+	body := b[hdrSize:]
+	values := make([]interface{}, len(types))
+	for i, t := range types {
+		var size int64
+		switch {
+		case t < 0 || t == 10 || t == 11:
+			return nil, fmt.Errorf("invalid serial type: %d", t)
+		case t >= 1 && t <= 4:
+			size = t
+		case t == 5:
+			size = 6
+		case t == 6 || t == 7:
+			size = 8
+		case t >= 12:
+			size = (t - 12) / 2
+		}
+		if size > int64(len(body)) {
+			return nil, errors.New("invalid record")
+		}
+		v := body[:size]
+		body = body[size:]
+		switch {
+		case t == 0:
+			values[i] = nil
+		case t >= 1 && t <= 6:
+			n := int64(int8(v[0]))
+			for _, c := range v[1:] {
+				n = n<<8 | int64(c)
+			}
+			values[i] = n
+		case t == 7:
+			values[i] = math.Float64frombits(binary.BigEndian.Uint64(v))
+		case t == 8:
+			values[i] = int64(0)
+		case t == 9:
+			values[i] = int64(1)
+		case t >= 12 && t%2 == 0:
+			values[i] = v
+		case t >= 13:
+			values[i] = string(v)
+		}
+	}
+	return values, nil
+}
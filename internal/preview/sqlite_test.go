@@ -0,0 +1,172 @@
+package preview
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func readSQLiteFixture(t *testing.T) []byte {
+	t.Helper()
+	// 512 bytes pages so that events has interior pages and notes has overflow pages
+	data, err := ioutil.ReadFile("testdata/sample.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestRenderSQLite(t *testing.T) {
+	got := renderSQLite(readSQLiteFixture(t))
+	for _, want := range []string{
+		"users", "(3 rows)",
+		"1 | alice | 1.5 | <blob 2 bytes>",
+		"2 | bob | NULL | NULL",
+		"3 | carol | -2 | <blob 0 bytes>",
+		"events", "(500 rows)", "10 | event 10",
+		"notes", "(1 rows)", strings.Repeat("x", sqliteMaxValueWidth) + "...",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("renderSQLite() does not contain %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestSQLiteTablesRows(t *testing.T) {
+	f, err := openSQLite(readSQLiteFixture(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables, err := f.tables()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"users": 3, "events": 500, "notes": 1}
+	if len(tables) != len(want) {
+		t.Fatalf("%d tables, want %d", len(tables), len(want))
+	}
+	for _, table := range tables {
+		var last []interface{}
+		count := 0
+		if err := f.walk(table.rootPage, func(_ int64, values []interface{}) {
+			last = values
+			count++
+		}); err != nil {
+			t.Errorf("%s: %v", table.name, err)
+		}
+		if count != want[table.name] {
+			t.Errorf("%s has %d rows, want %d", table.name, count, want[table.name])
+		}
+		// the payload of notes is read from the overflow pages
+		if table.name == "notes" && (len(last) != 1 || last[0] != strings.Repeat("x", 2000)+"end") {
+			t.Errorf("notes = %.20q", last)
+		}
+	}
+}
+
+func TestSQLiteMalformed(t *testing.T) {
+	fixture := readSQLiteFixture(t)
+	f, err := openSQLite(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables, err := f.tables()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events int
+	for _, table := range tables {
+		if table.name == "events" {
+			events = table.rootPage
+		}
+	}
+	eventsPage := (events - 1) * 512
+
+	tests := []struct {
+		name   string
+		modify func(b []byte) []byte
+	}{
+		{"empty", func(b []byte) []byte { return nil }},
+		{"header only", func(b []byte) []byte { return b[:100] }},
+		{"truncated", func(b []byte) []byte { return b[:len(b)/2] }},
+		{"page size", func(b []byte) []byte { b[16], b[17] = 0x03, 0x00; return b }},
+		{"reserved space", func(b []byte) []byte { b[20] = 0xff; return b }},
+		{"cell count", func(b []byte) []byte { binary.BigEndian.PutUint16(b[103:], 0xffff); return b }},
+		{"cell pointer", func(b []byte) []byte { binary.BigEndian.PutUint16(b[108:], 0x01ff); return b }},
+		{"cyclic right pointer", func(b []byte) []byte {
+			binary.BigEndian.PutUint32(b[eventsPage+8:], uint32(events))
+			return b
+		}},
+		{"cyclic child pointer", func(b []byte) []byte {
+			off := binary.BigEndian.Uint16(b[eventsPage+12:])
+			binary.BigEndian.PutUint32(b[eventsPage+int(off):], uint32(events))
+			return b
+		}},
+	}
+	for _, tt := range tests {
+		b := tt.modify(append([]byte(nil), fixture...))
+		if _, err := sqliteMode.Render(b); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+	}
+}
+
+func TestSQLiteRecordMalformed(t *testing.T) {
+	tests := []struct {
+		name   string
+		record []byte
+	}{
+		{"empty", nil},
+		{"header size larger than record", []byte{0x05, 0x01}},
+		{"header size smaller than itself", []byte{0x00}},
+		{"truncated varint", []byte{0x02, 0x81}},
+		{"negative serial type", []byte{0x0a, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{"reserved serial type", []byte{0x02, 0x0a}},
+		{"value larger than record", []byte{0x02, 0x21, 'a'}},
+	}
+	for _, tt := range tests {
+		if _, err := sqliteRecord(tt.record); err == nil {
+			t.Errorf("%s: sqliteRecord() returned no error", tt.name)
+		}
+	}
+}
+
+// Every single corrupted byte must be reported in the preview instead of panicking.
+func TestSQLiteCorruptedBytes(t *testing.T) {
+	fixture := readSQLiteFixture(t)
+	for i := 0; i < len(fixture); i++ {
+		for _, c := range []byte{0x00, 0xff} {
+			b := append([]byte(nil), fixture...)
+			b[i] = c
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						t.Fatalf("byte %d set to %#x: %v", i, c, r)
+					}
+				}()
+				renderSQLite(b)
+			}()
+		}
+	}
+}
+
+func TestSQLiteRowidAlias(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want int
+	}{
+		{"CREATE TABLE t (id INTEGER PRIMARY KEY, name TEXT)", 0},
+		{"CREATE TABLE t (name TEXT, id integer not null primary key autoincrement)", 1},
+		{"CREATE TABLE t (price DECIMAL(10, 2), id INTEGER PRIMARY KEY)", 1},
+		{"CREATE TABLE t (id INT PRIMARY KEY, name TEXT)", -1},
+		{"CREATE TABLE t (id INTEGER, name TEXT)", -1},
+		{"CREATE TABLE t (body TEXT)", -1},
+		{"", -1},
+	}
+	for _, tt := range tests {
+		if got := sqliteRowidAlias(tt.sql); got != tt.want {
+			t.Errorf("sqliteRowidAlias(%q) = %d, want %d", tt.sql, got, tt.want)
+		}
+	}
+}
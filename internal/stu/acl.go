@@ -0,0 +1,22 @@
+package stu
+
+import (
+	"context"
+	"errors"
+)
+
+// canned ACLs which can be set on objects from the UI
+const (
+	ACLPrivate    = "private"
+	ACLPublicRead = "public-read"
+)
+
+var (
+	ErrACLsDisabled     = errors.New("ACLs are disabled by the object ownership setting of the bucket")
+	ErrACLsNotSupported = errors.New("ACLs are not supported by the client")
+)
+
+// ACLSetter is implemented by clients which can set canned ACLs of objects.
+type ACLSetter interface {
+	PutObjectACL(ctx context.Context, bucket, key, acl string) error
+}
@@ -0,0 +1,92 @@
+package stu
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"time"
+)
+
+const (
+	s3EventSource = "s3.amazonaws.com"
+)
+
+var ErrActivityNotConfigured = errors.New("CloudTrail trail is not configured")
+
+// ObjectEvent is a CloudTrail data event of an object.
+type ObjectEvent struct {
+	Time time.Time
+	// e.g. GetObject, PutObject or DeleteObject
+	Name string
+	// ARN of the caller, or the principal ID and the type if the caller has no ARN
+	Principal string
+	SourceIP  string
+	UserAgent string
+	// empty if the request succeeded
+	ErrorCode string
+}
+
+// ObjectActivityReader is implemented by clients which can read the recent events of objects.
+type ObjectActivityReader interface {
+	// ObjectEvents returns the events of the object, latest first.
+	ObjectEvents(ctx context.Context, bucket, key string) ([]*ObjectEvent, error)
+}
+
+type trailLog struct {
+	Records []*trailRecord
+}
+
+type trailRecord struct {
+	EventTime       time.Time
+	EventName       string
+	EventSource     string
+	SourceIPAddress string
+	UserAgent       string
+	ErrorCode       string
+	UserIdentity    struct {
+		Type        string
+		PrincipalID string
+		ARN         string
+	}
+	RequestParameters struct {
+		BucketName string
+		Key        string
+	}
+}
+
+// ParseTrailEvents returns the events of the object in a CloudTrail log file (decompressed).
+// Objects deleted by DeleteObjects are not included, as the keys are not in the event.
+func ParseTrailEvents(r io.Reader, bucket, key string) ([]*ObjectEvent, error) {
+	var log trailLog
+	if err := json.NewDecoder(r).Decode(&log); err != nil {
+		return nil, err
+	}
+	events := make([]*ObjectEvent, 0)
+	for _, rec := range log.Records {
+		if rec.EventSource != s3EventSource || rec.RequestParameters.BucketName != bucket || rec.RequestParameters.Key != key {
+			continue
+		}
+		principal := rec.UserIdentity.ARN
+		if principal == "" {
+			principal = rec.UserIdentity.PrincipalID + " (" + rec.UserIdentity.Type + ")"
+		}
+		events = append(events, &ObjectEvent{
+			Time:      rec.EventTime,
+			Name:      rec.EventName,
+			Principal: principal,
+			SourceIP:  rec.SourceIPAddress,
+			UserAgent: rec.UserAgent,
+			ErrorCode: rec.ErrorCode,
+		})
+	}
+	return events, nil
+}
+
+// SortObjectEvents sorts the events latest first.
+func SortObjectEvents(events []*ObjectEvent) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.After(events[j].Time)
+	})
+}
@@ -0,0 +1,68 @@
+package stu
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// AuditLog appends the changes made to objects to a file as JSON lines.
+// A nil AuditLog records nothing.
+type AuditLog struct {
+	path string
+	mu   sync.Mutex
+}
+
+type auditEntry struct {
+	Time   time.Time `json:"time"`
+	Action string    `json:"action"`
+	Bucket string    `json:"bucket"`
+	Key    string    `json:"key"`
+	Detail string    `json:"detail,omitempty"`
+	Error  string    `json:"error,omitempty"`
+}
+
+func NewAuditLog(path string) *AuditLog {
+	if path == "" {
+		return nil
+	}
+	return &AuditLog{path: path}
+}
+
+// Record appends the action and its result, failed actions are recorded too.
+func (l *AuditLog) Record(action, bucket, key, detail string, actionErr error) error {
+	if l == nil {
+		return nil
+	}
+	e := &auditEntry{
+		Time:   time.Now(),
+		Action: action,
+		Bucket: bucket,
+		Key:    key,
+		Detail: detail,
+	}
+	if actionErr != nil {
+		e.Error = actionErr.Error()
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
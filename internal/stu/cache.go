@@ -0,0 +1,162 @@
+package stu
+
+import (
+	"container/list"
+	"sync"
+)
+
+const (
+	maxCachedPreviewSize = 64 * 1024
+)
+
+// ObjectCache keeps ObjectHead and previews of recently used objects.
+// Entries are keyed by bucket and key, and are valid only while the ETag of the object is unchanged.
+// A nil *ObjectCache caches nothing.
+type ObjectCache struct {
+	mu      sync.Mutex
+	size    int
+	ll      *list.List
+	entries map[string]*list.Element
+}
+
+type objectCacheEntry struct {
+	key       string
+	etag      string
+	head      *ObjectHead
+	preview   []byte
+	truncated bool
+}
+
+func NewObjectCache(size int) *ObjectCache {
+	if size <= 0 {
+		return nil
+	}
+	return &ObjectCache{
+		size:    size,
+		ll:      list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (*ObjectCache) cacheKey(bucket string, item *ObjectItem) string {
+	return bucket + "/" + item.ObjectKey()
+}
+
+// get returns the entry of the item if its ETag matches, and drops it otherwise.
+func (c *ObjectCache) get(bucket string, item *ObjectItem) *objectCacheEntry {
+	e, ok := c.entries[c.cacheKey(bucket, item)]
+	if !ok {
+		return nil
+	}
+	entry := e.Value.(*objectCacheEntry)
+	if entry.etag != item.ETag {
+		c.remove(e)
+		return nil
+	}
+	c.ll.MoveToFront(e)
+	return entry
+}
+
+func (c *ObjectCache) getOrCreate(bucket string, item *ObjectItem) *objectCacheEntry {
+	if entry := c.get(bucket, item); entry != nil {
+		return entry
+	}
+	entry := &objectCacheEntry{key: c.cacheKey(bucket, item), etag: item.ETag}
+	c.entries[entry.key] = c.ll.PushFront(entry)
+	for c.ll.Len() > c.size {
+		c.remove(c.ll.Back())
+	}
+	return entry
+}
+
+func (c *ObjectCache) remove(e *list.Element) {
+	c.ll.Remove(e)
+	delete(c.entries, e.Value.(*objectCacheEntry).key)
+}
+
+func (c *ObjectCache) Head(bucket string, item *ObjectItem) (*ObjectHead, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := c.get(bucket, item)
+	if entry == nil || entry.head == nil {
+		return nil, false
+	}
+	return entry.head, true
+}
+
+func (c *ObjectCache) PutHead(bucket string, item *ObjectItem, head *ObjectHead) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.getOrCreate(bucket, item).head = head
+}
+
+func (c *ObjectCache) Preview(bucket string, item *ObjectItem) (data []byte, truncated bool, ok bool) {
+	if c == nil {
+		return nil, false, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := c.get(bucket, item)
+	if entry == nil || entry.preview == nil {
+		return nil, false, false
+	}
+	return entry.preview, entry.truncated, true
+}
+
+// PutPreview caches the preview only if it is small, large previews are read again.
+func (c *ObjectCache) PutPreview(bucket string, item *ObjectItem, data []byte, truncated bool) {
+	if c == nil || len(data) > maxCachedPreviewSize {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := c.getOrCreate(bucket, item)
+	entry.preview = data
+	entry.truncated = truncated
+}
+
+// PurgePreviews drops all cached previews and keeps the heads.
+func (c *ObjectCache) PurgePreviews() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, e := range c.entries {
+		entry := e.Value.(*objectCacheEntry)
+		entry.preview = nil
+		entry.truncated = false
+	}
+}
+
+// Remove drops the entry of the object, e.g. after it is deleted.
+func (c *ObjectCache) Remove(bucket string, item *ObjectItem) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[c.cacheKey(bucket, item)]; ok {
+		c.remove(e)
+	}
+}
+
+// Observe drops the entries of listed objects whose ETag has changed.
+func (c *ObjectCache) Observe(bucket string, items []*ObjectItem) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, item := range items {
+		if !item.Dir {
+			c.get(bucket, item)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package stu
+
+import (
+	"testing"
+	"time"
+)
+
+func newCacheTestItem(key, etag string) *ObjectItem {
+	item := NewFileObjectItem(key, 1, time.Time{})
+	item.ETag = etag
+	return item
+}
+
+func TestNewObjectCache(t *testing.T) {
+	for _, size := range []int{0, -1} {
+		if c := NewObjectCache(size); c != nil {
+			t.Errorf("NewObjectCache(%d) = %+v, want nil", size, c)
+		}
+	}
+	// a nil cache caches nothing
+	var c *ObjectCache
+	item := newCacheTestItem("a", "1")
+	c.PutHead("bucket", item, &ObjectHead{})
+	c.PutPreview("bucket", item, []byte("a"), false)
+	if _, ok := c.Head("bucket", item); ok {
+		t.Error("nil cache returned a head")
+	}
+	if _, _, ok := c.Preview("bucket", item); ok {
+		t.Error("nil cache returned a preview")
+	}
+}
+
+func TestObjectCacheETag(t *testing.T) {
+	tests := []struct {
+		name string
+		// called between caching the item with ETag "1" and getting it
+		op   func(c *ObjectCache)
+		get  *ObjectItem
+		want bool
+	}{
+		{"same etag", func(c *ObjectCache) {}, newCacheTestItem("a", "1"), true},
+		{"changed etag", func(c *ObjectCache) {}, newCacheTestItem("a", "2"), false},
+		{"other bucket", func(c *ObjectCache) {}, newCacheTestItem("a", "1"), true},
+		{"observed same etag", func(c *ObjectCache) {
+			c.Observe("bucket", []*ObjectItem{newCacheTestItem("a", "1")})
+		}, newCacheTestItem("a", "1"), true},
+		{"observed changed etag", func(c *ObjectCache) {
+			c.Observe("bucket", []*ObjectItem{newCacheTestItem("a", "2")})
+		}, newCacheTestItem("a", "1"), false},
+		{"removed", func(c *ObjectCache) {
+			c.Remove("bucket", newCacheTestItem("a", "1"))
+		}, newCacheTestItem("a", "1"), false},
+	}
+	for _, tt := range tests {
+		c := NewObjectCache(10)
+		head := &ObjectHead{}
+		c.PutHead("bucket", newCacheTestItem("a", "1"), head)
+		c.PutPreview("bucket", newCacheTestItem("a", "1"), []byte("data"), true)
+		tt.op(c)
+		got, ok := c.Head("bucket", tt.get)
+		if ok != tt.want || (ok && got != head) {
+			t.Errorf("%s: Head() = %v, %v, want %v", tt.name, got, ok, tt.want)
+		}
+		data, truncated, ok := c.Preview("bucket", tt.get)
+		if ok != tt.want || (ok && (string(data) != "data" || !truncated)) {
+			t.Errorf("%s: Preview() = %q, %v, %v, want %v", tt.name, data, truncated, ok, tt.want)
+		}
+	}
+	c := NewObjectCache(10)
+	c.PutHead("bucket", newCacheTestItem("a", "1"), &ObjectHead{})
+	if _, ok := c.Head("other", newCacheTestItem("a", "1")); ok {
+		t.Error("Head() returned the entry of another bucket")
+	}
+}
+
+func TestObjectCacheEviction(t *testing.T) {
+	tests := []struct {
+		name string
+		// keys put or got in order, the cache keeps 3 entries
+		keys []string
+		want []string
+	}{
+		{"under size", []string{"a", "b"}, []string{"a", "b"}},
+		{"oldest evicted", []string{"a", "b", "c", "d"}, []string{"b", "c", "d"}},
+		{"recently used kept", []string{"a", "b", "c", "a", "d"}, []string{"a", "c", "d"}},
+		{"all replaced", []string{"a", "b", "c", "d", "e", "f"}, []string{"d", "e", "f"}},
+	}
+	for _, tt := range tests {
+		c := NewObjectCache(3)
+		for _, k := range tt.keys {
+			item := newCacheTestItem(k, "1")
+			if _, ok := c.Head("bucket", item); !ok {
+				c.PutHead("bucket", item, &ObjectHead{})
+			}
+		}
+		want := make(map[string]bool)
+		for _, k := range tt.want {
+			want[k] = true
+		}
+		for _, k := range []string{"a", "b", "c", "d", "e", "f"} {
+			if _, ok := c.Head("bucket", newCacheTestItem(k, "1")); ok != want[k] {
+				t.Errorf("%s: %s cached = %v, want %v", tt.name, k, ok, want[k])
+			}
+		}
+	}
+}
+
+func TestObjectCachePreviews(t *testing.T) {
+	c := NewObjectCache(10)
+	item := newCacheTestItem("a", "1")
+	c.PutHead("bucket", item, &ObjectHead{})
+	c.PutPreview("bucket", item, make([]byte, maxCachedPreviewSize+1), false)
+	if _, _, ok := c.Preview("bucket", item); ok {
+		t.Error("large preview is cached")
+	}
+	c.PutPreview("bucket", item, []byte("data"), false)
+	c.PurgePreviews()
+	if _, _, ok := c.Preview("bucket", item); ok {
+		t.Error("preview is cached after PurgePreviews")
+	}
+	if _, ok := c.Head("bucket", item); !ok {
+		t.Error("head is dropped by PurgePreviews")
+	}
+}
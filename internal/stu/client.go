@@ -1,27 +1,56 @@
 package stu
 
-import "strings"
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
 
 const (
 	delimiter = "/"
 )
 
+// ErrOffline is returned by clients which serve only cached listings.
+var ErrOffline = errors.New("not available offline")
+
 type Client interface {
 	ListObjects(bucket, prefix string) ([]*ObjectItem, error)
 	ListBuckets() ([]*BucketItem, error)
+	WalkObjects(ctx context.Context, bucket, prefix string, fn func(*ObjectItem) error) error
+	GetObjectTags(ctx context.Context, bucket, key string) (map[string]string, error)
+	GetObjectMetadata(ctx context.Context, bucket, key string) (map[string]string, error)
+	HeadObject(ctx context.Context, bucket, key string) (*ObjectHead, error)
+	GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error)
+	// PresignGetObject returns a URL which can download the object without credentials until it expires.
+	PresignGetObject(ctx context.Context, bucket, key string, expires time.Duration) (string, error)
+	// GetObjectRange returns length bytes of the object from offset.
+	GetObjectRange(ctx context.Context, bucket, key string, offset, length int64) (io.ReadCloser, error)
+	// TouchObject copies the object onto itself to update its last modified time.
+	TouchObject(ctx context.Context, bucket, key string) error
+	// size is the size of the source object, large objects are copied in parts
+	CopyObject(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string, size int64) error
+	DeleteObject(ctx context.Context, bucket, key string) error
 }
 
 type ObjectItem struct {
-	Dir   bool
-	name  string
-	paths []string
+	Dir          bool
+	Size         int64
+	LastModified time.Time
+	ETag         string
+	Head         *ObjectHead
+	name         string
+	paths        []string
 }
 
-func NewFileObjectItem(key string) *ObjectItem {
+func NewFileObjectItem(key string, size int64, lastModified time.Time) *ObjectItem {
 	return &ObjectItem{
-		Dir:   false,
-		name:  key,
-		paths: parseObjectKey(key, false),
+		Dir:          false,
+		Size:         size,
+		LastModified: lastModified,
+		name:         key,
+		paths:        parseObjectKey(key, false),
 	}
 }
 
@@ -43,6 +72,9 @@ func parseObjectKey(key string, dir bool) []string {
 }
 
 func (i *ObjectItem) Text() string {
+	if i.FolderMarker() {
+		return "." + delimiter
+	}
 	name := i.Filename()
 	if i.Dir {
 		name += delimiter
@@ -62,8 +94,15 @@ func (i *ObjectItem) Filename() string {
 	return i.paths[len(i.paths)-1]
 }
 
+// FolderMarker reports whether the object is a zero-byte key ending with the delimiter,
+// which is created by the console to represent an empty folder.
+func (i *ObjectItem) FolderMarker() bool {
+	return !i.Dir && i.Size == 0 && strings.HasSuffix(i.name, delimiter)
+}
+
 type BucketItem struct {
-	name string
+	Metrics *BucketMetrics
+	name    string
 }
 
 func NewBucketItem(name string) *BucketItem {
@@ -0,0 +1,110 @@
+package stu
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	copyRetryInterval = time.Second
+)
+
+type CopyFailure struct {
+	Key string
+	Err error
+}
+
+type CopyResult struct {
+	Copied int
+	Bytes  int64
+	Failed []*CopyFailure
+}
+
+func (r *CopyResult) Progress() Progress {
+	return Progress{
+		Items:  r.Copied + len(r.Failed),
+		Failed: len(r.Failed),
+		Bytes:  r.Bytes,
+	}
+}
+
+// CopyPrefix copies all objects under srcPrefix of srcBucket to dstPrefix of dstBucket on the server side
+// with at most concurrency requests in flight.
+// Each object is retried up to retries times, objects which still fail are reported in the result
+// instead of stopping the copy. progress is called after each object.
+func CopyPrefix(ctx context.Context, client Client, srcBucket, srcPrefix, dstBucket, dstPrefix string, concurrency, retries int, progress ProgressFunc) (*CopyResult, error) {
+	return copyObjects(ctx, client, srcBucket, srcPrefix, walkPrefix(client, srcBucket, srcPrefix), dstBucket, dstPrefix, concurrency, retries, progress)
+}
+
+// CopyItems copies the given objects under srcPrefix in the same way as CopyPrefix.
+func CopyItems(ctx context.Context, client Client, srcBucket, srcPrefix string, items []*ObjectItem, dstBucket, dstPrefix string, concurrency, retries int, progress ProgressFunc) (*CopyResult, error) {
+	return copyObjects(ctx, client, srcBucket, srcPrefix, walkItems(items), dstBucket, dstPrefix, concurrency, retries, progress)
+}
+
+func copyObjects(ctx context.Context, client Client, srcBucket, srcPrefix string, walk walkFunc, dstBucket, dstPrefix string, concurrency, retries int, progress ProgressFunc) (*CopyResult, error) {
+	result := &CopyResult{Failed: make([]*CopyFailure, 0)}
+	var mu sync.Mutex
+
+	items := make(chan *ObjectItem)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range items {
+				key := item.ObjectKey()
+				dstKey := dstPrefix + strings.TrimPrefix(key, srcPrefix)
+				err := copyWithRetry(ctx, client, srcBucket, key, dstBucket, dstKey, item.Size, retries)
+				if ctx.Err() != nil {
+					return
+				}
+				mu.Lock()
+				if err != nil {
+					result.Failed = append(result.Failed, &CopyFailure{Key: key, Err: err})
+				} else {
+					result.Copied++
+					result.Bytes += item.Size
+				}
+				p := result.Progress()
+				mu.Unlock()
+				p.Key = key
+				progress.report(p)
+			}
+		}()
+	}
+
+	walkErr := walk(ctx, func(item *ObjectItem) error {
+		select {
+		case items <- item:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	close(items)
+	wg.Wait()
+
+	if walkErr != nil {
+		return result, walkErr
+	}
+	return result, ctx.Err()
+}
+
+func copyWithRetry(ctx context.Context, client Client, srcBucket, srcKey, dstBucket, dstKey string, size int64, retries int) error {
+	var err error
+	for i := 0; i <= retries; i++ {
+		if i > 0 {
+			select {
+			case <-time.After(time.Duration(i) * copyRetryInterval):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if err = client.CopyObject(ctx, srcBucket, srcKey, dstBucket, dstKey, size); err == nil {
+			return nil
+		}
+	}
+	return err
+}
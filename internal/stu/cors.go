@@ -0,0 +1,19 @@
+package stu
+
+import "context"
+
+type CORSRule struct {
+	ID             string
+	AllowedOrigins []string
+	AllowedMethods []string
+	AllowedHeaders []string
+	ExposeHeaders  []string
+	// 0 if not set
+	MaxAgeSeconds int32
+}
+
+// BucketCORSReader is implemented by clients which can read CORS configurations of buckets.
+type BucketCORSReader interface {
+	// GetBucketCORS returns no rules if the bucket has no CORS configuration.
+	GetBucketCORS(ctx context.Context, bucket string) ([]*CORSRule, error)
+}
@@ -0,0 +1,86 @@
+package stu
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrCredentialsNotSupported = errors.New("temporary credentials are not supported by the client")
+
+type TemporaryCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Expiration      time.Time
+}
+
+// CredentialsRequest describes temporary credentials limited to the objects under Prefix of Bucket.
+type CredentialsRequest struct {
+	Bucket string
+	Prefix string
+	// allow put and delete in addition to list and get
+	Write    bool
+	Duration time.Duration
+	// the role is assumed with the scoped policy, GetFederationToken is used if empty
+	RoleARN string
+}
+
+// CredentialsIssuer is implemented by clients which can issue temporary credentials.
+type CredentialsIssuer interface {
+	IssueCredentials(ctx context.Context, req *CredentialsRequest) (*TemporaryCredentials, error)
+}
+
+func (r *CredentialsRequest) Access() string {
+	if r.Write {
+		return "read-write"
+	}
+	return "read-only"
+}
+
+// ScopedPolicy returns the IAM policy which allows only the access of the request.
+func ScopedPolicy(req *CredentialsRequest) (string, error) {
+	bucketARN := "arn:aws:s3:::" + req.Bucket
+	actions := []string{"s3:GetObject"}
+	if req.Write {
+		actions = append(actions, "s3:PutObject", "s3:DeleteObject", "s3:AbortMultipartUpload")
+	}
+	listing := map[string]interface{}{
+		"Effect":   "Allow",
+		"Action":   "s3:ListBucket",
+		"Resource": bucketARN,
+	}
+	if req.Prefix != "" {
+		listing["Condition"] = map[string]interface{}{
+			"StringLike": map[string]string{"s3:prefix": req.Prefix + "*"},
+		}
+	}
+	policy := map[string]interface{}{
+		"Version": "2012-10-17",
+		"Statement": []interface{}{
+			listing,
+			map[string]interface{}{
+				"Effect":   "Allow",
+				"Action":   actions,
+				"Resource": bucketARN + "/" + req.Prefix + "*",
+			},
+		},
+	}
+	b, err := json.Marshal(policy)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// ExportCommands returns the shell commands which set the credentials to the environment variables.
+func ExportCommands(c *TemporaryCredentials) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "export AWS_ACCESS_KEY_ID=%s\n", c.AccessKeyID)
+	fmt.Fprintf(&b, "export AWS_SECRET_ACCESS_KEY=%s\n", c.SecretAccessKey)
+	fmt.Fprintf(&b, "export AWS_SESSION_TOKEN=%s\n", c.SessionToken)
+	return b.String()
+}
@@ -0,0 +1,105 @@
+package stu
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	dateLayout = "2006-01-02"
+)
+
+type DateRange struct {
+	// zero value means unbounded
+	From time.Time
+	To   time.Time
+	text string
+}
+
+// ParseDateRange parses expressions like "older than 90 days", "newer than 2w",
+// "from 2021-01-01 to 2021-12-31", "from 2021-01-01" or "to 2021-12-31".
+// The to date is inclusive.
+func ParseDateRange(s string, now time.Time) (*DateRange, error) {
+	text := strings.Join(strings.Fields(strings.ToLower(s)), " ")
+	r := &DateRange{text: text}
+	switch {
+	case strings.HasPrefix(text, "older than "):
+		d, err := parseAge(strings.TrimPrefix(text, "older than "))
+		if err != nil {
+			return nil, err
+		}
+		r.To = now.Add(-d)
+	case strings.HasPrefix(text, "newer than "):
+		d, err := parseAge(strings.TrimPrefix(text, "newer than "))
+		if err != nil {
+			return nil, err
+		}
+		r.From = now.Add(-d)
+	case strings.HasPrefix(text, "from "), strings.HasPrefix(text, "to "):
+		from, to := text, ""
+		if i := strings.Index(text, "to "); i >= 0 {
+			from, to = text[:i], text[i+len("to "):]
+		}
+		from = strings.TrimSpace(strings.TrimPrefix(from, "from"))
+		if from != "" {
+			t, err := time.ParseInLocation(dateLayout, from, now.Location())
+			if err != nil {
+				return nil, fmt.Errorf("invalid date: %s", from)
+			}
+			r.From = t
+		}
+		if to != "" {
+			t, err := time.ParseInLocation(dateLayout, strings.TrimSpace(to), now.Location())
+			if err != nil {
+				return nil, fmt.Errorf("invalid date: %s", to)
+			}
+			r.To = t.AddDate(0, 0, 1)
+		}
+		if !r.From.IsZero() && !r.To.IsZero() && !r.From.Before(r.To) {
+			return nil, errors.New("from date must not be after to date")
+		}
+	default:
+		return nil, errors.New(`expected "older than N days", "newer than N days" or "from YYYY-MM-DD to YYYY-MM-DD"`)
+	}
+	return r, nil
+}
+
+func parseAge(s string) (time.Duration, error) {
+	i := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+	if i <= 0 {
+		return 0, fmt.Errorf("invalid age: %s", s)
+	}
+	n, err := strconv.Atoi(s[:i])
+	if err != nil {
+		return 0, fmt.Errorf("invalid age: %s", s)
+	}
+	var unit time.Duration
+	switch strings.TrimSpace(s[i:]) {
+	case "h", "hour", "hours":
+		unit = time.Hour
+	case "d", "day", "days":
+		unit = 24 * time.Hour
+	case "w", "week", "weeks":
+		unit = 7 * 24 * time.Hour
+	default:
+		return 0, fmt.Errorf("invalid age unit: %s", s[i:])
+	}
+	return time.Duration(n) * unit, nil
+}
+
+func (r *DateRange) Contains(t time.Time) bool {
+	if !r.From.IsZero() && t.Before(r.From) {
+		return false
+	}
+	if !r.To.IsZero() && !t.Before(r.To) {
+		return false
+	}
+	return true
+}
+
+func (r *DateRange) String() string {
+	return r.text
+}
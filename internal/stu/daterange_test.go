@@ -0,0 +1,75 @@
+package stu
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDateRange(t *testing.T) {
+	now := time.Date(2021, 12, 15, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		s       string
+		from    time.Time
+		to      time.Time
+		wantErr bool
+	}{
+		{"older than 90 days", time.Time{}, now.Add(-90 * day), false},
+		{"Older  Than 1 day", time.Time{}, now.Add(-day), false},
+		{"older than 12h", time.Time{}, now.Add(-12 * time.Hour), false},
+		{"newer than 2w", now.Add(-14 * day), time.Time{}, false},
+		{"newer than 3 weeks", now.Add(-21 * day), time.Time{}, false},
+		{"from 2021-01-01 to 2021-12-31", date(2021, 1, 1), date(2022, 1, 1), false},
+		{"from 2021-01-01", date(2021, 1, 1), time.Time{}, false},
+		{"to 2021-12-31", time.Time{}, date(2022, 1, 1), false},
+		{"from 2021-12-31 to 2021-12-31", date(2021, 12, 31), date(2022, 1, 1), false},
+		{"from 2022-01-01 to 2021-12-31", time.Time{}, time.Time{}, true},
+		{"from 2021-13-01", time.Time{}, time.Time{}, true},
+		{"to yesterday", time.Time{}, time.Time{}, true},
+		{"older than days", time.Time{}, time.Time{}, true},
+		{"older than 3 months", time.Time{}, time.Time{}, true},
+		{"last week", time.Time{}, time.Time{}, true},
+		{"", time.Time{}, time.Time{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDateRange(tt.s, now)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseDateRange(%q) = %+v, want error", tt.s, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseDateRange(%q) returned error: %v", tt.s, err)
+			continue
+		}
+		if !got.From.Equal(tt.from) || !got.To.Equal(tt.to) {
+			t.Errorf("ParseDateRange(%q) = [%v, %v), want [%v, %v)", tt.s, got.From, got.To, tt.from, tt.to)
+		}
+	}
+}
+
+func TestDateRangeContains(t *testing.T) {
+	now := time.Date(2021, 12, 15, 12, 0, 0, 0, time.UTC)
+	r, err := ParseDateRange("from 2021-01-01 to 2021-12-31", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		t    time.Time
+		want bool
+	}{
+		{time.Date(2020, 12, 31, 23, 59, 59, 0, time.UTC), false},
+		{time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), true},
+		{time.Date(2021, 12, 31, 23, 59, 59, 0, time.UTC), true},
+		{time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), false},
+	}
+	for _, tt := range tests {
+		if got := r.Contains(tt.t); got != tt.want {
+			t.Errorf("Contains(%v) = %v, want %v", tt.t, got, tt.want)
+		}
+	}
+}
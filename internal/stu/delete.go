@@ -0,0 +1,122 @@
+package stu
+
+import (
+	"context"
+	"errors"
+)
+
+const (
+	// max keys of a DeleteObjects request
+	maxDeleteBatch = 1000
+)
+
+var ErrBatchDeleteNotSupported = errors.New("deleting objects in batches is not supported by the client")
+
+type DeleteFailure struct {
+	Key string
+	Err error
+}
+
+type DeleteResult struct {
+	Deleted int
+	Bytes   int64
+	Failed  []*DeleteFailure
+}
+
+func (r *DeleteResult) Progress() Progress {
+	return Progress{
+		Items:  r.Deleted + len(r.Failed),
+		Failed: len(r.Failed),
+		Bytes:  r.Bytes,
+	}
+}
+
+// BatchDeleter is implemented by clients which can delete several objects in a request.
+type BatchDeleter interface {
+	// DeleteObjects deletes up to 1000 objects and returns the objects which could not be deleted.
+	DeleteObjects(ctx context.Context, bucket string, keys []string) ([]*DeleteFailure, error)
+}
+
+// DeletePrefix deletes all objects under prefix in batches of up to 1000 objects,
+// or one by one if the client cannot delete in batches.
+// Objects which fail are reported in the result, errors of whole requests stop the deletion.
+// progress is called after each batch.
+func DeletePrefix(ctx context.Context, client Client, bucket, prefix string, progress ProgressFunc) (*DeleteResult, error) {
+	return deleteObjects(ctx, client, bucket, walkPrefix(client, bucket, prefix), progress)
+}
+
+// DeleteItems deletes the given objects in the same way as DeletePrefix.
+func DeleteItems(ctx context.Context, client Client, bucket string, items []*ObjectItem, progress ProgressFunc) (*DeleteResult, error) {
+	return deleteObjects(ctx, client, bucket, walkItems(items), progress)
+}
+
+func deleteObjects(ctx context.Context, client Client, bucket string, walk walkFunc, progress ProgressFunc) (*DeleteResult, error) {
+	result := &DeleteResult{Failed: make([]*DeleteFailure, 0)}
+	batch := make([]*ObjectItem, 0, maxDeleteBatch)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := deleteBatch(ctx, client, bucket, batch, result); err != nil {
+			return err
+		}
+		p := result.Progress()
+		p.Key = batch[len(batch)-1].ObjectKey()
+		progress.report(p)
+		batch = batch[:0]
+		return nil
+	}
+	err := walk(ctx, func(item *ObjectItem) error {
+		batch = append(batch, item)
+		if len(batch) < maxDeleteBatch {
+			return nil
+		}
+		return flush()
+	})
+	if err == nil {
+		err = flush()
+	}
+	return result, err
+}
+
+func deleteBatch(ctx context.Context, client Client, bucket string, items []*ObjectItem, result *DeleteResult) error {
+	failed := make(map[string]error)
+	if d, ok := client.(BatchDeleter); ok {
+		keys := make([]string, len(items))
+		for i, item := range items {
+			keys[i] = item.ObjectKey()
+		}
+		failures, err := d.DeleteObjects(ctx, bucket, keys)
+		switch {
+		case err == nil:
+			for _, f := range failures {
+				failed[f.Key] = f.Err
+			}
+			result.add(items, failed)
+			return nil
+		case !errors.Is(err, ErrBatchDeleteNotSupported):
+			return err
+		}
+	}
+	for _, item := range items {
+		if err := client.DeleteObject(ctx, bucket, item.ObjectKey()); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			failed[item.ObjectKey()] = err
+		}
+	}
+	result.add(items, failed)
+	return nil
+}
+
+func (r *DeleteResult) add(items []*ObjectItem, failed map[string]error) {
+	for _, item := range items {
+		if err, ok := failed[item.ObjectKey()]; ok {
+			r.Failed = append(r.Failed, &DeleteFailure{Key: item.ObjectKey(), Err: err})
+			continue
+		}
+		r.Deleted++
+		r.Bytes += item.Size
+	}
+}
@@ -0,0 +1,136 @@
+package stu_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lusingander/stu/internal/mock"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+// batchClient deletes objects in batches and records the size of each batch.
+type batchClient struct {
+	*mock.Client
+	batches []int
+	// keys reported as failed
+	fail map[string]bool
+	err  error
+}
+
+func (c *batchClient) DeleteObjects(ctx context.Context, bucket string, keys []string) ([]*stu.DeleteFailure, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	c.batches = append(c.batches, len(keys))
+	failures := make([]*stu.DeleteFailure, 0)
+	for _, key := range keys {
+		if c.fail[key] {
+			failures = append(failures, &stu.DeleteFailure{Key: key, Err: errors.New("access denied")})
+			continue
+		}
+		if err := c.DeleteObject(ctx, bucket, key); err != nil {
+			return nil, err
+		}
+	}
+	return failures, nil
+}
+
+func newDeleteTestClient(n int) *mock.Client {
+	client := mock.NewClient()
+	for i := 0; i < n; i++ {
+		client.PutObject("bucket", &mock.Object{Key: fmt.Sprintf("dir/%05d", i), Content: []byte("x")})
+	}
+	client.PutObject("bucket", &mock.Object{Key: "other", Content: []byte("x")})
+	return client
+}
+
+func TestDeletePrefix(t *testing.T) {
+	tests := []struct {
+		name    string
+		objects int
+		fail    []string
+		err     error
+		batches []int
+		deleted int
+		failed  int
+		wantErr bool
+	}{
+		{"empty", 0, nil, nil, nil, 0, 0, false},
+		{"one batch", 3, nil, nil, []int{3}, 3, 0, false},
+		{"full batch", 1000, nil, nil, []int{1000}, 1000, 0, false},
+		{"several batches", 2500, nil, nil, []int{1000, 1000, 500}, 2500, 0, false},
+		{"failures", 1001, []string{"dir/00000", "dir/01000"}, nil, []int{1000, 1}, 999, 2, false},
+		{"one by one", 3, nil, stu.ErrBatchDeleteNotSupported, nil, 3, 0, false},
+		{"request error", 3, nil, errors.New("internal error"), nil, 0, 0, true},
+	}
+	for _, tt := range tests {
+		client := &batchClient{Client: newDeleteTestClient(tt.objects), fail: make(map[string]bool), err: tt.err}
+		for _, key := range tt.fail {
+			client.fail[key] = true
+		}
+		var reports []stu.Progress
+		result, err := stu.DeletePrefix(context.Background(), client, "bucket", "dir/", func(p stu.Progress) {
+			reports = append(reports, p)
+		})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: DeletePrefix() returned error: %v", tt.name, err)
+			continue
+		}
+		if fmt.Sprint(client.batches) != fmt.Sprint(tt.batches) {
+			t.Errorf("%s: batches = %v, want %v", tt.name, client.batches, tt.batches)
+		}
+		if result.Deleted != tt.deleted || len(result.Failed) != tt.failed || result.Bytes != int64(tt.deleted) {
+			t.Errorf("%s: deleted %d (%d bytes), failed %d, want %d, %d", tt.name, result.Deleted, result.Bytes, len(result.Failed), tt.deleted, tt.failed)
+		}
+		if tt.wantErr {
+			continue
+		}
+		if len(reports) > 0 {
+			if last := reports[len(reports)-1]; last.Items != tt.objects || last.Failed != tt.failed {
+				t.Errorf("%s: last progress = %+v", tt.name, last)
+			}
+		}
+		// failed objects and objects outside of the prefix are left
+		left := 0
+		err = client.WalkObjects(context.Background(), "bucket", "", func(*stu.ObjectItem) error {
+			left++
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if left != 1+tt.failed {
+			t.Errorf("%s: %d objects are left, want %d", tt.name, left, 1+tt.failed)
+		}
+	}
+}
+
+func TestDeleteItems(t *testing.T) {
+	client := &batchClient{Client: newDeleteTestClient(5), fail: map[string]bool{"dir/00003": true}}
+	var items []*stu.ObjectItem
+	for _, key := range []string{"dir/00001", "dir/00003", "dir/00004"} {
+		items = append(items, stu.NewFileObjectItem(key, 1, time.Time{}))
+	}
+	result, err := stu.DeleteItems(context.Background(), client, "bucket", items, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Deleted != 2 || len(result.Failed) != 1 || result.Failed[0].Key != "dir/00003" {
+		t.Errorf("deleted %d, failed %v", result.Deleted, result.Failed)
+	}
+	// objects which are not given are left even under the same prefix
+	var left []string
+	err = client.WalkObjects(context.Background(), "bucket", "", func(item *stu.ObjectItem) error {
+		left = append(left, item.ObjectKey())
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[dir/00000 dir/00002 dir/00003 other]"; fmt.Sprint(left) != want {
+		t.Errorf("left %v, want %s", left, want)
+	}
+}
@@ -0,0 +1,139 @@
+package stu
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+type DownloadFailure struct {
+	Key string
+	Err error
+}
+
+type DownloadResult struct {
+	Downloaded int
+	Bytes      int64
+	Failed     []*DownloadFailure
+}
+
+func (r *DownloadResult) Progress() Progress {
+	return Progress{
+		Items:  r.Downloaded + len(r.Failed),
+		Failed: len(r.Failed),
+		Bytes:  r.Bytes,
+	}
+}
+
+// DownloadPrefix downloads the objects under prefix for which filter returns true (all if nil)
+// into dir with at most concurrency objects in flight, keeping the hierarchy below prefix.
+// Existing files are overwritten. Objects which fail are reported in the result instead of
+// stopping the download. progress is called after each object.
+func DownloadPrefix(ctx context.Context, client Client, bucket, prefix, dir string, filter func(key string) bool, concurrency int, progress ProgressFunc) (*DownloadResult, error) {
+	return downloadObjects(ctx, client, bucket, prefix, walkPrefix(client, bucket, prefix), dir, filter, concurrency, progress)
+}
+
+// DownloadItems downloads the given objects under prefix in the same way as DownloadPrefix.
+func DownloadItems(ctx context.Context, client Client, bucket, prefix string, items []*ObjectItem, dir string, concurrency int, progress ProgressFunc) (*DownloadResult, error) {
+	return downloadObjects(ctx, client, bucket, prefix, walkItems(items), dir, nil, concurrency, progress)
+}
+
+func downloadObjects(ctx context.Context, client Client, bucket, prefix string, walk walkFunc, dir string, filter func(key string) bool, concurrency int, progress ProgressFunc) (*DownloadResult, error) {
+	result := &DownloadResult{Failed: make([]*DownloadFailure, 0)}
+	var mu sync.Mutex
+
+	items := make(chan *ObjectItem)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range items {
+				key := item.ObjectKey()
+				n, err := downloadToDir(ctx, client, bucket, key, dir, strings.TrimPrefix(key, prefix))
+				if ctx.Err() != nil {
+					return
+				}
+				mu.Lock()
+				if err != nil {
+					result.Failed = append(result.Failed, &DownloadFailure{Key: key, Err: err})
+				} else {
+					result.Downloaded++
+					result.Bytes += n
+				}
+				p := result.Progress()
+				mu.Unlock()
+				p.Key = key
+				progress.report(p)
+			}
+		}()
+	}
+
+	walkErr := walk(ctx, func(item *ObjectItem) error {
+		if item.FolderMarker() || (filter != nil && !filter(item.ObjectKey())) {
+			return nil
+		}
+		select {
+		case items <- item:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	close(items)
+	wg.Wait()
+
+	if walkErr != nil {
+		return result, walkErr
+	}
+	return result, ctx.Err()
+}
+
+// downloadToDir writes the object to rel under dir, the partial file is removed on failure.
+func downloadToDir(ctx context.Context, client Client, bucket, key, dir, rel string) (int64, error) {
+	path, err := localPath(dir, rel)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return 0, err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	n, err := DownloadObject(ctx, client, bucket, key, f, nil)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return n, err
+	}
+	return n, nil
+}
+
+// localPath refuses keys which would be written outside of dir, such as "../x",
+// or "..\x" and "C:x" on Windows.
+func localPath(dir, rel string) (string, error) {
+	native := filepath.FromSlash(rel)
+	invalid := rel == "" || filepath.IsAbs(native) || filepath.VolumeName(native) != ""
+	for _, seg := range strings.FieldsFunc(rel, isSeparator) {
+		invalid = invalid || seg == ".."
+	}
+	path := filepath.Join(dir, native)
+	if r, err := filepath.Rel(dir, path); err != nil || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+		invalid = true
+	}
+	if invalid {
+		return "", fmt.Errorf("key is not a valid local path: %q", rel)
+	}
+	return path, nil
+}
+
+func isSeparator(r rune) bool {
+	return r == '/' || r == filepath.Separator
+}
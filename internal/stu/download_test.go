@@ -0,0 +1,46 @@
+package stu
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestLocalPath(t *testing.T) {
+	dir := filepath.Join("tmp", "out")
+	tests := []struct {
+		rel     string
+		want    string
+		wantErr bool
+	}{
+		{"file.txt", filepath.Join(dir, "file.txt"), false},
+		{"a/b/file.txt", filepath.Join(dir, "a", "b", "file.txt"), false},
+		{"a/./file.txt", filepath.Join(dir, "a", "file.txt"), false},
+		{"a..b", filepath.Join(dir, "a..b"), false},
+		{"", "", true},
+		{"..", "", true},
+		{"../file.txt", "", true},
+		{"a/../../file.txt", "", true},
+		{"a/../file.txt", "", true},
+		{"/etc/passwd", "", runtime.GOOS != "windows"},
+		{`..\file.txt`, "", runtime.GOOS == "windows"},
+		{`C:file.txt`, "", runtime.GOOS == "windows"},
+		{`C:\file.txt`, "", runtime.GOOS == "windows"},
+	}
+	for _, tt := range tests {
+		got, err := localPath(dir, tt.rel)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("localPath(%q) = %q, want error", tt.rel, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("localPath(%q) returned error: %v", tt.rel, err)
+			continue
+		}
+		if tt.want != "" && got != tt.want {
+			t.Errorf("localPath(%q) = %q, want %q", tt.rel, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package stu
+
+import (
+	"context"
+	"sort"
+)
+
+// DuplicateGroup is a set of objects which have the same ETag and size.
+// ETag of a multipart upload is not the MD5 of the content,
+// so objects are only likely to be identical.
+type DuplicateGroup struct {
+	ETag  string
+	Size  int64
+	Items []*ObjectItem
+}
+
+// Wasted returns the bytes which would be freed by keeping only one of the objects.
+func (g *DuplicateGroup) Wasted() int64 {
+	return g.Size * int64(len(g.Items)-1)
+}
+
+type duplicateKey struct {
+	etag string
+	size int64
+}
+
+// FindDuplicates walks all objects under the prefix and groups them by ETag.
+// Empty objects are ignored because they all have the same ETag.
+// Groups are sorted by wasted bytes in descending order.
+func FindDuplicates(ctx context.Context, client Client, bucket, prefix string, progress ProgressFunc) ([]*DuplicateGroup, error) {
+	groups := make(map[duplicateKey]*DuplicateGroup)
+	var p Progress
+	err := client.WalkObjects(ctx, bucket, prefix, func(item *ObjectItem) error {
+		p.scanned(item)
+		if p.Items%reportProgressInterval == 0 {
+			progress.report(p)
+		}
+		if item.Size == 0 || item.ETag == "" {
+			return nil
+		}
+		k := duplicateKey{etag: item.ETag, size: item.Size}
+		g, ok := groups[k]
+		if !ok {
+			g = &DuplicateGroup{ETag: item.ETag, Size: item.Size}
+			groups[k] = g
+		}
+		g.Items = append(g.Items, item)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*DuplicateGroup, 0)
+	for _, g := range groups {
+		if len(g.Items) > 1 {
+			ret = append(ret, g)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Wasted() != ret[j].Wasted() {
+			return ret[i].Wasted() > ret[j].Wasted()
+		}
+		return ret[i].ETag < ret[j].ETag
+	})
+	return ret, nil
+}
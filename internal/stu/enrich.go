@@ -0,0 +1,146 @@
+package stu
+
+import (
+	"context"
+	"sync"
+)
+
+// ObjectHead is the data of an object which is not included in listings.
+type ObjectHead struct {
+	Size                 int64
+	ContentType          string
+	ETag                 string
+	StorageClass         string
+	ServerSideEncryption string
+	ReplicationStatus    string
+	Metadata             map[string]string
+}
+
+type HeadResult struct {
+	Bucket string
+	Key    string
+	Head   *ObjectHead
+	Err    error
+}
+
+type headRequest struct {
+	bucket string
+	key    string
+}
+
+// Enricher fetches ObjectHead of objects in the background with bounded concurrency.
+// Objects passed as visible are fetched before the others.
+type Enricher struct {
+	client  Client
+	cancels []context.CancelFunc
+	results chan *HeadResult
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	high     []*headRequest
+	low      []*headRequest
+	inflight map[headRequest]bool
+	closed   bool
+}
+
+func NewEnricher(tasks *TaskManager, client Client, concurrency int) *Enricher {
+	e := &Enricher{
+		client:   client,
+		results:  make(chan *HeadResult, concurrency),
+		inflight: make(map[headRequest]bool),
+	}
+	e.cond = sync.NewCond(&e.mu)
+	for i := 0; i < concurrency; i++ {
+		e.cancels = append(e.cancels, tasks.Go("enrich", e.work))
+	}
+	return e
+}
+
+func (e *Enricher) Results() <-chan *HeadResult {
+	return e.results
+}
+
+// SetClient replaces the client of the following requests and drops the queued ones.
+func (e *Enricher) SetClient(client Client) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.client = client
+	e.high = nil
+	e.low = nil
+}
+
+// Enqueue replaces the queued requests, so that objects which are no longer shown are not fetched.
+func (e *Enricher) Enqueue(bucket string, visible, rest []*ObjectItem) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.high = e.requests(bucket, visible)
+	e.low = e.requests(bucket, rest)
+	e.cond.Broadcast()
+}
+
+func (e *Enricher) requests(bucket string, items []*ObjectItem) []*headRequest {
+	reqs := make([]*headRequest, 0, len(items))
+	for _, item := range items {
+		req := &headRequest{bucket: bucket, key: item.ObjectKey()}
+		if !e.inflight[*req] {
+			reqs = append(reqs, req)
+		}
+	}
+	return reqs
+}
+
+func (e *Enricher) next() (*headRequest, Client, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for len(e.high) == 0 && len(e.low) == 0 && !e.closed {
+		e.cond.Wait()
+	}
+	if e.closed {
+		return nil, nil, false
+	}
+	var req *headRequest
+	if len(e.high) > 0 {
+		req, e.high = e.high[0], e.high[1:]
+	} else {
+		req, e.low = e.low[0], e.low[1:]
+	}
+	e.inflight[*req] = true
+	return req, e.client, true
+}
+
+func (e *Enricher) done(req *headRequest) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.inflight, *req)
+}
+
+func (e *Enricher) work(ctx context.Context) {
+	for {
+		req, client, ok := e.next()
+		if !ok {
+			return
+		}
+		if ctx.Err() != nil {
+			// canceled from the tasks page
+			e.done(req)
+			return
+		}
+		head, err := client.HeadObject(ctx, req.bucket, req.key)
+		e.done(req)
+		select {
+		case e.results <- &HeadResult{Bucket: req.bucket, Key: req.key, Head: head, Err: err}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (e *Enricher) Close() {
+	e.mu.Lock()
+	e.closed = true
+	e.cond.Broadcast()
+	e.mu.Unlock()
+	for _, cancel := range e.cancels {
+		cancel()
+	}
+}
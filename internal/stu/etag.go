@@ -0,0 +1,141 @@
+package stu
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const mib = 1024 * 1024
+
+// part sizes used by default in common tools (aws cli, sdks, console, rclone, ...)
+var commonPartSizes = []int64{5 * mib, 8 * mib, 15 * mib, 16 * mib, 32 * mib, 64 * mib, 100 * mib, 128 * mib, 256 * mib, 512 * mib, 1024 * mib}
+
+// MultipartETagParts returns the number of parts of an ETag of a multipart upload (<md5 of part md5s>-<parts>),
+// or 0 for other ETags.
+func MultipartETagParts(etag string) int {
+	i := strings.LastIndex(etag, "-")
+	if i < 0 {
+		return 0
+	}
+	n, err := strconv.Atoi(etag[i+1:])
+	if err != nil || n <= 0 {
+		return 0
+	}
+	return n
+}
+
+// ComputeETag computes the ETag of the content uploaded with the part size, or with a single PUT if partSize is 0.
+func ComputeETag(ctx context.Context, r io.Reader, partSize int64) (string, error) {
+	if partSize <= 0 {
+		h := md5.New()
+		if _, err := io.Copy(h, r); err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+	sums := md5.New()
+	parts := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		h := md5.New()
+		n, err := io.CopyN(h, r, partSize)
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		if n == 0 && parts > 0 {
+			break
+		}
+		sums.Write(h.Sum(nil))
+		parts++
+		if n < partSize {
+			break
+		}
+	}
+	return fmt.Sprintf("%s-%d", hex.EncodeToString(sums.Sum(nil)), parts), nil
+}
+
+// PartSizeCandidates returns the part sizes which split size bytes into the given number of parts,
+// the common ones first, followed by the smallest size rounded up to MiB.
+func PartSizeCandidates(size int64, parts int) []int64 {
+	n := int64(parts)
+	fits := func(p int64) bool {
+		return p > 0 && (n-1)*p < size && size <= n*p
+	}
+	if n == 1 {
+		// every size larger than the content gives the same ETag
+		return []int64{size + 1}
+	}
+	ret := make([]int64, 0)
+	seen := make(map[int64]bool)
+	add := func(p int64) {
+		if fits(p) && !seen[p] {
+			seen[p] = true
+			ret = append(ret, p)
+		}
+	}
+	for _, p := range commonPartSizes {
+		add(p)
+	}
+	min := (size + n - 1) / n
+	add((min + mib - 1) / mib * mib)
+	add(min)
+	return ret
+}
+
+// ETagVerification is the result of comparing a local file with the ETag of an object.
+type ETagVerification struct {
+	Match bool
+	// ETag computed from the local file, with the first candidate part size if none match
+	Local string
+	// part size which reproduced the ETag, 0 for a single part upload
+	PartSize int64
+	// local file size differs from the object
+	SizeMismatch bool
+}
+
+// VerifyETag recomputes the ETag of the local file and compares it with the ETag of the object.
+// The part size of a multipart upload is not recorded, so the likely part sizes are tried in turn.
+func VerifyETag(ctx context.Context, path, etag string, size int64) (*ETagVerification, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() != size {
+		return &ETagVerification{SizeMismatch: true}, nil
+	}
+	partSizes := []int64{0}
+	if parts := MultipartETagParts(etag); parts > 0 {
+		partSizes = PartSizeCandidates(size, parts)
+	}
+	ret := &ETagVerification{}
+	for i, p := range partSizes {
+		local, err := computeFileETag(ctx, path, p)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			ret.Local = local
+		}
+		if local == etag {
+			return &ETagVerification{Match: true, Local: local, PartSize: p}, nil
+		}
+	}
+	return ret, nil
+}
+
+func computeFileETag(ctx context.Context, path string, partSize int64) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return ComputeETag(ctx, f, partSize)
+}
@@ -0,0 +1,126 @@
+package stu
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// multipartETag computes the ETag from the parts, as S3 does for multipart uploads.
+func multipartETag(parts ...string) string {
+	var sums []byte
+	for _, p := range parts {
+		sum := md5.Sum([]byte(p))
+		sums = append(sums, sum[:]...)
+	}
+	sum := md5.Sum(sums)
+	return fmt.Sprintf("%s-%d", hex.EncodeToString(sum[:]), len(parts))
+}
+
+func TestComputeETag(t *testing.T) {
+	sum := md5.Sum([]byte("0123456789"))
+	tests := []struct {
+		name     string
+		content  string
+		partSize int64
+		want     string
+	}{
+		{"single put", "0123456789", 0, hex.EncodeToString(sum[:])},
+		{"last part is short", "0123456789", 4, multipartETag("0123", "4567", "89")},
+		{"parts of the same size", "01234567", 4, multipartETag("0123", "4567")},
+		{"one part", "0123", 4, multipartETag("0123")},
+		{"part larger than content", "01", 4, multipartETag("01")},
+		{"empty", "", 4, multipartETag("")},
+	}
+	for _, tt := range tests {
+		got, err := ComputeETag(context.Background(), bytes.NewReader([]byte(tt.content)), tt.partSize)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: ComputeETag() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMultipartETagParts(t *testing.T) {
+	tests := []struct {
+		etag string
+		want int
+	}{
+		{"d41d8cd98f00b204e9800998ecf8427e", 0},
+		{"d41d8cd98f00b204e9800998ecf8427e-1", 1},
+		{"d41d8cd98f00b204e9800998ecf8427e-25", 25},
+		{"d41d8cd98f00b204e9800998ecf8427e-0", 0},
+		{"d41d8cd98f00b204e9800998ecf8427e-x", 0},
+		{"d41d8cd98f00b204e9800998ecf8427e-", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := MultipartETagParts(tt.etag); got != tt.want {
+			t.Errorf("MultipartETagParts(%q) = %d, want %d", tt.etag, got, tt.want)
+		}
+	}
+}
+
+func TestPartSizeCandidates(t *testing.T) {
+	tests := []struct {
+		name  string
+		size  int64
+		parts int
+		want  []int64
+	}{
+		{"one part", 100, 1, []int64{101}},
+		{"aws cli default", 20 * mib, 3, []int64{8 * mib, 7 * mib, (20*mib + 2) / 3}},
+		{"exact multiple", 16 * mib, 2, []int64{8 * mib, 15 * mib}},
+		{"no common size", 10, 3, []int64{4}},
+	}
+	for _, tt := range tests {
+		got := PartSizeCandidates(tt.size, tt.parts)
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: PartSizeCandidates(%d, %d) = %v, want %v", tt.name, tt.size, tt.parts, got, tt.want)
+		}
+		for _, p := range got {
+			if n := (tt.size + p - 1) / p; n != int64(tt.parts) {
+				t.Errorf("%s: part size %d gives %d parts", tt.name, p, n)
+			}
+		}
+	}
+}
+
+func TestVerifyETag(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	if err := ioutil.WriteFile(path, []byte("0123456789"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sum := md5.Sum([]byte("0123456789"))
+	tests := []struct {
+		name     string
+		etag     string
+		size     int64
+		match    bool
+		partSize int64
+		mismatch bool
+	}{
+		{"single put", hex.EncodeToString(sum[:]), 10, true, 0, false},
+		{"multipart", multipartETag("0123", "4567", "89"), 10, true, 4, false},
+		{"different content", multipartETag("0123", "4567", "xx"), 10, false, 0, false},
+		{"different size", hex.EncodeToString(sum[:]), 11, false, 0, true},
+	}
+	for _, tt := range tests {
+		got, err := VerifyETag(context.Background(), path, tt.etag, tt.size)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got.Match != tt.match || got.PartSize != tt.partSize || got.SizeMismatch != tt.mismatch {
+			t.Errorf("%s: VerifyETag() = %+v", tt.name, got)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package stu
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ExportFormat int
+
+const (
+	ExportJSON ExportFormat = iota
+	ExportCSV
+)
+
+func ExportFormatFromPath(path string) (ExportFormat, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return ExportJSON, nil
+	case ".csv":
+		return ExportCSV, nil
+	}
+	return 0, fmt.Errorf("unsupported export format: %s (use .json or .csv)", path)
+}
+
+type exportSizeBucket struct {
+	Label string `json:"label"`
+	Max   int64  `json:"max,omitempty"`
+	Count int    `json:"count"`
+	Bytes int64  `json:"bytes"`
+}
+
+type exportStats struct {
+	Bucket    string              `json:"bucket"`
+	Prefix    string              `json:"prefix"`
+	Objects   int                 `json:"objects"`
+	TotalSize int64               `json:"totalSize"`
+	Histogram []*exportSizeBucket `json:"histogram"`
+}
+
+type exportObject struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"lastModified"`
+}
+
+func ExportStats(w io.Writer, stats *PrefixStats, format ExportFormat) error {
+	if format == ExportCSV {
+		cw := csv.NewWriter(w)
+		records := [][]string{{"label", "max", "count", "bytes"}}
+		for _, b := range stats.Histogram {
+			records = append(records, []string{b.Label, strconv.FormatInt(b.Max, 10), strconv.Itoa(b.Count), strconv.FormatInt(b.Bytes, 10)})
+		}
+		records = append(records, []string{"total", "", strconv.Itoa(stats.Objects), strconv.FormatInt(stats.TotalSize, 10)})
+		return cw.WriteAll(records)
+	}
+
+	e := &exportStats{
+		Bucket:    stats.Bucket,
+		Prefix:    stats.Prefix,
+		Objects:   stats.Objects,
+		TotalSize: stats.TotalSize,
+		Histogram: make([]*exportSizeBucket, len(stats.Histogram)),
+	}
+	for i, b := range stats.Histogram {
+		e.Histogram[i] = &exportSizeBucket{Label: b.Label, Max: b.Max, Count: b.Count, Bytes: b.Bytes}
+	}
+	return writeJSON(w, e)
+}
+
+func ExportObjects(w io.Writer, items []*ObjectItem, format ExportFormat) error {
+	if format == ExportCSV {
+		cw := csv.NewWriter(w)
+		records := [][]string{{"key", "size", "last_modified"}}
+		for _, item := range items {
+			records = append(records, []string{item.ObjectKey(), strconv.FormatInt(item.Size, 10), item.LastModified.Format(time.RFC3339)})
+		}
+		return cw.WriteAll(records)
+	}
+
+	objs := make([]*exportObject, len(items))
+	for i, item := range items {
+		objs[i] = &exportObject{Key: item.ObjectKey(), Size: item.Size, LastModified: item.LastModified}
+	}
+	return writeJSON(w, objs)
+}
+
+type exportDuplicateGroup struct {
+	ETag   string   `json:"etag"`
+	Size   int64    `json:"size"`
+	Wasted int64    `json:"wasted"`
+	Keys   []string `json:"keys"`
+}
+
+func ExportDuplicates(w io.Writer, groups []*DuplicateGroup, format ExportFormat) error {
+	if format == ExportCSV {
+		cw := csv.NewWriter(w)
+		records := [][]string{{"etag", "size", "key"}}
+		for _, g := range groups {
+			for _, item := range g.Items {
+				records = append(records, []string{g.ETag, strconv.FormatInt(g.Size, 10), item.ObjectKey()})
+			}
+		}
+		return cw.WriteAll(records)
+	}
+
+	gs := make([]*exportDuplicateGroup, len(groups))
+	for i, g := range groups {
+		keys := make([]string, len(g.Items))
+		for j, item := range g.Items {
+			keys[j] = item.ObjectKey()
+		}
+		gs[i] = &exportDuplicateGroup{ETag: g.ETag, Size: g.Size, Wasted: g.Wasted(), Keys: keys}
+	}
+	return writeJSON(w, gs)
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
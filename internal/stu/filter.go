@@ -0,0 +1,83 @@
+package stu
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// BucketFilter selects the buckets shown by default.
+type BucketFilter struct {
+	include []string
+	exclude []string
+}
+
+func NewBucketFilter(include, exclude []string) (*BucketFilter, error) {
+	for _, p := range append(append([]string{}, include...), exclude...) {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid bucket pattern %q: %w", p, err)
+		}
+	}
+	return &BucketFilter{
+		include: include,
+		exclude: exclude,
+	}, nil
+}
+
+func (f *BucketFilter) Visible(name string) bool {
+	if len(f.include) > 0 && !matchAny(f.include, name) {
+		return false
+	}
+	return !matchAny(f.exclude, name)
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// KeyFilter selects objects under a prefix by the key below the prefix.
+// Patterns without "/" are matched against the last segment of the key.
+type KeyFilter struct {
+	prefix  string
+	include []string
+	exclude []string
+}
+
+func NewKeyFilter(prefix string, include, exclude []string) (*KeyFilter, error) {
+	for _, p := range append(append([]string{}, include...), exclude...) {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid key pattern %q: %w", p, err)
+		}
+	}
+	return &KeyFilter{
+		prefix:  prefix,
+		include: include,
+		exclude: exclude,
+	}, nil
+}
+
+func (f *KeyFilter) Match(key string) bool {
+	rel := strings.TrimPrefix(key, f.prefix)
+	if len(f.include) > 0 && !matchAnyKey(f.include, rel) {
+		return false
+	}
+	return !matchAnyKey(f.exclude, rel)
+}
+
+func matchAnyKey(patterns []string, rel string) bool {
+	for _, p := range patterns {
+		name := rel
+		if !strings.Contains(p, "/") {
+			name = path.Base(rel)
+		}
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
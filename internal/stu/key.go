@@ -0,0 +1,46 @@
+package stu
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const maxKeyBytes = 1024
+
+// CheckKey checks a new key or prefix typed by the user.
+// It returns an error for keys S3 rejects, and warnings for keys S3 accepts as they are
+// but which are likely mistakes or are handled differently by other tools.
+func CheckKey(key string) ([]string, error) {
+	if !utf8.ValidString(key) {
+		return nil, fmt.Errorf("key is not valid UTF-8")
+	}
+	if len(key) > maxKeyBytes {
+		return nil, fmt.Errorf("key is %d bytes, longer than %d bytes", len(key), maxKeyBytes)
+	}
+	warnings := make([]string, 0)
+	if strings.IndexFunc(key, unicode.IsControl) >= 0 {
+		warnings = append(warnings, "contains control characters")
+	}
+	segments := strings.Split(strings.TrimSuffix(key, delimiter), delimiter)
+	for _, seg := range segments {
+		if seg != strings.TrimSpace(seg) {
+			warnings = append(warnings, fmt.Sprintf("%q has leading or trailing spaces", seg))
+			break
+		}
+	}
+	for _, seg := range segments {
+		if seg == "." || seg == ".." {
+			warnings = append(warnings, "contains . or .. segments, which are not resolved by S3 but are by many tools")
+			break
+		}
+	}
+	for _, seg := range segments {
+		if seg == "" && key != "" {
+			warnings = append(warnings, "contains an empty segment (// or a leading /)")
+			break
+		}
+	}
+	return warnings, nil
+}
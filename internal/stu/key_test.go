@@ -0,0 +1,47 @@
+package stu
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckKey(t *testing.T) {
+	tests := []struct {
+		key      string
+		warnings int
+		wantErr  bool
+	}{
+		{"", 0, false},
+		{"file.txt", 0, false},
+		{"dir/file.txt", 0, false},
+		{"dir/", 0, false},
+		{"日本語/ファイル.txt", 0, false},
+		{strings.Repeat("a", maxKeyBytes), 0, false},
+		{strings.Repeat("a", maxKeyBytes+1), 0, true},
+		{"\xff\xfe", 0, true},
+		{"dir/file\n.txt", 1, false},
+		{" file.txt", 1, false},
+		{"dir /file.txt", 1, false},
+		{"./file.txt", 1, false},
+		{"dir/../file.txt", 1, false},
+		{"/file.txt", 1, false},
+		{"dir//file.txt", 1, false},
+		{" dir/../x\t/", 3, false},
+	}
+	for _, tt := range tests {
+		got, err := CheckKey(tt.key)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("CheckKey(%q) = %q, want error", tt.key, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("CheckKey(%q) returned error: %v", tt.key, err)
+			continue
+		}
+		if len(got) != tt.warnings {
+			t.Errorf("CheckKey(%q) = %q, want %d warnings", tt.key, got, tt.warnings)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package stu
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// BucketPolicyReader is implemented by clients which can read bucket policies.
+type BucketPolicyReader interface {
+	// GetBucketPolicy returns an empty string if the bucket has no policy.
+	GetBucketPolicy(ctx context.Context, bucket string) (string, error)
+}
+
+var ErrPropertiesNotSupported = errors.New("bucket properties are not supported by the client")
+
+type policyStatement struct {
+	Sid          string
+	Effect       string
+	Principal    json.RawMessage
+	NotPrincipal json.RawMessage
+	Action       json.RawMessage
+	Condition    json.RawMessage
+}
+
+// FormatBucketPolicy indents the policy for reading.
+func FormatBucketPolicy(policy string) (string, error) {
+	var b bytes.Buffer
+	if err := json.Indent(&b, []byte(policy), "", "  "); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// LintBucketPolicy returns warnings about statements which grant access to anyone.
+// It is a basic check of the common mistakes, not an evaluation of the policy.
+func LintBucketPolicy(policy string) ([]string, error) {
+	var doc struct {
+		Statement json.RawMessage
+	}
+	if err := json.Unmarshal([]byte(policy), &doc); err != nil {
+		return nil, err
+	}
+	statements, err := parseStatements(doc.Statement)
+	if err != nil {
+		return nil, err
+	}
+	warnings := make([]string, 0)
+	for i, st := range statements {
+		if st.Effect != "Allow" {
+			continue
+		}
+		name := fmt.Sprintf("statement %d", i+1)
+		if st.Sid != "" {
+			name += fmt.Sprintf(" (%s)", st.Sid)
+		}
+		cond := ""
+		if len(st.Condition) > 0 && string(st.Condition) != "null" {
+			cond = ", unless denied by its conditions"
+		}
+		if len(st.NotPrincipal) > 0 {
+			warnings = append(warnings, fmt.Sprintf("%s: Allow with NotPrincipal grants access to every other principal%s", name, cond))
+			continue
+		}
+		if !publicPrincipal(st.Principal) {
+			continue
+		}
+		actions := stringOrList(st.Action)
+		if writes := writeActions(actions); len(writes) > 0 {
+			warnings = append(warnings, fmt.Sprintf("%s: anyone can %s%s", name, strings.Join(writes, ", "), cond))
+		} else if len(actions) > 0 {
+			warnings = append(warnings, fmt.Sprintf("%s: anyone can %s (public read)%s", name, strings.Join(actions, ", "), cond))
+		}
+	}
+	return warnings, nil
+}
+
+func parseStatements(raw json.RawMessage) ([]*policyStatement, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var list []*policyStatement
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return list, nil
+	}
+	var st policyStatement
+	if err := json.Unmarshal(raw, &st); err != nil {
+		return nil, err
+	}
+	return []*policyStatement{&st}, nil
+}
+
+// publicPrincipal reports whether the principal is "*" or {"AWS": "*"}.
+func publicPrincipal(raw json.RawMessage) bool {
+	for _, p := range stringOrList(raw) {
+		if p == "*" {
+			return true
+		}
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return false
+	}
+	for _, p := range stringOrList(m["AWS"]) {
+		if p == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// writeActions returns the actions which modify or delete objects or the bucket.
+func writeActions(actions []string) []string {
+	writes := make([]string, 0)
+	for _, a := range actions {
+		lower := strings.ToLower(a)
+		name := strings.TrimPrefix(lower, "s3:")
+		if lower == "*" || name == "*" || strings.HasPrefix(name, "put") || strings.HasPrefix(name, "delete") ||
+			strings.HasPrefix(name, "abort") || strings.HasPrefix(name, "restore") || strings.HasPrefix(name, "replicate") {
+			writes = append(writes, a)
+		}
+	}
+	return writes
+}
+
+func stringOrList(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return []string{s}
+	}
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return list
+	}
+	return nil
+}
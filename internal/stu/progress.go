@@ -0,0 +1,30 @@
+package stu
+
+// Progress is reported by long-running operations in the same form,
+// so that callers can show it without knowing the operation.
+type Progress struct {
+	// objects processed so far, including failed ones
+	Items int
+	// objects which failed, included in Items
+	Failed int
+	// bytes transferred (downloads, copies) or scanned (walks) so far
+	Bytes int64
+	// key of the object processed last
+	Key string
+}
+
+// ProgressFunc receives the progress of an operation. It may be nil.
+// Operations running objects concurrently call it from several goroutines.
+type ProgressFunc func(Progress)
+
+func (f ProgressFunc) report(p Progress) {
+	if f != nil {
+		f(p)
+	}
+}
+
+func (p *Progress) scanned(item *ObjectItem) {
+	p.Items++
+	p.Bytes += item.Size
+	p.Key = item.ObjectKey()
+}
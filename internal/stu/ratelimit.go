@@ -0,0 +1,64 @@
+package stu
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RateLimiter spaces requests evenly to keep them under the configured rate.
+// A nil RateLimiter does not limit anything.
+type RateLimiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+
+	waiting int32
+}
+
+// NewRateLimiter returns a limiter which allows rps requests per second, or nil if rps is not positive.
+func NewRateLimiter(rps float64) *RateLimiter {
+	if rps <= 0 {
+		return nil
+	}
+	return &RateLimiter{
+		interval: time.Duration(float64(time.Second) / rps),
+	}
+}
+
+// Wait blocks until the next request is allowed.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	atomic.AddInt32(&l.waiting, 1)
+	defer atomic.AddInt32(&l.waiting, -1)
+
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Throttled reports whether any request is waiting for the limiter.
+func (l *RateLimiter) Throttled() bool {
+	return l != nil && atomic.LoadInt32(&l.waiting) > 0
+}
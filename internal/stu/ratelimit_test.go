@@ -0,0 +1,82 @@
+package stu
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNewRateLimiter(t *testing.T) {
+	tests := []struct {
+		rps      float64
+		interval time.Duration
+	}{
+		{0, 0},
+		{-1, 0},
+		{1, time.Second},
+		{4, 250 * time.Millisecond},
+		{0.5, 2 * time.Second},
+	}
+	for _, tt := range tests {
+		l := NewRateLimiter(tt.rps)
+		if tt.interval == 0 {
+			if l != nil {
+				t.Errorf("NewRateLimiter(%v) = %+v, want nil", tt.rps, l)
+			}
+			continue
+		}
+		if l == nil || l.interval != tt.interval {
+			t.Errorf("NewRateLimiter(%v) = %+v, want interval %v", tt.rps, l, tt.interval)
+		}
+	}
+}
+
+func TestRateLimiterNil(t *testing.T) {
+	var l *RateLimiter
+	if err := l.Wait(context.Background()); err != nil {
+		t.Errorf("Wait() = %v", err)
+	}
+	if l.Throttled() {
+		t.Error("Throttled() = true")
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	l := NewRateLimiter(20)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the first request is not delayed, the others are spaced by 50ms
+	if d := time.Since(start); d < 200*time.Millisecond {
+		t.Errorf("5 requests at 20 rps took %v, want at least 200ms", d)
+	}
+}
+
+func TestRateLimiterCancel(t *testing.T) {
+	l := NewRateLimiter(0.1)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error)
+	go func() {
+		errc <- l.Wait(ctx)
+	}()
+	deadline := time.Now().Add(time.Second)
+	for !l.Throttled() {
+		if time.Now().After(deadline) {
+			t.Fatal("Throttled() = false while waiting")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-errc; err != context.Canceled {
+		t.Errorf("Wait() = %v, want %v", err, context.Canceled)
+	}
+	if l.Throttled() {
+		t.Error("Throttled() = true after the wait is canceled")
+	}
+}
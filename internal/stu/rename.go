@@ -0,0 +1,66 @@
+package stu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ValidateRename checks that objects under from can be moved to to.
+func ValidateRename(from, to string) error {
+	if to == "" || to == delimiter {
+		return errors.New("new prefix is empty")
+	}
+	if to == from {
+		return errors.New("new prefix is the same as the current one")
+	}
+	if strings.HasPrefix(to, from) {
+		return errors.New("new prefix must not be under the current one")
+	}
+	return nil
+}
+
+func renamedKey(key, from, to string) string {
+	return to + strings.TrimPrefix(key, from)
+}
+
+// PlanRename calls fn for each object under from with the key after renaming,
+// without changing anything. It fails if a key after renaming is too long for S3.
+func PlanRename(ctx context.Context, client Client, bucket, from, to string, fn func(item *ObjectItem, dst string) error) error {
+	if err := ValidateRename(from, to); err != nil {
+		return err
+	}
+	return client.WalkObjects(ctx, bucket, from, func(item *ObjectItem) error {
+		dst := renamedKey(item.ObjectKey(), from, to)
+		if len(dst) > maxKeyBytes {
+			return fmt.Errorf("%s would be longer than %d bytes", dst, maxKeyBytes)
+		}
+		return fn(item, dst)
+	})
+}
+
+// RenamePrefix copies each object under from to to and deletes the original one by one.
+// Moved objects no longer exist under from, so an interrupted rename is resumed by running it again.
+// It returns the number of moved objects, progress is called after each object.
+func RenamePrefix(ctx context.Context, client Client, bucket, from, to string, progress ProgressFunc) (int, error) {
+	if err := ValidateRename(from, to); err != nil {
+		return 0, err
+	}
+	moved := 0
+	var bytes int64
+	err := client.WalkObjects(ctx, bucket, from, func(item *ObjectItem) error {
+		key := item.ObjectKey()
+		if err := client.CopyObject(ctx, bucket, key, bucket, renamedKey(key, from, to), item.Size); err != nil {
+			return err
+		}
+		if err := client.DeleteObject(ctx, bucket, key); err != nil {
+			return err
+		}
+		moved++
+		bytes += item.Size
+		progress.report(Progress{Items: moved, Bytes: bytes, Key: key})
+		return nil
+	})
+	return moved, err
+}
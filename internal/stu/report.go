@@ -0,0 +1,90 @@
+package stu
+
+import (
+	"container/heap"
+	"context"
+	"sort"
+)
+
+const (
+	reportProgressInterval = 1000
+)
+
+type ReportKind int
+
+const (
+	ReportLargest ReportKind = iota
+	ReportNewest
+	ReportDuplicates
+)
+
+func (k ReportKind) String() string {
+	switch k {
+	case ReportLargest:
+		return "Largest objects"
+	case ReportNewest:
+		return "Most recently modified objects"
+	case ReportDuplicates:
+		return "Duplicate objects (same ETag)"
+	}
+	return ""
+}
+
+func (k ReportKind) less(a, b *ObjectItem) bool {
+	if k == ReportNewest {
+		return a.LastModified.Before(b.LastModified)
+	}
+	return a.Size < b.Size
+}
+
+type objectHeap struct {
+	items []*ObjectItem
+	kind  ReportKind
+}
+
+func (h objectHeap) Len() int           { return len(h.items) }
+func (h objectHeap) Less(i, j int) bool { return h.kind.less(h.items[i], h.items[j]) }
+func (h objectHeap) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+
+func (h *objectHeap) Push(x interface{}) {
+	h.items = append(h.items, x.(*ObjectItem))
+}
+
+func (h *objectHeap) Pop() interface{} {
+	n := len(h.items)
+	item := h.items[n-1]
+	h.items = h.items[:n-1]
+	return item
+}
+
+// TopObjects walks all objects under the prefix and keeps only the top n of them,
+// so the memory usage does not depend on the number of objects.
+// progress is called periodically with the scanned objects.
+func TopObjects(ctx context.Context, client Client, bucket, prefix string, kind ReportKind, n int, progress ProgressFunc) ([]*ObjectItem, error) {
+	h := &objectHeap{kind: kind}
+	if n <= 0 {
+		return h.items, nil
+	}
+	var p Progress
+	err := client.WalkObjects(ctx, bucket, prefix, func(item *ObjectItem) error {
+		p.scanned(item)
+		if p.Items%reportProgressInterval == 0 {
+			progress.report(p)
+		}
+		if h.Len() < n {
+			heap.Push(h, item)
+		} else if kind.less(h.items[0], item) {
+			h.items[0] = item
+			heap.Fix(h, 0)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	items := h.items
+	sort.SliceStable(items, func(i, j int) bool {
+		return kind.less(items[j], items[i])
+	})
+	return items, nil
+}
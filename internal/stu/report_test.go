@@ -0,0 +1,56 @@
+package stu_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lusingander/stu/internal/mock"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+func TestTopObjects(t *testing.T) {
+	base := time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)
+	client := mock.NewClient()
+	// sizes and dates are not in the order of the keys
+	sizes := []int{5, 1, 9, 3, 7, 2, 8}
+	for i, size := range sizes {
+		client.PutObject("bucket", &mock.Object{
+			Key:          fmt.Sprintf("dir/%d", i),
+			Content:      bytes.Repeat([]byte{0}, size),
+			LastModified: base.AddDate(0, 0, (i*3)%len(sizes)),
+		})
+	}
+	client.PutObject("bucket", &mock.Object{Key: "other/x", Content: bytes.Repeat([]byte{0}, 100), LastModified: base.AddDate(1, 0, 0)})
+
+	tests := []struct {
+		name   string
+		prefix string
+		kind   stu.ReportKind
+		n      int
+		want   []string
+	}{
+		{"largest", "dir/", stu.ReportLargest, 3, []string{"dir/2", "dir/6", "dir/4"}},
+		{"newest", "dir/", stu.ReportNewest, 3, []string{"dir/2", "dir/4", "dir/6"}},
+		{"more than objects", "dir/", stu.ReportLargest, 10, []string{"dir/2", "dir/6", "dir/4", "dir/0", "dir/3", "dir/5", "dir/1"}},
+		{"zero", "dir/", stu.ReportLargest, 0, []string{}},
+		{"whole bucket", "", stu.ReportLargest, 1, []string{"other/x"}},
+		{"no objects", "none/", stu.ReportLargest, 3, []string{}},
+	}
+	for _, tt := range tests {
+		items, err := stu.TopObjects(context.Background(), client, "bucket", tt.prefix, tt.kind, tt.n, nil)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		got := make([]string, len(items))
+		for i, item := range items {
+			got[i] = item.ObjectKey()
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: TopObjects() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package stu
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+)
+
+type SearchTarget int
+
+const (
+	SearchTag SearchTarget = iota
+	SearchMetadata
+)
+
+const (
+	searchTagPrefix      = "tag:"
+	searchMetadataPrefix = "meta:"
+)
+
+type SearchQuery struct {
+	Target SearchTarget
+	Key    string
+	Value  string
+	// match only by key existence if Value is not specified
+	hasValue bool
+}
+
+func ParseSearchQuery(s string) (*SearchQuery, error) {
+	s = strings.TrimSpace(s)
+	var target SearchTarget
+	switch {
+	case strings.HasPrefix(s, searchTagPrefix):
+		target = SearchTag
+		s = strings.TrimPrefix(s, searchTagPrefix)
+	case strings.HasPrefix(s, searchMetadataPrefix):
+		target = SearchMetadata
+		s = strings.TrimPrefix(s, searchMetadataPrefix)
+	default:
+		return nil, errors.New("query must start with tag: or meta:")
+	}
+	kv := strings.SplitN(s, "=", 2)
+	key := strings.TrimSpace(kv[0])
+	if key == "" {
+		return nil, errors.New("query key must not be empty")
+	}
+	q := &SearchQuery{
+		Target: target,
+		Key:    key,
+	}
+	if len(kv) == 2 {
+		q.Value = strings.TrimSpace(kv[1])
+		q.hasValue = true
+	}
+	if target == SearchMetadata {
+		// metadata keys are case-insensitive
+		q.Key = strings.ToLower(q.Key)
+	}
+	return q, nil
+}
+
+func (q *SearchQuery) String() string {
+	s := searchTagPrefix
+	if q.Target == SearchMetadata {
+		s = searchMetadataPrefix
+	}
+	s += q.Key
+	if q.hasValue {
+		s += "=" + q.Value
+	}
+	return s
+}
+
+func (q *SearchQuery) Match(attrs map[string]string) bool {
+	v, ok := attrs[q.Key]
+	if !ok {
+		return false
+	}
+	return !q.hasValue || v == q.Value
+}
+
+func (q *SearchQuery) fetch(ctx context.Context, client Client, bucket, key string) (map[string]string, error) {
+	if q.Target == SearchMetadata {
+		return client.GetObjectMetadata(ctx, bucket, key)
+	}
+	return client.GetObjectTags(ctx, bucket, key)
+}
+
+type SearchFailure struct {
+	Key string
+	Err error
+}
+
+type SearchResult struct {
+	Scanned int
+	Found   int
+	Bytes   int64
+	Failed  []*SearchFailure
+}
+
+func (r *SearchResult) Progress() Progress {
+	return Progress{
+		Items:  r.Scanned + len(r.Failed),
+		Failed: len(r.Failed),
+		Bytes:  r.Bytes,
+	}
+}
+
+// Search walks all objects under the prefix and checks each of them against the query.
+// S3 cannot search by tags or metadata on the server side, so the objects are fetched one by one
+// with at most concurrency requests in flight. Objects whose tags or metadata cannot be fetched
+// are reported in the result instead of stopping the search.
+// found is called for every matched object and progress after every object, both may be called
+// from multiple goroutines.
+func Search(ctx context.Context, client Client, bucket, prefix string, q *SearchQuery, concurrency int, found func(item *ObjectItem), progress ProgressFunc) (*SearchResult, error) {
+	result := &SearchResult{Failed: make([]*SearchFailure, 0)}
+	var mu sync.Mutex
+
+	items := make(chan *ObjectItem)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range items {
+				key := item.ObjectKey()
+				attrs, err := q.fetch(ctx, client, bucket, key)
+				if ctx.Err() != nil {
+					return
+				}
+				matched := err == nil && q.Match(attrs)
+				if matched && found != nil {
+					found(item)
+				}
+				mu.Lock()
+				if err != nil {
+					result.Failed = append(result.Failed, &SearchFailure{Key: key, Err: err})
+				} else {
+					result.Scanned++
+					result.Bytes += item.Size
+					if matched {
+						result.Found++
+					}
+				}
+				p := result.Progress()
+				mu.Unlock()
+				p.Key = key
+				progress.report(p)
+			}
+		}()
+	}
+
+	walkErr := client.WalkObjects(ctx, bucket, prefix, func(item *ObjectItem) error {
+		select {
+		case items <- item:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	close(items)
+	wg.Wait()
+
+	if walkErr != nil {
+		return result, walkErr
+	}
+	return result, ctx.Err()
+}
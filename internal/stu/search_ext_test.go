@@ -0,0 +1,113 @@
+package stu_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/lusingander/stu/internal/mock"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+// failingTagsClient fails to get the tags of some objects.
+type failingTagsClient struct {
+	*mock.Client
+	fail map[string]bool
+}
+
+func (c *failingTagsClient) GetObjectTags(ctx context.Context, bucket, key string) (map[string]string, error) {
+	if c.fail[key] {
+		return nil, errors.New("access denied")
+	}
+	return c.Client.GetObjectTags(ctx, bucket, key)
+}
+
+func TestSearch(t *testing.T) {
+	tests := []struct {
+		name    string
+		fail    []string
+		found   []string
+		scanned int
+	}{
+		{"no failures", nil, []string{"dir/00000", "dir/00002", "dir/00004"}, 6},
+		{"failures", []string{"dir/00001", "dir/00002"}, []string{"dir/00000", "dir/00004"}, 4},
+		{"all failed", []string{"dir/00000", "dir/00001", "dir/00002", "dir/00003", "dir/00004", "dir/00005"}, nil, 0},
+	}
+	q, err := stu.ParseSearchQuery("tag:env=prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		client := &failingTagsClient{Client: mock.NewClient(), fail: make(map[string]bool)}
+		for i := 0; i < 6; i++ {
+			env := "dev"
+			if i%2 == 0 {
+				env = "prod"
+			}
+			client.PutObject("bucket", &mock.Object{Key: fmt.Sprintf("dir/%05d", i), Content: []byte("x"), Tags: map[string]string{"env": env}})
+		}
+		client.PutObject("bucket", &mock.Object{Key: "other", Content: []byte("x"), Tags: map[string]string{"env": "prod"}})
+		for _, key := range tt.fail {
+			client.fail[key] = true
+		}
+
+		var mu sync.Mutex
+		var found []string
+		var reports []stu.Progress
+		result, err := stu.Search(context.Background(), client, "bucket", "dir/", q, 3, func(item *stu.ObjectItem) {
+			mu.Lock()
+			found = append(found, item.ObjectKey())
+			mu.Unlock()
+		}, func(p stu.Progress) {
+			mu.Lock()
+			reports = append(reports, p)
+			mu.Unlock()
+		})
+		if err != nil {
+			t.Errorf("%s: Search() returned error: %v", tt.name, err)
+			continue
+		}
+		sort.Strings(found)
+		if fmt.Sprint(found) != fmt.Sprint(tt.found) {
+			t.Errorf("%s: found %v, want %v", tt.name, found, tt.found)
+		}
+		if result.Scanned != tt.scanned || result.Found != len(tt.found) || len(result.Failed) != len(tt.fail) {
+			t.Errorf("%s: scanned %d, found %d, failed %d, want %d, %d, %d", tt.name, result.Scanned, result.Found, len(result.Failed), tt.scanned, len(tt.found), len(tt.fail))
+		}
+		failed := make([]string, 0, len(result.Failed))
+		for _, f := range result.Failed {
+			failed = append(failed, f.Key)
+		}
+		sort.Strings(failed)
+		if fmt.Sprint(failed) != fmt.Sprint(tt.fail) {
+			t.Errorf("%s: failed keys %v, want %v", tt.name, failed, tt.fail)
+		}
+		if len(reports) != 6 {
+			t.Errorf("%s: progress reported %d times, want 6", tt.name, len(reports))
+		} else if p := result.Progress(); p.Items != 6 || p.Failed != len(tt.fail) {
+			t.Errorf("%s: progress = %+v", tt.name, p)
+		}
+	}
+}
+
+func TestSearchCanceled(t *testing.T) {
+	client := mock.NewClient()
+	for i := 0; i < 100; i++ {
+		client.PutObject("bucket", &mock.Object{Key: fmt.Sprintf("dir/%05d", i), Content: []byte("x")})
+	}
+	q, err := stu.ParseSearchQuery("tag:env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	var once sync.Once
+	_, err = stu.Search(ctx, client, "bucket", "dir/", q, 2, nil, func(stu.Progress) {
+		once.Do(cancel)
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Search() returned %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,66 @@
+package stu
+
+import "testing"
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    *SearchQuery
+		str     string
+		wantErr bool
+	}{
+		{"tag:env=prod", &SearchQuery{Target: SearchTag, Key: "env", Value: "prod", hasValue: true}, "tag:env=prod", false},
+		{"  tag: env = prod ", &SearchQuery{Target: SearchTag, Key: "env", Value: "prod", hasValue: true}, "tag:env=prod", false},
+		{"tag:env", &SearchQuery{Target: SearchTag, Key: "env"}, "tag:env", false},
+		{"tag:env=", &SearchQuery{Target: SearchTag, Key: "env", hasValue: true}, "tag:env=", false},
+		{"tag:a=b=c", &SearchQuery{Target: SearchTag, Key: "a", Value: "b=c", hasValue: true}, "tag:a=b=c", false},
+		{"meta:Owner=Alice", &SearchQuery{Target: SearchMetadata, Key: "owner", Value: "Alice", hasValue: true}, "meta:owner=Alice", false},
+		{"tag:=prod", nil, "", true},
+		{"tag:", nil, "", true},
+		{"env=prod", nil, "", true},
+		{"", nil, "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSearchQuery(tt.s)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseSearchQuery(%q) = %+v, want error", tt.s, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseSearchQuery(%q) returned error: %v", tt.s, err)
+			continue
+		}
+		if *got != *tt.want {
+			t.Errorf("ParseSearchQuery(%q) = %+v, want %+v", tt.s, got, tt.want)
+		}
+		if got.String() != tt.str {
+			t.Errorf("ParseSearchQuery(%q).String() = %q, want %q", tt.s, got.String(), tt.str)
+		}
+	}
+}
+
+func TestSearchQueryMatch(t *testing.T) {
+	attrs := map[string]string{"env": "prod", "empty": ""}
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{"tag:env=prod", true},
+		{"tag:env=dev", false},
+		{"tag:env", true},
+		{"tag:empty", true},
+		{"tag:empty=", true},
+		{"tag:missing", false},
+	}
+	for _, tt := range tests {
+		q, err := ParseSearchQuery(tt.s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := q.Match(attrs); got != tt.want {
+			t.Errorf("%q.Match() = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package stu
+
+import (
+	"errors"
+	"fmt"
+	"path"
+)
+
+var ErrReadOnly = errors.New("bucket is read-only")
+
+// BucketSettings are applied to the operations inside a bucket.
+type BucketSettings struct {
+	// default storage class of objects written to the bucket
+	StorageClass  string
+	RequesterPays bool
+	// objects written to the bucket are encrypted with SSE-KMS using this key
+	SSEKMSKeyID string
+	ReadOnly    bool
+}
+
+type bucketOverride struct {
+	pattern  string
+	settings *BucketSettings
+}
+
+// BucketOverrides holds BucketSettings by bucket name pattern (path.Match).
+// A nil BucketOverrides has no overrides.
+type BucketOverrides struct {
+	overrides []*bucketOverride
+}
+
+func NewBucketOverrides() *BucketOverrides {
+	return &BucketOverrides{}
+}
+
+func (o *BucketOverrides) Add(pattern string, settings *BucketSettings) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid bucket pattern %q: %w", pattern, err)
+	}
+	o.overrides = append(o.overrides, &bucketOverride{pattern: pattern, settings: settings})
+	return nil
+}
+
+// Lookup returns the settings of the first matching pattern, or the zero settings.
+func (o *BucketOverrides) Lookup(bucket string) *BucketSettings {
+	if o != nil {
+		for _, ov := range o.overrides {
+			if ok, _ := path.Match(ov.pattern, bucket); ok {
+				return ov.settings
+			}
+		}
+	}
+	return &BucketSettings{}
+}
@@ -0,0 +1,42 @@
+package stu
+
+import "sort"
+
+type SortKey int
+
+const (
+	SortByName SortKey = iota
+	SortBySize
+	SortByLastModified
+)
+
+// SortObjects returns the items sorted by the key, directories are placed before files and sorted by name.
+// items is not modified as it may be cached by the client.
+func SortObjects(items []*ObjectItem, key SortKey, desc bool) []*ObjectItem {
+	sorted := make([]*ObjectItem, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Dir != b.Dir {
+			return a.Dir
+		}
+		if desc {
+			a, b = b, a
+		}
+		if a.Dir {
+			return a.ObjectKey() < b.ObjectKey()
+		}
+		switch key {
+		case SortBySize:
+			if a.Size != b.Size {
+				return a.Size < b.Size
+			}
+		case SortByLastModified:
+			if !a.LastModified.Equal(b.LastModified) {
+				return a.LastModified.Before(b.LastModified)
+			}
+		}
+		return a.ObjectKey() < b.ObjectKey()
+	})
+	return sorted
+}
@@ -0,0 +1,85 @@
+package stu
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	KB = 1 << (10 * (iota + 1))
+	MB
+	GB
+)
+
+const (
+	statsProgressInterval = 1000
+)
+
+type SizeBucket struct {
+	Label string
+	// upper bound (exclusive), zero means unbounded
+	Max   int64
+	Count int
+	Bytes int64
+}
+
+type PrefixStats struct {
+	Bucket    string
+	Prefix    string
+	Objects   int
+	TotalSize int64
+	Histogram []*SizeBucket
+}
+
+// BucketMetrics is the result of the latest stats of a whole bucket.
+type BucketMetrics struct {
+	Objects   int
+	Size      int64
+	UpdatedAt time.Time
+}
+
+func NewPrefixStats(bucket, prefix string) *PrefixStats {
+	return &PrefixStats{
+		Bucket: bucket,
+		Prefix: prefix,
+		Histogram: []*SizeBucket{
+			{Label: "0 - 1KB", Max: KB},
+			{Label: "1KB - 100KB", Max: 100 * KB},
+			{Label: "100KB - 1MB", Max: MB},
+			{Label: "1MB - 100MB", Max: 100 * MB},
+			{Label: "100MB - 1GB", Max: GB},
+			{Label: "> 1GB", Max: 0},
+		},
+	}
+}
+
+func (s *PrefixStats) Add(item *ObjectItem) {
+	s.Objects++
+	s.TotalSize += item.Size
+	for _, b := range s.Histogram {
+		if b.Max == 0 || item.Size < b.Max {
+			b.Count++
+			b.Bytes += item.Size
+			return
+		}
+	}
+}
+
+// CollectPrefixStats walks all objects under the prefix recursively.
+// progress is called periodically with the scanned objects.
+func CollectPrefixStats(ctx context.Context, client Client, bucket, prefix string, progress ProgressFunc) (*PrefixStats, error) {
+	stats := NewPrefixStats(bucket, prefix)
+	var p Progress
+	err := client.WalkObjects(ctx, bucket, prefix, func(item *ObjectItem) error {
+		stats.Add(item)
+		p.scanned(item)
+		if p.Items%statsProgressInterval == 0 {
+			progress.report(p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
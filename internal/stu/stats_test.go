@@ -0,0 +1,61 @@
+package stu
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPrefixStatsAdd(t *testing.T) {
+	tests := []struct {
+		size   int64
+		bucket string
+	}{
+		{0, "0 - 1KB"},
+		{KB - 1, "0 - 1KB"},
+		{KB, "1KB - 100KB"},
+		{100*KB - 1, "1KB - 100KB"},
+		{100 * KB, "100KB - 1MB"},
+		{MB, "1MB - 100MB"},
+		{100 * MB, "100MB - 1GB"},
+		{GB - 1, "100MB - 1GB"},
+		{GB, "> 1GB"},
+		{10 * GB, "> 1GB"},
+	}
+	for _, tt := range tests {
+		s := NewPrefixStats("bucket", "")
+		s.Add(NewFileObjectItem("key", tt.size, time.Time{}))
+		if s.Objects != 1 || s.TotalSize != tt.size {
+			t.Errorf("size %d: Objects = %d, TotalSize = %d", tt.size, s.Objects, s.TotalSize)
+		}
+		for _, b := range s.Histogram {
+			want := 0
+			if b.Label == tt.bucket {
+				want = 1
+			}
+			if b.Count != want {
+				t.Errorf("size %d: %q has %d objects, want %d", tt.size, b.Label, b.Count, want)
+			}
+		}
+	}
+}
+
+func TestPrefixStatsAddTotals(t *testing.T) {
+	s := NewPrefixStats("bucket", "")
+	for _, size := range []int64{1, 2, 2 * KB, 3 * KB, 2 * GB} {
+		s.Add(NewFileObjectItem("key", size, time.Time{}))
+	}
+	want := []struct {
+		count int
+		bytes int64
+	}{
+		{2, 3}, {2, 5 * KB}, {0, 0}, {0, 0}, {0, 0}, {1, 2 * GB},
+	}
+	if s.Objects != 5 || s.TotalSize != 3+5*KB+2*GB {
+		t.Errorf("Objects = %d, TotalSize = %d", s.Objects, s.TotalSize)
+	}
+	for i, b := range s.Histogram {
+		if b.Count != want[i].count || b.Bytes != want[i].bytes {
+			t.Errorf("%q = %d objects (%d bytes), want %d objects (%d bytes)", b.Label, b.Count, b.Bytes, want[i].count, want[i].bytes)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package stu
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"sync"
+)
+
+const (
+	downloadChunkSize = 1024 * 1024
+)
+
+var (
+	ErrPinnedRangeNotSupported = errors.New("reading a range of a specific object is not supported by the client")
+	ErrObjectChanged           = errors.New("the object was replaced during the download")
+)
+
+// PinnedRangeGetter is implemented by clients which can read a range only while the object has the ETag,
+// so that the parts of a parallel download come from the same object.
+type PinnedRangeGetter interface {
+	// GetObjectRangeIfMatch returns ErrObjectChanged if the ETag of the object is no longer etag.
+	GetObjectRangeIfMatch(ctx context.Context, bucket, key, etag string, offset, length int64) (io.ReadCloser, error)
+}
+
+// ReadObjectHead reads at most max bytes from the beginning of the object with a ranged request,
+// so the memory usage does not depend on the size of the object.
+// truncated is true if the object is larger than max.
+func ReadObjectHead(ctx context.Context, client Client, bucket string, item *ObjectItem, max int64) (data []byte, truncated bool, err error) {
+	length := item.Size
+	if length > max {
+		length = max
+	}
+	if length <= 0 {
+		return []byte{}, item.Size > 0, nil
+	}
+	r, err := client.GetObjectRange(ctx, bucket, item.ObjectKey(), 0, length)
+	if err != nil {
+		return nil, false, err
+	}
+	defer r.Close()
+
+	data = make([]byte, length)
+	n, err := io.ReadFull(io.LimitReader(r, length), data)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, false, err
+	}
+	return data[:n], item.Size > int64(n), nil
+}
+
+// ReadObjectTail reads at most max bytes from the end of the object with a ranged request.
+// truncated is true if the beginning of the object is not included.
+func ReadObjectTail(ctx context.Context, client Client, bucket string, item *ObjectItem, max int64) (data []byte, truncated bool, err error) {
+	offset := item.Size - max
+	if offset < 0 {
+		offset = 0
+	}
+	data, err = readRange(ctx, client, bucket, item.ObjectKey(), offset, item.Size-offset)
+	return data, offset > 0, err
+}
+
+// ReadAppended reads from offset to the current end of the object, to follow an object which is replaced
+// with a longer one such as a periodically synced log file. At most max bytes from the end are read.
+// If newOffset differs from offset, the data does not continue from the previous read.
+func ReadAppended(ctx context.Context, client Client, bucket, key string, offset, max int64) (data []byte, newOffset, size int64, err error) {
+	head, err := client.HeadObject(ctx, bucket, key)
+	if err != nil {
+		return nil, offset, 0, err
+	}
+	size = head.Size
+	if size < offset {
+		offset = 0
+	}
+	if size-offset > max {
+		offset = size - max
+	}
+	data, err = readRange(ctx, client, bucket, key, offset, size-offset)
+	return data, offset, size, err
+}
+
+func readRange(ctx context.Context, client Client, bucket, key string, offset, length int64) ([]byte, error) {
+	if length <= 0 {
+		return []byte{}, nil
+	}
+	r, err := client.GetObjectRange(ctx, bucket, key, offset, length)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(io.LimitReader(r, length))
+}
+
+// DownloadObject streams the object into w chunk by chunk.
+// progress is called after each chunk with the written bytes, and with Items 1 when done.
+func DownloadObject(ctx context.Context, client Client, bucket, key string, w io.Writer, progress ProgressFunc) (int64, error) {
+	r, err := client.GetObject(ctx, bucket, key)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	buf := make([]byte, downloadChunkSize)
+	var written int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return written, err
+		}
+		n, rerr := r.Read(buf)
+		if n > 0 {
+			if _, err := w.Write(buf[:n]); err != nil {
+				return written, err
+			}
+			written += int64(n)
+			progress.report(Progress{Bytes: written, Key: key})
+		}
+		if rerr == io.EOF {
+			progress.report(Progress{Items: 1, Bytes: written, Key: key})
+			return written, nil
+		}
+		if rerr != nil {
+			return written, rerr
+		}
+	}
+}
+
+// DownloadObjectParallel downloads the object of size bytes with up to concurrency ranged requests of partSize,
+// each written to its offset of w, which is faster than a single stream on high-latency links.
+// Every part is read only while the object has the ETag of the HEAD before the download, so an object replaced
+// during the download fails with ErrObjectChanged instead of mixing the contents.
+// Objects of a single part, and objects of clients which cannot pin the ETag, are streamed with DownloadObject.
+func DownloadObjectParallel(ctx context.Context, client Client, bucket, key string, size int64, w io.WriterAt, partSize int64, concurrency int, progress ProgressFunc) (int64, error) {
+	getter, ok := client.(PinnedRangeGetter)
+	if !ok || concurrency <= 1 || partSize <= 0 || size <= partSize {
+		return DownloadObject(ctx, client, bucket, key, &offsetWriter{w: w}, progress)
+	}
+	head, err := client.HeadObject(ctx, bucket, key)
+	if err != nil {
+		return 0, err
+	}
+	// the size may differ from the listing if the object was replaced since then
+	size = head.Size
+	if head.ETag == "" || size <= partSize {
+		return DownloadObject(ctx, client, bucket, key, &offsetWriter{w: w}, progress)
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	var written int64
+	var firstErr error
+	report := func(n int64) {
+		mu.Lock()
+		written += n
+		p := Progress{Bytes: written, Key: key}
+		mu.Unlock()
+		progress.report(p)
+	}
+
+	offsets := make(chan int64)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for offset := range offsets {
+				length := partSize
+				if offset+length > size {
+					length = size - offset
+				}
+				if err := downloadPart(ctx, getter, bucket, key, head.ETag, w, offset, length, report); err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+					cancel()
+					return
+				}
+			}
+		}()
+	}
+feed:
+	for offset := int64(0); offset < size; offset += partSize {
+		select {
+		case offsets <- offset:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(offsets)
+	wg.Wait()
+
+	if errors.Is(firstErr, ErrPinnedRangeNotSupported) {
+		// every part fails before writing anything
+		return DownloadObject(parent, client, bucket, key, &offsetWriter{w: w}, progress)
+	}
+	if firstErr != nil {
+		return written, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return written, err
+	}
+	progress.report(Progress{Items: 1, Bytes: written, Key: key})
+	return written, nil
+}
+
+func downloadPart(ctx context.Context, client PinnedRangeGetter, bucket, key, etag string, w io.WriterAt, offset, length int64, report func(n int64)) error {
+	r, err := client.GetObjectRangeIfMatch(ctx, bucket, key, etag, offset, length)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	buf := make([]byte, downloadChunkSize)
+	var pos int64
+	for pos < length {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, rerr := r.Read(buf)
+		if int64(n) > length-pos {
+			n = int(length - pos)
+		}
+		if n > 0 {
+			if _, err := w.WriteAt(buf[:n], offset+pos); err != nil {
+				return err
+			}
+			pos += int64(n)
+			report(int64(n))
+		}
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			return rerr
+		}
+	}
+	if pos < length {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+// offsetWriter writes sequentially to an io.WriterAt from the beginning.
+type offsetWriter struct {
+	w      io.WriterAt
+	offset int64
+}
+
+func (w *offsetWriter) Write(p []byte) (int, error) {
+	n, err := w.w.WriteAt(p, w.offset)
+	w.offset += int64(n)
+	return n, err
+}
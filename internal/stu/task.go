@@ -0,0 +1,116 @@
+package stu
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Task is a running background job.
+type Task struct {
+	ID      int
+	Name    string
+	Started time.Time
+}
+
+type runningTask struct {
+	Task
+	cancel context.CancelFunc
+}
+
+// TaskManager owns the background goroutines, so that all of them are canceled on shutdown
+// and the running ones can be listed.
+type TaskManager struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu     sync.Mutex
+	nextID int
+	tasks  map[int]*runningTask
+}
+
+func NewTaskManager() *TaskManager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &TaskManager{
+		ctx:    ctx,
+		cancel: cancel,
+		tasks:  make(map[int]*runningTask),
+	}
+}
+
+// Start registers a task running in a goroutine of the caller, e.g. a command of the UI framework.
+// The context is canceled by cancel or Shutdown, and done must be called when the task finishes.
+func (m *TaskManager) Start(name string) (context.Context, context.CancelFunc, func()) {
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.mu.Lock()
+	m.nextID++
+	id := m.nextID
+	m.tasks[id] = &runningTask{
+		Task:   Task{ID: id, Name: name, Started: time.Now()},
+		cancel: cancel,
+	}
+	m.wg.Add(1)
+	m.mu.Unlock()
+
+	var once sync.Once
+	done := func() {
+		once.Do(func() {
+			cancel()
+			m.mu.Lock()
+			delete(m.tasks, id)
+			m.mu.Unlock()
+			m.wg.Done()
+		})
+	}
+	return ctx, cancel, done
+}
+
+// Go runs fn in a new goroutine as a task and returns the function to cancel it.
+func (m *TaskManager) Go(name string, fn func(ctx context.Context)) context.CancelFunc {
+	ctx, cancel, done := m.Start(name)
+	go func() {
+		defer done()
+		fn(ctx)
+	}()
+	return cancel
+}
+
+// Tasks returns the running tasks in the order they were started.
+func (m *TaskManager) Tasks() []Task {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ret := make([]Task, 0, len(m.tasks))
+	for _, t := range m.tasks {
+		ret = append(ret, t.Task)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].ID < ret[j].ID })
+	return ret
+}
+
+// Cancel cancels the task with the id, which keeps being listed until it actually finishes.
+func (m *TaskManager) Cancel(id int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if t, ok := m.tasks[id]; ok {
+		t.cancel()
+	}
+}
+
+// Shutdown cancels all tasks and waits for them to finish up to timeout.
+// It returns false if some tasks are still running, e.g. blocked in a request which ignores the context.
+func (m *TaskManager) Shutdown(timeout time.Duration) bool {
+	m.cancel()
+	finished := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
@@ -0,0 +1,174 @@
+package stu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var ErrUploadNotSupported = errors.New("uploading is not supported by the client")
+
+// Uploader is implemented by clients which can upload objects.
+type Uploader interface {
+	// UploadObject uploads r of unknown length and returns the number of bytes uploaded.
+	UploadObject(ctx context.Context, bucket, key string, r io.Reader) (int64, error)
+}
+
+// UploadFile is a local file to upload, Rel is the slash-separated path which is appended to the prefix.
+type UploadFile struct {
+	Path string
+	Rel  string
+	Size int64
+}
+
+type UploadFailure struct {
+	Rel string
+	Err error
+}
+
+type UploadResult struct {
+	Uploaded int
+	Bytes    int64
+	Failed   []*UploadFailure
+}
+
+func (r *UploadResult) Progress() Progress {
+	return Progress{
+		Items:  r.Uploaded + len(r.Failed),
+		Failed: len(r.Failed),
+		Bytes:  r.Bytes,
+	}
+}
+
+// FileProgressFunc receives the bytes of a file read for uploading so far. It may be nil.
+type FileProgressFunc func(file *UploadFile, read int64)
+
+// PlanUpload returns the regular files under path keeping the hierarchy, or path itself if it is a file.
+// Symbolic links are not followed.
+func PlanUpload(path string) ([]*UploadFile, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return []*UploadFile{{Path: path, Rel: filepath.Base(path), Size: fi.Size()}}, nil
+	}
+	files := make([]*UploadFile, 0)
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(path, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, &UploadFile{Path: p, Rel: filepath.ToSlash(rel), Size: info.Size()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// UploadKeyWarnings returns the warnings of CheckKey for the keys of the files under prefix, one line per key.
+func UploadKeyWarnings(files []*UploadFile, prefix string) []string {
+	lines := make([]string, 0)
+	for _, file := range files {
+		key := prefix + file.Rel
+		if warnings, err := CheckKey(key); err == nil && len(warnings) > 0 {
+			lines = append(lines, fmt.Sprintf("%s: %s", key, strings.Join(warnings, ", ")))
+		}
+	}
+	return lines
+}
+
+// UploadFiles uploads the files under prefix of bucket with at most concurrency files in flight.
+// Files which fail, including the keys rejected by CheckKey, are reported in the result instead of stopping the upload.
+// progress is called after each file, and fileProgress while reading each file.
+func UploadFiles(ctx context.Context, client Uploader, files []*UploadFile, bucket, prefix string, concurrency int, progress ProgressFunc, fileProgress FileProgressFunc) (*UploadResult, error) {
+	result := &UploadResult{Failed: make([]*UploadFailure, 0)}
+	var mu sync.Mutex
+
+	ch := make(chan *UploadFile)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range ch {
+				n, err := uploadFile(ctx, client, file, bucket, prefix+file.Rel, fileProgress)
+				if ctx.Err() != nil {
+					return
+				}
+				mu.Lock()
+				if err != nil {
+					result.Failed = append(result.Failed, &UploadFailure{Rel: file.Rel, Err: err})
+				} else {
+					result.Uploaded++
+					result.Bytes += n
+				}
+				p := result.Progress()
+				mu.Unlock()
+				p.Key = file.Rel
+				progress.report(p)
+			}
+		}()
+	}
+
+loop:
+	for _, file := range files {
+		select {
+		case ch <- file:
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	close(ch)
+	wg.Wait()
+	return result, ctx.Err()
+}
+
+func uploadFile(ctx context.Context, client Uploader, file *UploadFile, bucket, key string, fileProgress FileProgressFunc) (int64, error) {
+	if _, err := CheckKey(key); err != nil {
+		return 0, err
+	}
+	f, err := os.Open(file.Path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if fileProgress != nil {
+		r = &progressReader{r: f, fn: func(n int64) { fileProgress(file, n) }}
+	}
+	return client.UploadObject(ctx, bucket, key, r)
+}
+
+type progressReader struct {
+	r    io.Reader
+	read int64
+	fn   func(read int64)
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.read += int64(n)
+		r.fn(r.read)
+	}
+	return n, err
+}
@@ -0,0 +1,27 @@
+package stu
+
+import "context"
+
+// walkFunc calls fn for each object processed by a batch operation.
+type walkFunc func(ctx context.Context, fn func(*ObjectItem) error) error
+
+func walkPrefix(client Client, bucket, prefix string) walkFunc {
+	return func(ctx context.Context, fn func(*ObjectItem) error) error {
+		return client.WalkObjects(ctx, bucket, prefix, fn)
+	}
+}
+
+// walkItems walks the given objects, such as the ones matched by a filter of a listing.
+func walkItems(items []*ObjectItem) walkFunc {
+	return func(ctx context.Context, fn func(*ObjectItem) error) error {
+		for _, item := range items {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+// +build !windows
+
+package tempfile
+
+import (
+	"os"
+	"syscall"
+)
+
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = p.Signal(syscall.Signal(0))
+	// EPERM means the process exists but is owned by another user
+	return err == nil || err == syscall.EPERM
+}
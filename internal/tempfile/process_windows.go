@@ -0,0 +1,18 @@
+//go:build windows
+// +build windows
+
+package tempfile
+
+import (
+	"os"
+)
+
+func processAlive(pid int) bool {
+	// FindProcess opens the process on Windows and fails if it does not exist
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
@@ -0,0 +1,98 @@
+package tempfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	dirPrefix = "stu-"
+)
+
+// Manager keeps temporary files of the process in a directory,
+// and tracks files created outside of it such as incomplete downloads,
+// so that all of them are removed on exit.
+type Manager struct {
+	dir  string
+	keep bool
+
+	mu      sync.Mutex
+	tracked map[string]bool
+}
+
+// New creates the temporary directory of the process.
+// If keep is true, Cleanup leaves the files for debugging.
+func New(keep bool) (*Manager, error) {
+	dir, err := os.MkdirTemp("", fmt.Sprintf("%s%d-", dirPrefix, os.Getpid()))
+	if err != nil {
+		return nil, err
+	}
+	return &Manager{
+		dir:     dir,
+		keep:    keep,
+		tracked: make(map[string]bool),
+	}, nil
+}
+
+// Create creates a new file whose name ends with name in the temporary directory.
+func (m *Manager) Create(name string) (*os.File, error) {
+	return os.CreateTemp(m.dir, "*-"+name)
+}
+
+// Track registers a file outside of the temporary directory to be removed on Cleanup.
+func (m *Manager) Track(path string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tracked[path] = true
+}
+
+// Untrack unregisters the file, for example when the download is completed.
+func (m *Manager) Untrack(path string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.tracked, path)
+}
+
+// Remove removes the file immediately.
+func (m *Manager) Remove(path string) error {
+	m.Untrack(path)
+	return os.Remove(path)
+}
+
+// Cleanup removes the temporary directory and tracked files.
+func (m *Manager) Cleanup() error {
+	if m.keep {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for path := range m.tracked {
+		os.Remove(path)
+	}
+	m.tracked = make(map[string]bool)
+	return os.RemoveAll(m.dir)
+}
+
+// Sweep removes temporary directories left by processes which exited without cleanup.
+func Sweep() error {
+	paths, err := filepath.Glob(filepath.Join(os.TempDir(), dirPrefix+"*-*"))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		name := strings.TrimPrefix(filepath.Base(path), dirPrefix)
+		pid, err := strconv.Atoi(name[:strings.Index(name, "-")])
+		if err != nil || pid == os.Getpid() || processAlive(pid) {
+			continue
+		}
+		if fi, err := os.Stat(path); err != nil || !fi.IsDir() {
+			continue
+		}
+		os.RemoveAll(path)
+	}
+	return nil
+}
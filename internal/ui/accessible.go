@@ -0,0 +1,74 @@
+package ui
+
+import (
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+var (
+	asciiBorder = lipgloss.Border{
+		Top:         "-",
+		Bottom:      "-",
+		Left:        "|",
+		Right:       "|",
+		TopLeft:     "+",
+		TopRight:    "+",
+		BottomLeft:  "+",
+		BottomRight: "+",
+	}
+)
+
+// accessibleStyles replaces styles that rely on color or box-drawing characters
+// with high-contrast styles and textual markers.
+func accessibleStyles(s uiStyles) uiStyles {
+	s.list = s.list.Copy().
+		BorderStyle(asciiBorder).
+		UnsetBorderForeground()
+	s.selectedItem = s.selectedItem.Copy().
+		UnsetForeground().
+		Bold(true).
+		Reverse(true)
+	s.err = s.err.Copy().
+		UnsetForeground().
+		Bold(true)
+	s.warning = s.warning.Copy().
+		UnsetForeground().
+		Bold(true)
+	s.histogramBar = s.histogramBar.Copy().
+		UnsetForeground()
+	s.histogramBarChar = "#"
+	return s
+}
+
+func (c *uiConfig) accessibleMarker(item list.Item) string {
+	if !c.accessible {
+		return ""
+	}
+	if i, ok := item.(*stu.ObjectItem); ok && i.Dir {
+		return "[DIR] "
+	}
+	return ""
+}
+
+func (c *uiConfig) selectedMarker() string {
+	if c.accessible {
+		return "[SEL] "
+	}
+	return "> "
+}
+
+func (c *uiConfig) viewError(err error) string {
+	s := err.Error()
+	if c.accessible {
+		s = "[ERROR] " + s
+	}
+	return c.styles.err.Render(s)
+}
+
+func (c *uiConfig) viewWarning(s string) string {
+	if c.accessible {
+		s = "[WARNING] " + s
+	}
+	return c.styles.warning.Render(s)
+}
@@ -0,0 +1,121 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+var aclMenu = []string{
+	"  p  private      only the owner of the bucket can access the object",
+	"  r  public-read  ANYONE on the internet can read the object",
+	"",
+	"Press p or r to select, esc to cancel.",
+}
+
+var aclPublicWarning = []string{
+	"The object will be readable by anyone on the internet without authentication.",
+	"",
+	"  - the object can be downloaded by its URL, and may be indexed or cached by third parties",
+	"  - Block Public Access settings of the bucket or the account may still deny the access",
+	"  - set the ACL to private again to stop exposing the object",
+	"",
+	"Press y to make the object public, n or esc to cancel.",
+}
+
+type aclState struct {
+	target *stu.ObjectItem
+	// true while confirming public-read
+	confirmPublic bool
+}
+
+type aclDoneMsg struct {
+	item *stu.ObjectItem
+	acl  string
+	err  error
+}
+
+func newACLState() *aclState {
+	return &aclState{}
+}
+
+func (m model) openACLMenu() (tea.Model, tea.Cmd) {
+	item, ok := m.selectedFile()
+	if !ok {
+		return m, nil
+	}
+	m.acl.target = item
+	m.acl.confirmPublic = false
+	m.page = pageACLMenu
+	return m, nil
+}
+
+func putObjectACL(tasks *stu.TaskManager, client stu.Client, audit *stu.AuditLog, bucket string, item *stu.ObjectItem, acl string) tea.Cmd {
+	return taskCmd(tasks, fmt.Sprintf("set ACL %s %s/%s", acl, bucket, item.ObjectKey()), func(ctx context.Context) tea.Msg {
+		err := stu.ErrACLsNotSupported
+		if s, ok := client.(stu.ACLSetter); ok {
+			err = s.PutObjectACL(ctx, bucket, item.ObjectKey(), acl)
+		}
+		if aerr := audit.Record("put-object-acl", bucket, item.ObjectKey(), acl, err); aerr != nil && err == nil {
+			err = fmt.Errorf("ACL was set but failed to write the audit log: %w", aerr)
+		}
+		return aclDoneMsg{item: item, acl: acl, err: err}
+	})
+}
+
+func (m model) setACL(acl string) (tea.Model, tea.Cmd) {
+	m.page = pageList
+	m.status = fmt.Sprintf("setting ACL of %s to %s...", m.acl.target.Filename(), acl)
+	return m, putObjectACL(m.tasks, m.client, m.audit, m.bucket, m.acl.target, acl)
+}
+
+func (m model) updateACLMsg(msg aclDoneMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.status = m.ui.viewError(msg.err)
+		return m, nil
+	}
+	m.status = fmt.Sprintf("set ACL of %s to %s", msg.item.Filename(), msg.acl)
+	return m, nil
+}
+
+func (m model) updateACLMenu(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.acl
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		if s.confirmPublic {
+			switch msg.String() {
+			case "y":
+				return m.setACL(stu.ACLPublicRead)
+			case "n", "esc", "backspace", "ctrl+h":
+				s.confirmPublic = false
+				m.page = pageList
+				return m, nil
+			}
+			return m, nil
+		}
+		switch msg.String() {
+		case "p":
+			return m.setACL(stu.ACLPrivate)
+		case "r":
+			s.confirmPublic = true
+			return m, nil
+		case "esc", "backspace", "ctrl+h":
+			m.page = pageList
+			return m, nil
+		}
+	}
+	return m, nil
+}
+
+func (m model) viewACLMenu() string {
+	s := m.acl
+	bc := breadcrumbStyle.Render(fmt.Sprintf("%s : Set ACL of %s", m.viewBreadcrumb(), s.target.Filename()))
+	if s.confirmPublic {
+		// the first line is the warning
+		w := m.ui.viewWarning(aclPublicWarning[0]) + "\n" + strings.Join(aclPublicWarning[1:], "\n")
+		return bc + m.ui.styles.list.Render(itemStyle.Render(w))
+	}
+	return bc + m.ui.styles.list.Render(itemStyle.Render(strings.Join(aclMenu, "\n")))
+}
@@ -1,47 +1,156 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/lusingander/stu/internal/clipboard"
+	"github.com/lusingander/stu/internal/config"
+	"github.com/lusingander/stu/internal/preview"
 	"github.com/lusingander/stu/internal/stu"
+	"github.com/lusingander/stu/internal/tempfile"
 )
 
 var (
-	listStyle = lipgloss.NewStyle().
-			MarginTop(1).
-			BorderStyle(lipgloss.NormalBorder()).
-			BorderForeground(lipgloss.Color("63")).
-			BorderTop(true)
-
 	itemStyle = lipgloss.NewStyle().
 			PaddingLeft(2)
 
-	selectedItemStyle = lipgloss.NewStyle().
-				PaddingLeft(0).
-				Foreground(lipgloss.Color("170"))
+	emptyStyle = lipgloss.NewStyle().
+			PaddingLeft(2).
+			Foreground(lipgloss.Color("244"))
 
 	breadcrumbStyle = lipgloss.NewStyle().
 			PaddingLeft(2).
 			Height(1)
 )
 
+// uiStyles are the styles replaced in the accessible mode, the others are shared by all models.
+type uiStyles struct {
+	list             lipgloss.Style
+	selectedItem     lipgloss.Style
+	err              lipgloss.Style
+	warning          lipgloss.Style
+	histogramBar     lipgloss.Style
+	histogramBarChar string
+}
+
+func newStyles() uiStyles {
+	return uiStyles{
+		list: lipgloss.NewStyle().
+			MarginTop(1).
+			BorderStyle(lipgloss.NormalBorder()).
+			BorderForeground(lipgloss.Color("63")).
+			BorderTop(true),
+		selectedItem: lipgloss.NewStyle().
+			PaddingLeft(0).
+			Foreground(lipgloss.Color("170")),
+		err: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("160")),
+		warning: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("214")),
+		histogramBar: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("63")),
+		histogramBarChar: "█",
+	}
+}
+
+type page int
+
+const (
+	pageList page = iota
+	pageSearchInput
+	pageSearchResult
+	pageDateFilter
+	pageStats
+	pageReportMenu
+	pageReport
+	pageExport
+	pageHookMenu
+	pageTouchConfirm
+	pageDeleteConfirm
+	pageDeletePrefixConfirm
+	pageDeletePrefix
+	pageUploadInput
+	pageUpload
+	pageRenameInput
+	pageRenamePreview
+	pageCopyInput
+	pageCopy
+	pageDetail
+	pagePreview
+	pageTasks
+	pageHelp
+	pageProfiles
+	pageACLMenu
+	pageProperties
+	pageCredentials
+)
+
 type model struct {
-	list list.Model
+	list   list.Model
+	page   page
+	width  int
+	height int
 
+	ui          *uiConfig
 	client      stu.Client
+	clipboard   clipboard.Clipboard
+	status      string
 	bucket      string
 	breadcrumbs []*stu.ObjectItem
+	showMarkers bool
+	sort        listSort
+	filter      *stu.BucketFilter
+	showAll     bool
+	download    config.DownloadConfig
+
+	search       *searchState
+	dateFilter   *dateFilterState
+	stats        *statsState
+	report       *reportState
+	export       *exportState
+	hook         *hookState
+	touch        *touchState
+	delete       *deleteState
+	deletePrefix *deletePrefixState
+	upload       *uploadState
+	acl          *aclState
+	rename       *renameState
+	copy         *copyState
+	detail       *detailState
+	preview      *previewState
+	metrics      *metricsState
+	popup        popup
+	toast        *toastState
+	tasksPage    *tasksState
+	help         *helpState
+	profiles     *profilesState
+	properties   *propertiesState
+	credentials  *credentialsState
+
+	tasks    *stu.TaskManager
+	audit    *stu.AuditLog
+	enricher *stu.Enricher
+	objCache *stu.ObjectCache
+	rendered *preview.Cache
+
+	temp     *tempfile.Manager
+	terminal *terminal
 }
 
 type listItem interface {
 	Text() string
 }
 
-type itemDelegate struct{}
+type itemDelegate struct {
+	ui *uiConfig
+}
 
 func (d itemDelegate) Height() int {
 	return 1
@@ -61,12 +170,20 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, item list.Ite
 		return
 	}
 
-	str := i.Text()
+	str := d.ui.accessibleMarker(item) + i.Text()
+	switch i := item.(type) {
+	case *stu.ObjectItem:
+		str += viewHead(i.Head)
+	case *stu.BucketItem:
+		if d.ui.bucketMetrics {
+			str += d.ui.viewBucketMetrics(m, i)
+		}
+	}
 
 	fn := itemStyle.Render
 	if index == m.Index() {
 		fn = func(s string) string {
-			return selectedItemStyle.Render("> " + s)
+			return d.ui.styles.selectedItem.Render(d.ui.selectedMarker() + s)
 		}
 	}
 
@@ -74,63 +191,274 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, item list.Ite
 }
 
 func (m model) Init() tea.Cmd {
+	if m.enricher != nil {
+		return waitEnrichMsg(m.enricher.Results())
+	}
 	return nil
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.list.SetSize(msg.Width, msg.Height-3)
+		m.search.setSize(msg.Width, msg.Height-3)
+		m.report.setSize(msg.Width, msg.Height-3)
+		m.hook.setSize(msg.Width, msg.Height-3)
+		m.rename.setSize(msg.Width, msg.Height-3)
+		m.copy.setSize(msg.Width, msg.Height-3)
+		m.deletePrefix.setSize(msg.Width, msg.Height-3)
+		m.upload.setSize(msg.Width, msg.Height-3)
+		m.preview.setSize(msg.Width, msg.Height-3)
+		m.tasksPage.setSize(msg.Width, msg.Height-3)
+		m.help.setSize(msg.Width, msg.Height-3)
+		m.profiles.setSize(msg.Width, msg.Height-3)
+		m.properties.setSize(msg.Width, msg.Height-3)
+	case searchResultMsg, searchProgressMsg, searchDoneMsg:
+		return m.updateSearchMsg(msg)
+	case statsProgressMsg, statsDoneMsg:
+		return m.updateStatsMsg(msg)
+	case reportProgressMsg, reportDoneMsg:
+		return m.updateReportMsg(msg)
+	case hookDownloadedMsg:
+		return m.updateHookMsg(msg)
+	case touchDoneMsg:
+		return m.updateTouchMsg(msg)
+	case deleteDoneMsg:
+		return m.updateDeleteMsg(msg)
+	case deletePrefixCountMsg, deletePrefixProgressMsg, deletePrefixDoneMsg:
+		return m.updateDeletePrefixMsg(msg)
+	case uploadPlanMsg, uploadFileMsg, uploadProgressMsg, uploadDoneMsg:
+		return m.updateUploadMsg(msg)
+	case aclDoneMsg:
+		return m.updateACLMsg(msg)
+	case propertiesMsg:
+		return m.updatePropertiesMsg(msg)
+	case credentialsMsg:
+		return m.updateCredentialsMsg(msg)
+	case tasksRefreshMsg:
+		return m.updateTasksMsg(msg)
+	case toastExpiredMsg:
+		return m.updateToastMsg(msg)
+	case clipboardDoneMsg:
+		return m.updateClipboardMsg(msg)
+	case downloadDoneMsg:
+		return m.updateDownloadMsg(msg)
+	case downloadMatchedDoneMsg:
+		return m.updateDownloadMatchedMsg(msg)
+	case browserOpenedMsg:
+		return m.updateBrowserMsg(msg)
+	case metricsProgressMsg, metricsDoneMsg:
+		return m.updateMetricsMsg(msg)
+	case detailHeadMsg:
+		return m.updateDetailMsg(msg)
+	case detailVerifyMsg:
+		return m.updateDetailVerifyMsg(msg)
+	case detailEventsMsg:
+		return m.updateDetailEventsMsg(msg)
+	case previewLoadedMsg, previewFollowMsg, previewAppendedMsg:
+		return m.updatePreviewMsg(msg)
+	case renamePreviewMsg, renameProgressMsg, renameDoneMsg:
+		return m.updateRenameMsg(msg)
+	case copyProgressMsg, copyDoneMsg:
+		return m.updateCopyMsg(msg)
+	case controlMsg:
+		return m.updateControlMsg(msg)
+	case enrichMsg:
+		return m.updateEnrichMsg(msg)
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+z" && suspendSupported && m.terminal != nil {
+			return m.suspend()
+		}
+	}
+
+	if m.popup != nil {
+		return m.popup.update(m, msg)
+	}
+
+	switch m.page {
+	case pageSearchInput:
+		return m.updateSearchInput(msg)
+	case pageSearchResult:
+		return m.updateSearchResult(msg)
+	case pageDateFilter:
+		return m.updateDateFilter(msg)
+	case pageStats:
+		return m.updateStats(msg)
+	case pageReportMenu:
+		return m.updateReportMenu(msg)
+	case pageReport:
+		return m.updateReport(msg)
+	case pageExport:
+		return m.updateExport(msg)
+	case pageHookMenu:
+		return m.updateHookMenu(msg)
+	case pageTouchConfirm:
+		return m.updateTouchConfirm(msg)
+	case pageDeleteConfirm:
+		return m.updateDeleteConfirm(msg)
+	case pageDeletePrefixConfirm:
+		return m.updateDeletePrefixConfirm(msg)
+	case pageDeletePrefix:
+		return m.updateDeletePrefix(msg)
+	case pageUploadInput:
+		return m.updateUploadInput(msg)
+	case pageUpload:
+		return m.updateUpload(msg)
+	case pageACLMenu:
+		return m.updateACLMenu(msg)
+	case pageRenameInput:
+		return m.updateRenameInput(msg)
+	case pageRenamePreview:
+		return m.updateRenamePreview(msg)
+	case pageCopyInput:
+		return m.updateCopyInput(msg)
+	case pageCopy:
+		return m.updateCopy(msg)
+	case pageDetail:
+		return m.updateDetail(msg)
+	case pagePreview:
+		return m.updatePreview(msg)
+	case pageTasks:
+		return m.updateTasks(msg)
+	case pageHelp:
+		return m.updateHelp(msg)
+	case pageProfiles:
+		return m.updateProfiles(msg)
+	case pageProperties:
+		return m.updateProperties(msg)
+	case pageCredentials:
+		return m.updateCredentials(msg)
+	}
+	return m.updateList(msg)
+}
+
+func (m *model) resetList(items []list.Item) {
+	m.list.SetItems(items)
+	m.list.ResetSelected()
+	m.list.ResetFilter()
+	m.dateFilter.reset()
+	m.enrichVisible()
+}
+
+func (m model) updateList(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "enter":
+		if m.list.SettingFilter() {
+			break
+		}
+		m.status = ""
+		k := m.ui.keys.List
+		inBucket := m.bucket != ""
+		switch {
+		case key.Matches(msg, k.CopyName):
+			return m.copySelected(copyName)
+		case key.Matches(msg, k.CopyURI):
+			return m.copySelected(copyURI)
+		case key.Matches(msg, k.CopyARN):
+			return m.copySelected(copyARN)
+		case key.Matches(msg, k.Help):
+			return m.openHelp()
+		case inBucket && key.Matches(msg, k.Search):
+			return m.openSearchInput()
+		case inBucket && key.Matches(msg, k.DateFilter):
+			return m.openDateFilter()
+		case inBucket && key.Matches(msg, k.Stats):
+			return m.openStats()
+		case inBucket && key.Matches(msg, k.Report):
+			return m.openReportMenu()
+		case key.Matches(msg, k.Hooks):
+			return m.openHookMenu()
+		case !inBucket && key.Matches(msg, k.Properties):
+			return m.openProperties()
+		case !inBucket && key.Matches(msg, k.ToggleHidden):
+			return m.toggleHiddenBuckets()
+		case inBucket && key.Matches(msg, k.ToggleMarkers):
+			return m.toggleFolderMarkers()
+		case inBucket && key.Matches(msg, k.Sort):
+			return m.toggleSort()
+		case key.Matches(msg, k.Browser):
+			return m.openInBrowser()
+		case key.Matches(msg, k.Download):
+			return m.openDownloadInput()
+		case key.Matches(msg, k.Tasks):
+			return m.openTasks()
+		case key.Matches(msg, k.Profiles):
+			return m.openProfiles()
+		case key.Matches(msg, k.Credentials):
+			return m.openCredentials()
+		case key.Matches(msg, k.Preview):
+			return m.openPreview()
+		case key.Matches(msg, k.PurgePreviews):
+			return m.purgePreviews(), nil
+		case !inBucket && key.Matches(msg, k.Metrics):
+			return m.refreshBucketMetrics()
+		case key.Matches(msg, k.Touch), key.Matches(msg, k.Rename), key.Matches(msg, k.Copy), key.Matches(msg, k.ACL), key.Matches(msg, k.Delete), key.Matches(msg, k.Upload):
+			if m.offline() {
+				m.status = stu.ErrOffline.Error()
+				return m, nil
+			}
+			// copying from a read-only bucket is allowed
+			if !key.Matches(msg, k.Copy) && m.bucketSettings(m.bucket).ReadOnly {
+				m.status = stu.ErrReadOnly.Error()
+				return m, nil
+			}
+			switch {
+			case key.Matches(msg, k.Touch):
+				return m.openTouchConfirm()
+			case key.Matches(msg, k.Rename):
+				return m.openRenameInput()
+			case key.Matches(msg, k.ACL):
+				return m.openACLMenu()
+			case key.Matches(msg, k.Delete):
+				return m.openDeleteConfirm()
+			case key.Matches(msg, k.Upload):
+				return m.openUploadInput()
+			default:
+				return m.openCopyInput()
+			}
+		case key.Matches(msg, k.ClearDates):
+			if m.dateFilter.applied != nil && m.list.FilterState() == list.Unfiltered {
+				return m.clearDateFilter(), nil
+			}
+		default:
+			if hook, ok := m.hook.find(msg.String()); ok {
+				if item, ok := m.selectedFile(); ok {
+					return m.runHook(hook, item)
+				}
+			}
+		case key.Matches(msg, k.Open):
 			switch i := m.list.SelectedItem().(type) {
 			case *stu.BucketItem:
 				bucket := i.BucketName()
-				objs, err := m.client.ListObjects(bucket, "")
+				items, err := m.listObjects(bucket, "")
 				if err != nil {
-					return m, tea.Quit
-				}
-				items := make([]list.Item, len(objs))
-				for i, obj := range objs {
-					items[i] = obj
+					return m.listFailed(err)
 				}
-				m.list.SetItems(items)
-				m.list.ResetSelected()
-				m.list.ResetFilter()
+				m.resetList(items)
 				m.bucket = bucket
 			case *stu.ObjectItem:
 				if i.Dir {
-					objs, err := m.client.ListObjects(m.bucket, i.ObjectKey())
+					items, err := m.listObjects(m.bucket, i.ObjectKey())
 					if err != nil {
-						return m, tea.Quit
+						return m.listFailed(err)
 					}
-					items := make([]list.Item, len(objs))
-					for i, obj := range objs {
-						items[i] = obj
-					}
-					m.list.SetItems(items)
-					m.list.ResetSelected()
-					m.list.ResetFilter()
+					m.resetList(items)
 					m.breadcrumbs = append(m.breadcrumbs, i)
+				} else {
+					return m.openDetail(i)
 				}
 			}
-		case "backspace", "ctrl+h":
-			switch m.list.SelectedItem().(type) {
-			case *stu.BucketItem:
-				// do nothing
-			case *stu.ObjectItem:
+		case key.Matches(msg, k.Back):
+			// check the location instead of the selected item, which is nil in an empty list
+			if m.bucket != "" {
 				bl := len(m.breadcrumbs)
 				if bl == 0 {
-					buckets, err := m.client.ListBuckets()
+					items, err := m.listBuckets()
 					if err != nil {
-						return m, tea.Quit
-					}
-					items := make([]list.Item, len(buckets))
-					for i, bucket := range buckets {
-						items[i] = bucket
+						return m.listFailed(err)
 					}
-					m.list.SetItems(items)
-					m.list.ResetSelected()
-					m.list.ResetFilter()
+					m.resetList(items)
 					m.bucket = ""
 				} else {
 					var key string
@@ -139,30 +467,106 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					} else {
 						key = m.breadcrumbs[bl-2].ObjectKey()
 					}
-					objs, err := m.client.ListObjects(m.bucket, key)
+					items, err := m.listObjects(m.bucket, key)
 					if err != nil {
-						return m, tea.Quit
+						return m.listFailed(err)
 					}
-					items := make([]list.Item, len(objs))
-					for i, obj := range objs {
-						items[i] = obj
-					}
-					m.list.SetItems(items)
-					m.list.ResetSelected()
-					m.list.ResetFilter()
+					m.resetList(items)
 					m.breadcrumbs = m.breadcrumbs[:bl-1]
 				}
 			}
 		}
-	case tea.WindowSizeMsg:
-		m.list.SetSize(msg.Width, msg.Height-3)
 	}
 
 	var cmd tea.Cmd
 	m.list, cmd = m.list.Update(msg)
+	m.enrichVisible()
 	return m, cmd
 }
 
+func (m model) listObjects(bucket, prefix string) ([]list.Item, error) {
+	objs, err := m.client.ListObjects(bucket, prefix)
+	if err != nil {
+		return nil, err
+	}
+	m.objCache.Observe(bucket, objs)
+	items := make([]list.Item, 0, len(objs))
+	for _, obj := range m.sort.apply(objs) {
+		if obj.FolderMarker() && !m.showMarkers {
+			continue
+		}
+		items = append(items, obj)
+	}
+	return items, nil
+}
+
+func (m model) purgePreviews() model {
+	m.objCache.PurgePreviews()
+	freed := m.rendered.Purge()
+	m.status = fmt.Sprintf("purged cached previews (%s)", m.ui.format.size(int64(freed)))
+	return m
+}
+
+// listBuckets lists the buckets hidden by the filter of the connection only if showAll.
+func (m model) listBuckets() ([]list.Item, error) {
+	buckets, err := m.client.ListBuckets()
+	if err != nil {
+		return nil, err
+	}
+	m.fillBucketMetrics(buckets)
+	items := make([]list.Item, 0, len(buckets))
+	for _, bucket := range buckets {
+		if m.showAll || m.filter.Visible(bucket.BucketName()) {
+			items = append(items, bucket)
+		}
+	}
+	return items, nil
+}
+
+func (m model) toggleHiddenBuckets() (tea.Model, tea.Cmd) {
+	m.showAll = !m.showAll
+	items, err := m.listBuckets()
+	if err != nil {
+		m.status = m.ui.viewError(err)
+		return m, nil
+	}
+	if m.showAll {
+		m.status = "hidden buckets: shown"
+	} else {
+		m.status = "hidden buckets: hidden"
+	}
+	m.resetList(items)
+	return m, nil
+}
+
+func (m model) toggleFolderMarkers() (tea.Model, tea.Cmd) {
+	m.showMarkers = !m.showMarkers
+	if m.showMarkers {
+		m.status = "folder markers: shown"
+	} else {
+		m.status = "folder markers: hidden"
+	}
+	return m.reloadList()
+}
+
+// reloadList lists the current location again and keeps the cursor position.
+func (m model) reloadList() (tea.Model, tea.Cmd) {
+	if m.bucket == "" {
+		return m, nil
+	}
+	items, err := m.listObjects(m.bucket, m.currentPrefix())
+	if err != nil {
+		m.status = m.ui.viewError(err)
+		return m, nil
+	}
+	idx := m.list.Index()
+	m.resetList(items)
+	if idx < len(items) {
+		m.list.Select(idx)
+	}
+	return m, nil
+}
+
 func (m model) viewBreadcrumb() string {
 	sep := " > "
 	s := "STU"
@@ -177,36 +581,325 @@ func (m model) viewBreadcrumb() string {
 	return s
 }
 
+func (m model) currentPrefix() string {
+	if bl := len(m.breadcrumbs); bl > 0 {
+		return m.breadcrumbs[bl-1].ObjectKey()
+	}
+	return ""
+}
+
 func (m model) View() string {
-	bc := breadcrumbStyle.Render(m.viewBreadcrumb())
-	l := listStyle.Render(m.list.View())
-	return bc + l
+	// clamp to the window size so that the output does not depend on the terminal
+	return lipgloss.NewStyle().
+		MaxWidth(m.width).
+		MaxHeight(m.height).
+		Render(m.ui.overlayToast(m.viewPopup(), m.toast.text, m.width, m.height))
+}
+
+func (m model) viewPopup() string {
+	if m.popup == nil {
+		return m.viewPage()
+	}
+	return m.ui.overlayPopup(m.viewPage(), m.popup, m.width)
+}
+
+func (m model) viewPage() string {
+	switch m.page {
+	case pageSearchInput, pageSearchResult:
+		return m.viewSearch()
+	case pageStats:
+		return m.viewStats()
+	case pageReportMenu, pageReport:
+		return m.viewReport()
+	case pageExport:
+		return m.viewExport()
+	case pageHookMenu:
+		return m.viewHookMenu()
+	case pageTouchConfirm:
+		return m.viewTouchConfirm()
+	case pageDeleteConfirm:
+		return m.viewDeleteConfirm()
+	case pageDeletePrefixConfirm:
+		return m.viewDeletePrefixConfirm()
+	case pageDeletePrefix:
+		return m.viewDeletePrefix()
+	case pageUploadInput, pageUpload:
+		return m.viewUpload()
+	case pageACLMenu:
+		return m.viewACLMenu()
+	case pageRenameInput, pageRenamePreview:
+		return m.viewRename()
+	case pageCopyInput, pageCopy:
+		return m.viewCopy()
+	case pageDetail:
+		return m.viewDetail()
+	case pagePreview:
+		return m.viewPreview()
+	case pageTasks:
+		return m.viewTasks()
+	case pageHelp:
+		return m.viewHelp()
+	case pageProfiles:
+		return m.viewProfiles()
+	case pageProperties:
+		return m.viewProperties()
+	case pageCredentials:
+		return m.viewCredentials()
+	}
+	bc := m.viewBreadcrumb()
+	if cachedAt := m.viewCachedAt(); cachedAt != "" {
+		bc += " : " + cachedAt
+	}
+	if status := m.viewDateFilterStatus(); status != "" {
+		bc += " : " + status
+	}
+	if filter := m.viewListFilter(); filter != "" {
+		bc += " : " + filter
+	}
+	if order := m.sort.String(); order != "" && m.bucket != "" {
+		bc += " : " + order
+	}
+	if m.status != "" {
+		bc += " : " + m.status
+	}
+	l := m.ui.styles.list.Render(m.viewList())
+	return breadcrumbStyle.Render(bc) + l
+}
+
+func (m model) viewListFilter() string {
+	switch m.list.FilterState() {
+	case list.Filtering:
+		return m.list.FilterInput.View()
+	case list.FilterApplied:
+		return fmt.Sprintf("filter: %s (%d/%d)", m.list.FilterValue(), len(m.list.VisibleItems()), len(m.list.Items()))
+	}
+	return ""
+}
+
+func (m model) viewList() string {
+	if len(m.list.Items()) > 0 {
+		return m.list.View()
+	}
+	var msg string
+	switch {
+	case m.bucket == "":
+		msg = "No buckets found"
+	case len(m.breadcrumbs) == 0:
+		msg = "This bucket is empty (press backspace to go back)"
+	default:
+		msg = "No objects under this prefix (press backspace to go back)"
+	}
+	return emptyStyle.Height(m.list.Height()).Render(msg)
+}
+
+// listFailed quits as the listing cannot be shown, except for locations which are not cached in offline mode.
+func (m model) listFailed(err error) (tea.Model, tea.Cmd) {
+	if errors.Is(err, stu.ErrOffline) {
+		m.status = err.Error()
+		return m, nil
+	}
+	return m, tea.Quit
+}
+
+func (m model) offline() bool {
+	c, ok := m.client.(interface{ Offline() bool })
+	return ok && c.Offline()
+}
+
+func (m model) bucketSettings(bucket string) *stu.BucketSettings {
+	if c, ok := m.client.(interface {
+		BucketSettings(bucket string) *stu.BucketSettings
+	}); ok {
+		return c.BucketSettings(bucket)
+	}
+	return &stu.BucketSettings{}
+}
+
+// viewCachedAt shows when the current listing was cached in offline mode.
+func (m model) viewCachedAt() string {
+	c, ok := m.client.(interface {
+		CachedAt(bucket, prefix string) (time.Time, bool)
+	})
+	if !ok {
+		return ""
+	}
+	if t, ok := c.CachedAt(m.bucket, m.currentPrefix()); ok {
+		return "offline, cached at " + m.ui.format.time(t)
+	}
+	return "offline"
+}
+
+// viewThrottled shows that requests are waiting for the rate limit.
+func (m model) viewThrottled() string {
+	if c, ok := m.client.(interface{ Throttled() bool }); ok && c.Throttled() {
+		return " (throttled)"
+	}
+	return ""
+}
+
+type copyTarget int
+
+const (
+	copyName copyTarget = iota
+	copyURI
+	copyARN
+)
+
+// copySelected copies the name, the S3 URI or the ARN of the selected item.
+func (m model) copySelected(target copyTarget) (tea.Model, tea.Cmd) {
+	var name, path string
+	switch i := m.list.SelectedItem().(type) {
+	case *stu.BucketItem:
+		name, path = i.BucketName(), i.BucketName()
+	case *stu.ObjectItem:
+		name, path = i.ObjectKey(), m.bucket+"/"+i.ObjectKey()
+	default:
+		return m, nil
+	}
+	text := name
+	switch target {
+	case copyURI:
+		text = "s3://" + path
+	case copyARN:
+		text = "arn:aws:s3:::" + path
+	}
+	return m, copyToClipboard(m.clipboard, text, fmt.Sprintf("copied: %s", text))
 }
 
-func Start(client stu.Client) error {
+func newList(ui *uiConfig, items []list.Item) list.Model {
+	l := list.NewModel(items, itemDelegate{ui: ui}, 0, 0)
+	l.SetShowTitle(false)
+	l.Styles.TitleBar = lipgloss.Style{} // clear style...
+	l.Styles.Title = lipgloss.Style{}
+	l.SetShowStatusBar(false)
+	return l
+}
 
-	buckets, err := client.ListBuckets()
+func newModel(client stu.Client, cfg *config.Config, switcher ProfileSwitcher) (model, error) {
+	ui, err := newUIConfig(cfg)
 	if err != nil {
-		return err
+		return model{}, err
+	}
+	if c := cfg.UI.DeleteConfirm; c != deleteConfirmKey && c != deleteConfirmName {
+		return model{}, fmt.Errorf("invalid delete_confirm: %s", c)
+	}
+	if err := preview.LoadSchemas(cfg.Preview.Schemas); err != nil {
+		return model{}, err
+	}
+
+	cb, err := clipboard.New(cfg.Clipboard.Mode, cfg.Terminal.Multiplexer, termOutput)
+	if err != nil {
+		return model{}, err
+	}
+
+	conn, err := cfg.CurrentConnection()
+	if err != nil {
+		return model{}, err
 	}
-	items := make([]list.Item, len(buckets))
-	for i, bucket := range buckets {
-		items[i] = bucket
+	filter, err := stu.NewBucketFilter(conn.Buckets.Include, conn.Buckets.Exclude)
+	if err != nil {
+		return model{}, err
 	}
 
 	m := model{
-		list:        list.NewModel(items, itemDelegate{}, 0, 0),
-		client:      client,
-		bucket:      "",
-		breadcrumbs: make([]*stu.ObjectItem, 0),
+		ui:           ui,
+		client:       client,
+		clipboard:    cb,
+		bucket:       "",
+		breadcrumbs:  make([]*stu.ObjectItem, 0),
+		showMarkers:  cfg.UI.ShowFolderMarkers,
+		search:       newSearchState(ui),
+		dateFilter:   newDateFilterState(),
+		stats:        newStatsState(),
+		report:       newReportState(ui),
+		export:       newExportState(),
+		hook:         newHookState(ui, cfg.Hooks),
+		touch:        newTouchState(),
+		delete:       newDeleteState(cfg.UI.DeleteConfirm),
+		deletePrefix: newDeletePrefixState(ui),
+		upload:       newUploadState(ui, cfg.Upload),
+		acl:          newACLState(),
+		rename:       newRenameState(ui),
+		copy:         newCopyState(ui),
+		detail:       newDetailState(),
+		preview:      newPreviewState(cfg.Preview),
+		objCache:     stu.NewObjectCache(objectCacheSize),
+		rendered:     preview.NewCache(renderCacheBytes),
+		metrics:      newMetricsState(),
+		toast:        &toastState{},
+		tasks:        stu.NewTaskManager(),
+		audit:        stu.NewAuditLog(cfg.Audit.Path),
+		tasksPage:    newTasksState(ui),
+		help:         newHelpState(),
+		profiles:     newProfilesState(ui, switcher),
+		properties:   newPropertiesState(),
+		credentials:  newCredentialsState(cfg.Credentials),
+		filter:       filter,
+		download:     cfg.Download,
+	}
+	items, err := m.listBuckets()
+	if err != nil {
+		return model{}, err
+	}
+	m.list = newList(ui, items)
+	// the filter is shown in the breadcrumb instead
+	m.list.SetShowFilter(false)
+	m.list.KeyMap.Filter = m.ui.keys.List.Filter
+	if cfg.UI.Enrich && !m.offline() {
+		m.enricher = stu.NewEnricher(m.tasks, client, enrichConcurrency)
+	}
+	return m, nil
+}
+
+// Start runs the program, switcher is nil if the profile cannot be switched.
+func Start(client stu.Client, cfg *config.Config, switcher ProfileSwitcher) error {
+	m, err := newModel(client, cfg, switcher)
+	if err != nil {
+		return err
+	}
+
+	if !cfg.Temp.Keep {
+		tempfile.Sweep()
+	}
+	m.temp, err = tempfile.New(cfg.Temp.Keep)
+	if err != nil {
+		return err
+	}
+	defer m.temp.Cleanup()
+	defer m.tasks.Shutdown(taskShutdownTimeout)
+
+	if m.enricher != nil {
+		defer m.enricher.Close()
+	}
+
+	term := newTerminator()
+	defer term.stop()
+
+	var control *controlServer
+	if cfg.Control.Socket != "" {
+		control, err = startControlServer(cfg.Control.Socket)
+		if err != nil {
+			return err
+		}
+		defer control.close()
 	}
-	m.list.SetShowTitle(false)
-	m.list.Styles.TitleBar = lipgloss.Style{} // clear style...
-	m.list.Styles.Title = lipgloss.Style{}
-	m.list.SetShowStatusBar(false)
 
-	p := tea.NewProgram(m)
+	input, err := newTTYInput()
+	if err != nil {
+		return err
+	}
+	defer input.close()
+	m.terminal = &terminal{input: input, signals: term}
+
+	p := tea.NewProgram(m, tea.WithInput(input), tea.WithOutput(termOutput))
 	p.EnterAltScreen()
+	m.terminal.program = p
 
+	if control != nil {
+		control.setProgram(p)
+		defer control.setProgram(nil)
+	}
+	term.setProgram(p)
+	defer term.setProgram(nil)
 	return p.Start()
 }
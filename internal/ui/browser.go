@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+const (
+	presignExpires = 15 * time.Minute
+)
+
+type browserOpenedMsg struct {
+	item *stu.ObjectItem
+	err  error
+}
+
+// openBrowser opens the url with the default browser without waiting for it,
+// so the terminal is not needed unlike execRequest.
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+func (m model) openInBrowser() (tea.Model, tea.Cmd) {
+	item, ok := m.selectedFile()
+	if !ok {
+		return m, nil
+	}
+	m.status = fmt.Sprintf("opening %s...", item.Filename())
+	return m, presignAndOpen(m.tasks, m.client, m.bucket, item)
+}
+
+func presignAndOpen(tasks *stu.TaskManager, client stu.Client, bucket string, item *stu.ObjectItem) tea.Cmd {
+	return taskCmd(tasks, "presign "+bucket+"/"+item.ObjectKey(), func(ctx context.Context) tea.Msg {
+		url, err := client.PresignGetObject(ctx, bucket, item.ObjectKey(), presignExpires)
+		if err == nil {
+			err = openBrowser(url)
+		}
+		return browserOpenedMsg{item: item, err: err}
+	})
+}
+
+func (m model) updateBrowserMsg(msg browserOpenedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.status = m.ui.viewError(msg.err)
+		return m, nil
+	}
+	m.status = fmt.Sprintf("opened %s in the browser (the URL expires in %s)", msg.item.Filename(), presignExpires)
+	return m, nil
+}
@@ -0,0 +1,33 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lusingander/stu/internal/clipboard"
+)
+
+type clipboardDoneMsg struct {
+	// shown as a toast when copied
+	done string
+	err  error
+}
+
+// copyToClipboard copies in a command instead of the update, which is kept responsive while
+// a clipboard command runs. OSC52 sequences are written to the output of the program in one write,
+// which is serialized with the frames of the renderer.
+func copyToClipboard(cb clipboard.Clipboard, text, done string) tea.Cmd {
+	return func() tea.Msg {
+		return clipboardDoneMsg{done: done, err: cb.Copy(text)}
+	}
+}
+
+func (m model) updateClipboardMsg(msg clipboardDoneMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		if m.page == pageCredentials {
+			m.credentials.err = msg.err
+			return m, nil
+		}
+		m.status = m.ui.viewError(msg.err)
+		return m, nil
+	}
+	return m.showToast(msg.done)
+}
@@ -0,0 +1,335 @@
+package ui
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// control server accepts newline-delimited JSON-RPC 2.0 requests on a unix socket
+// and applies them to the running program.
+//
+// methods:
+//   state                      returns the current page, location and items
+//   keys   {"keys": ["j", "enter"]}  sends key presses
+//   select {"text": "foo/"}    moves the cursor to the item
+//   quit                       quits the program
+
+const (
+	controlTimeout = 10 * time.Second
+)
+
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcInternalError  = -32603
+)
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type controlState struct {
+	Page     string   `json:"page"`
+	Bucket   string   `json:"bucket"`
+	Prefix   string   `json:"prefix"`
+	Selected string   `json:"selected"`
+	Items    []string `json:"items"`
+	Status   string   `json:"status"`
+}
+
+type controlMsg struct {
+	req   *rpcRequest
+	reply chan<- *rpcResponse
+}
+
+type controlServer struct {
+	listener net.Listener
+	path     string
+
+	mu      sync.Mutex
+	program *tea.Program
+}
+
+func startControlServer(path string) (*controlServer, error) {
+	// remove the socket left by the previous process, but nothing else at the configured path
+	fi, err := os.Lstat(path)
+	switch {
+	case err == nil && fi.Mode()&os.ModeSocket == 0:
+		return nil, fmt.Errorf("control socket path exists and is not a socket: %s", path)
+	case err == nil:
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("control socket is in use by another process: %s", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, err
+	}
+	l, err := listenControlSocket(path)
+	if err != nil {
+		return nil, err
+	}
+	s := &controlServer{listener: l, path: path}
+	go s.serve()
+	return s, nil
+}
+
+func (s *controlServer) setProgram(p *tea.Program) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.program = p
+}
+
+func (s *controlServer) close() error {
+	err := s.listener.Close()
+	os.Remove(s.path)
+	return err
+}
+
+func (s *controlServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *controlServer) handle(conn net.Conn) {
+	defer conn.Close()
+	enc := json.NewEncoder(conn)
+	sc := bufio.NewScanner(conn)
+	for sc.Scan() {
+		var req rpcRequest
+		if err := json.Unmarshal(sc.Bytes(), &req); err != nil {
+			enc.Encode(newRPCError(nil, rpcParseError, err.Error()))
+			continue
+		}
+		if req.JSONRPC != "2.0" || req.Method == "" {
+			enc.Encode(newRPCError(req.ID, rpcInvalidRequest, "invalid request"))
+			continue
+		}
+		res := s.dispatch(&req)
+		// notifications, requests without id, are not answered
+		if req.ID != nil {
+			enc.Encode(res)
+		}
+	}
+}
+
+func (s *controlServer) dispatch(req *rpcRequest) *rpcResponse {
+	s.mu.Lock()
+	p := s.program
+	s.mu.Unlock()
+	if p == nil {
+		return newRPCError(req.ID, rpcInternalError, "program is not running")
+	}
+	reply := make(chan *rpcResponse, 1)
+	// Send blocks if the program exits before receiving the message
+	go p.Send(controlMsg{req: req, reply: reply})
+	select {
+	case res := <-reply:
+		return res
+	case <-time.After(controlTimeout):
+		return newRPCError(req.ID, rpcInternalError, "timed out")
+	}
+}
+
+func newRPCResult(id json.RawMessage, result interface{}) *rpcResponse {
+	return &rpcResponse{JSONRPC: "2.0", ID: id, Result: result}
+}
+
+func newRPCError(id json.RawMessage, code int, message string) *rpcResponse {
+	return &rpcResponse{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: message}}
+}
+
+var controlKeyTypes = map[string]tea.KeyType{
+	"enter":     tea.KeyEnter,
+	"esc":       tea.KeyEscape,
+	"backspace": tea.KeyBackspace,
+	"tab":       tea.KeyTab,
+	"up":        tea.KeyUp,
+	"down":      tea.KeyDown,
+	"left":      tea.KeyLeft,
+	"right":     tea.KeyRight,
+	"home":      tea.KeyHome,
+	"end":       tea.KeyEnd,
+	"pgup":      tea.KeyPgUp,
+	"pgdown":    tea.KeyPgDown,
+	"ctrl+c":    tea.KeyCtrlC,
+	"ctrl+h":    tea.KeyCtrlH,
+}
+
+func parseControlKey(s string) (tea.KeyMsg, error) {
+	if t, ok := controlKeyTypes[s]; ok {
+		return tea.KeyMsg{Type: t}, nil
+	}
+	if runes := []rune(s); len(runes) == 1 {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: runes}, nil
+	}
+	return tea.KeyMsg{}, fmt.Errorf("unknown key: %s", s)
+}
+
+func (m model) updateControlMsg(msg controlMsg) (tea.Model, tea.Cmd) {
+	req := msg.req
+	switch req.Method {
+	case "state":
+		msg.reply <- newRPCResult(req.ID, m.controlState())
+		return m, nil
+	case "keys":
+		var params struct {
+			Keys []string `json:"keys"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			msg.reply <- newRPCError(req.ID, rpcInvalidParams, err.Error())
+			return m, nil
+		}
+		var ret tea.Model = m
+		cmds := make([]tea.Cmd, 0)
+		for _, k := range params.Keys {
+			key, err := parseControlKey(k)
+			if err != nil {
+				msg.reply <- newRPCError(req.ID, rpcInvalidParams, err.Error())
+				return ret, tea.Batch(cmds...)
+			}
+			var cmd tea.Cmd
+			ret, cmd = ret.Update(key)
+			cmds = append(cmds, cmd)
+		}
+		msg.reply <- newRPCResult(req.ID, ret.(model).controlState())
+		return ret, tea.Batch(cmds...)
+	case "select":
+		var params struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			msg.reply <- newRPCError(req.ID, rpcInvalidParams, err.Error())
+			return m, nil
+		}
+		l := m.currentList()
+		if l == nil {
+			msg.reply <- newRPCError(req.ID, rpcInvalidParams, "current page has no list")
+			return m, nil
+		}
+		for i, item := range l.VisibleItems() {
+			if li, ok := item.(listItem); ok && li.Text() == params.Text {
+				l.Select(i)
+				msg.reply <- newRPCResult(req.ID, m.controlState())
+				return m, nil
+			}
+		}
+		msg.reply <- newRPCError(req.ID, rpcInvalidParams, fmt.Sprintf("item not found: %s", params.Text))
+		return m, nil
+	case "quit":
+		msg.reply <- newRPCResult(req.ID, true)
+		return m, tea.Quit
+	}
+	msg.reply <- newRPCError(req.ID, rpcMethodNotFound, fmt.Sprintf("method not found: %s", req.Method))
+	return m, nil
+}
+
+func (m *model) currentList() *list.Model {
+	switch m.page {
+	case pageList, pageDateFilter:
+		return &m.list
+	case pageSearchResult:
+		return &m.search.results
+	case pageReportMenu:
+		return &m.report.menu
+	case pageReport:
+		return &m.report.results
+	case pageHookMenu:
+		return &m.hook.menu
+	case pageRenamePreview:
+		return &m.rename.preview
+	case pageCopy:
+		return &m.copy.failures
+	case pageDeletePrefix:
+		return &m.deletePrefix.failures
+	case pageUpload:
+		return &m.upload.failures
+	case pageProfiles:
+		return &m.profiles.profiles
+	}
+	return nil
+}
+
+var pageNames = map[page]string{
+	pageList:                "list",
+	pageSearchInput:         "search-input",
+	pageSearchResult:        "search-result",
+	pageDateFilter:          "date-filter",
+	pageStats:               "stats",
+	pageReportMenu:          "report-menu",
+	pageReport:              "report",
+	pageExport:              "export",
+	pageHookMenu:            "hook-menu",
+	pageTouchConfirm:        "touch-confirm",
+	pageDeleteConfirm:       "delete-confirm",
+	pageDeletePrefixConfirm: "delete-prefix-confirm",
+	pageDeletePrefix:        "delete-prefix",
+	pageUploadInput:         "upload-input",
+	pageUpload:              "upload",
+	pageRenameInput:         "rename-input",
+	pageRenamePreview:       "rename-preview",
+	pageCopyInput:           "copy-input",
+	pageCopy:                "copy",
+	pageDetail:              "detail",
+	pagePreview:             "preview",
+	pageTasks:               "tasks",
+	pageHelp:                "help",
+	pageProfiles:            "profiles",
+	pageACLMenu:             "acl-menu",
+	pageProperties:          "properties",
+	pageCredentials:         "credentials",
+}
+
+func (m model) controlState() *controlState {
+	st := &controlState{
+		Page:   pageNames[m.page],
+		Bucket: m.bucket,
+		Prefix: m.currentPrefix(),
+		Items:  make([]string, 0),
+		Status: m.status,
+	}
+	if l := m.currentList(); l != nil {
+		for _, item := range l.VisibleItems() {
+			if li, ok := item.(listItem); ok {
+				st.Items = append(st.Items, li.Text())
+			}
+		}
+		if li, ok := l.SelectedItem().(listItem); ok {
+			st.Selected = li.Text()
+		}
+	}
+	return st
+}
@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestStartControlServer(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix socket permissions are not supported")
+	}
+	dir := t.TempDir()
+
+	// left by a process which did not exit cleanly
+	stale := filepath.Join(dir, "stale.sock")
+	l, err := net.Listen("unix", stale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	l.Close()
+
+	notSocket := filepath.Join(dir, "file")
+	if err := ioutil.WriteFile(notSocket, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{name: "new", path: filepath.Join(dir, "new.sock")},
+		{name: "stale", path: stale},
+		{name: "not a socket", path: notSocket, wantErr: true},
+	}
+	for _, tt := range tests {
+		s, err := startControlServer(tt.path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if err != nil {
+			continue
+		}
+		fi, err := os.Stat(tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := fi.Mode().Perm(); perm != 0600 {
+			t.Errorf("%s: permission = %o, want 600", tt.name, perm)
+		}
+		if _, err := startControlServer(tt.path); err == nil {
+			t.Errorf("%s: started twice on the socket in use", tt.name)
+		}
+		s.close()
+		if _, err := os.Lstat(tt.path); !os.IsNotExist(err) {
+			t.Errorf("%s: socket is not removed: %v", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+//go:build !windows
+// +build !windows
+
+package ui
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// listenControlSocket creates the socket with 0600 so that other users cannot control the program.
+// The umask is shared by the whole process, so instead the socket is created in a private directory
+// and moved to the path after its permission is restricted.
+func listenControlSocket(path string) (net.Listener, error) {
+	dir, err := ioutil.TempDir(filepath.Dir(path), ".stu-control-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	tmp := filepath.Join(dir, "socket")
+	l, err := net.Listen("unix", tmp)
+	if err != nil {
+		return nil, err
+	}
+	// the listener would remove the temporary path, the socket is removed by the server instead
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	err = os.Chmod(tmp, 0600)
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
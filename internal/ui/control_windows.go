@@ -0,0 +1,13 @@
+//go:build windows
+// +build windows
+
+package ui
+
+import (
+	"net"
+)
+
+// listenControlSocket relies on the ACL of the directory, since the permission bits of the socket are ignored.
+func listenControlSocket(path string) (net.Listener, error) {
+	return net.Listen("unix", path)
+}
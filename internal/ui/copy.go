@@ -0,0 +1,304 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+const (
+	copyConcurrency = 8
+	copyRetries     = 3
+)
+
+type copyState struct {
+	input    textinput.Model
+	warning  keyWarning
+	failures list.Model
+
+	srcPrefix string
+	// objects matched by the date filter, copied instead of the whole prefix if set
+	items     []*stu.ObjectItem
+	dstBucket string
+	dstPrefix string
+
+	id       int
+	ch       chan tea.Msg
+	cancel   context.CancelFunc
+	running  bool
+	progress stu.Progress
+	err      error
+}
+
+type copyFailureItem struct {
+	*stu.CopyFailure
+}
+
+func (i *copyFailureItem) Text() string {
+	return fmt.Sprintf("%s: %s", i.Key, i.Err)
+}
+
+func (i *copyFailureItem) FilterValue() string {
+	return i.Key
+}
+
+type copyProgressMsg struct {
+	id       int
+	progress stu.Progress
+}
+
+type copyDoneMsg struct {
+	id     int
+	result *stu.CopyResult
+	err    error
+}
+
+func newCopyState(ui *uiConfig) *copyState {
+	input := textinput.NewModel()
+	input.Prompt = "Copy to: "
+	input.Placeholder = "bucket/prefix/"
+	return &copyState{
+		input:    input,
+		failures: newList(ui, nil),
+		cancel:   func() {},
+	}
+}
+
+func (s *copyState) setSize(width, height int) {
+	s.failures.SetSize(width, height)
+}
+
+func (s *copyState) stop() {
+	s.cancel()
+	s.running = false
+}
+
+func waitCopyMsg(id int, ch <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return copyDoneMsg{id: id}
+		}
+		return msg
+	}
+}
+
+// parseCopyDestination splits "bucket/prefix/" into the bucket and the prefix.
+func parseCopyDestination(s string) (string, string, error) {
+	s = strings.TrimSpace(strings.TrimPrefix(s, "s3://"))
+	bucket, prefix := s, ""
+	if i := strings.Index(s, "/"); i >= 0 {
+		bucket, prefix = s[:i], s[i+1:]
+	}
+	if bucket == "" {
+		return "", "", errors.New("bucket is empty")
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return bucket, prefix, nil
+}
+
+func (m model) openCopyInput() (tea.Model, tea.Cmd) {
+	s := m.copy
+	if items, ok := m.matchedObjects(); ok {
+		if len(items) == 0 {
+			m.status = "no objects to copy"
+			return m, nil
+		}
+		s.srcPrefix = m.currentPrefix()
+		s.items = items
+	} else {
+		i, ok := m.list.SelectedItem().(*stu.ObjectItem)
+		if !ok || !i.Dir {
+			return m, nil
+		}
+		s.srcPrefix = i.ObjectKey()
+		s.items = nil
+	}
+	s.err = nil
+	s.warning.reset()
+	s.input.SetValue(m.bucket + "/" + s.srcPrefix)
+	s.input.CursorEnd()
+	s.input.Focus()
+	m.page = pageCopyInput
+	return m, textinput.Blink
+}
+
+func (m model) startCopy() (tea.Model, tea.Cmd) {
+	s := m.copy
+	s.stop()
+
+	s.id++
+	s.ch = make(chan tea.Msg)
+	s.running = true
+	s.progress = stu.Progress{}
+	s.err = nil
+	s.failures.SetItems(nil)
+	s.failures.ResetSelected()
+	s.failures.ResetFilter()
+
+	id, ch := s.id, s.ch
+	client, srcBucket, srcPrefix, items, dstBucket, dstPrefix := m.client, m.bucket, s.srcPrefix, s.items, s.dstBucket, s.dstPrefix
+	s.cancel = m.tasks.Go(fmt.Sprintf("copy %s/%s to %s/%s", srcBucket, srcPrefix, dstBucket, dstPrefix), func(ctx context.Context) {
+		defer close(ch)
+		progress := func(p stu.Progress) {
+			select {
+			case ch <- copyProgressMsg{id: id, progress: p}:
+			case <-ctx.Done():
+			}
+		}
+		var result *stu.CopyResult
+		var err error
+		if items != nil {
+			result, err = stu.CopyItems(ctx, client, srcBucket, srcPrefix, items, dstBucket, dstPrefix, copyConcurrency, copyRetries, progress)
+		} else {
+			result, err = stu.CopyPrefix(ctx, client, srcBucket, srcPrefix, dstBucket, dstPrefix, copyConcurrency, copyRetries, progress)
+		}
+		select {
+		case ch <- copyDoneMsg{id: id, result: result, err: err}:
+		case <-ctx.Done():
+		}
+	})
+
+	m.page = pageCopy
+	return m, waitCopyMsg(id, ch)
+}
+
+func (m model) updateCopyMsg(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.copy
+	switch msg := msg.(type) {
+	case copyProgressMsg:
+		if msg.id != s.id {
+			return m, nil
+		}
+		// progress may arrive out of order from the workers
+		if msg.progress.Items > s.progress.Items {
+			s.progress = msg.progress
+		}
+		return m, waitCopyMsg(s.id, s.ch)
+	case copyDoneMsg:
+		if msg.id != s.id {
+			return m, nil
+		}
+		s.stop()
+		if msg.err != nil && !errors.Is(msg.err, context.Canceled) {
+			s.err = msg.err
+		}
+		if msg.result == nil {
+			return m, nil
+		}
+		s.progress = msg.result.Progress()
+		items := make([]list.Item, len(msg.result.Failed))
+		for i, f := range msg.result.Failed {
+			items[i] = &copyFailureItem{CopyFailure: f}
+		}
+		return m, s.failures.SetItems(items)
+	}
+	return m, nil
+}
+
+func (m model) updateCopyInput(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.copy
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "esc":
+			s.input.Blur()
+			m.page = pageList
+			return m, nil
+		case "enter":
+			bucket, prefix, err := parseCopyDestination(s.input.Value())
+			if err != nil {
+				s.err = err
+				return m, nil
+			}
+			if bucket == m.bucket && strings.HasPrefix(prefix, s.srcPrefix) {
+				s.err = errors.New("destination must not be under the source")
+				return m, nil
+			}
+			if m.bucketSettings(bucket).ReadOnly {
+				s.err = stu.ErrReadOnly
+				return m, nil
+			}
+			if ok, err := s.warning.check(prefix); !ok {
+				s.err = err
+				return m, nil
+			}
+			s.dstBucket, s.dstPrefix = bucket, prefix
+			s.input.Blur()
+			return m.startCopy()
+		}
+	}
+	s.err = nil
+	s.warning.reset()
+	var cmd tea.Cmd
+	s.input, cmd = s.input.Update(msg)
+	return m, cmd
+}
+
+func (m model) updateCopy(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.copy
+	if msg, ok := msg.(tea.KeyMsg); ok && !s.failures.SettingFilter() {
+		switch msg.String() {
+		case "esc", "backspace", "ctrl+h":
+			if msg.String() == "esc" && s.failures.FilterState() != list.Unfiltered {
+				break
+			}
+			if s.running {
+				s.stop()
+				return m, nil
+			}
+			m.page = pageList
+			return m.reloadList()
+		}
+	}
+	var cmd tea.Cmd
+	s.failures, cmd = s.failures.Update(msg)
+	return m, cmd
+}
+
+func (m model) viewCopyStatus() string {
+	s := m.copy
+	p := s.progress
+	summary := fmt.Sprintf("%s objects (%s) copied, %s failed", m.ui.format.count(p.Items-p.Failed), m.ui.format.size(p.Bytes), m.ui.format.count(p.Failed))
+	switch {
+	case s.err != nil:
+		return summary + " " + m.ui.viewError(s.err)
+	case s.running:
+		if s.items != nil {
+			return "copying... " + m.ui.viewProgressBar(int64(p.Items), int64(len(s.items))) + " " + summary + m.viewThrottled()
+		}
+		return "copying... " + summary + m.viewThrottled()
+	}
+	return "done: " + summary
+}
+
+func (m model) viewCopy() string {
+	s := m.copy
+	if m.page == pageCopyInput {
+		v := s.input.View()
+		if s.items != nil {
+			v += fmt.Sprintf("  (%s objects modified %s)", m.ui.format.count(len(s.items)), m.dateFilter.applied)
+		}
+		if s.err != nil {
+			v += "  " + m.ui.viewError(s.err)
+		} else if w := s.warning.view(m.ui); w != "" {
+			v += "  " + w
+		}
+		bc := breadcrumbStyle.Render(fmt.Sprintf("%s : %s", m.viewBreadcrumb(), v))
+		return bc + m.ui.styles.list.Render(m.list.View())
+	}
+	src := s.srcPrefix
+	if s.items != nil {
+		src = fmt.Sprintf("%s objects modified %s", m.ui.format.count(len(s.items)), m.dateFilter.applied)
+	}
+	dst := s.dstBucket + "/" + s.dstPrefix
+	bc := breadcrumbStyle.Render(fmt.Sprintf("%s : Copy %s to %s : %s", m.viewBreadcrumb(), src, dst, m.viewCopyStatus()))
+	return bc + m.ui.styles.list.Render(s.failures.View())
+}
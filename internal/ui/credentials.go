@@ -0,0 +1,159 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lusingander/stu/internal/config"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+var credentialsMenu = []string{
+	"  r  read-only   list and get the objects under the prefix",
+	"  w  read-write  also put and delete the objects under the prefix",
+	"",
+	"The credentials cannot do more than the current credentials (or the role) are allowed to.",
+	"",
+	"Press r or w to issue, esc to cancel.",
+}
+
+type credentialsState struct {
+	cfg     config.CredentialsConfig
+	bucket  string
+	prefix  string
+	id      int
+	issuing bool
+	req     *stu.CredentialsRequest
+	creds   *stu.TemporaryCredentials
+	err     error
+}
+
+type credentialsMsg struct {
+	id    int
+	creds *stu.TemporaryCredentials
+	err   error
+}
+
+func newCredentialsState(cfg config.CredentialsConfig) *credentialsState {
+	return &credentialsState{cfg: cfg}
+}
+
+func (m model) openCredentials() (tea.Model, tea.Cmd) {
+	if m.offline() {
+		m.status = stu.ErrOffline.Error()
+		return m, nil
+	}
+	s := m.credentials
+	switch {
+	case m.bucket != "":
+		s.bucket, s.prefix = m.bucket, m.currentPrefix()
+	default:
+		item, ok := m.list.SelectedItem().(*stu.BucketItem)
+		if !ok {
+			return m, nil
+		}
+		s.bucket, s.prefix = item.BucketName(), ""
+	}
+	s.id++
+	s.issuing = false
+	s.req, s.creds, s.err = nil, nil, nil
+	m.page = pageCredentials
+	return m, nil
+}
+
+func issueCredentials(tasks *stu.TaskManager, client stu.Client, audit *stu.AuditLog, id int, req *stu.CredentialsRequest) tea.Cmd {
+	return taskCmd(tasks, fmt.Sprintf("issue %s credentials s3://%s/%s", req.Access(), req.Bucket, req.Prefix), func(ctx context.Context) tea.Msg {
+		var creds *stu.TemporaryCredentials
+		err := stu.ErrCredentialsNotSupported
+		if i, ok := client.(stu.CredentialsIssuer); ok {
+			creds, err = i.IssueCredentials(ctx, req)
+		}
+		if aerr := audit.Record("issue-credentials", req.Bucket, req.Prefix, req.Access(), err); aerr != nil && err == nil {
+			err = fmt.Errorf("credentials were issued but failed to write the audit log: %w", aerr)
+		}
+		return credentialsMsg{id: id, creds: creds, err: err}
+	})
+}
+
+func (m model) startCredentials(write bool) (tea.Model, tea.Cmd) {
+	s := m.credentials
+	if write && m.bucketSettings(s.bucket).ReadOnly {
+		s.err = stu.ErrReadOnly
+		return m, nil
+	}
+	s.req = &stu.CredentialsRequest{
+		Bucket:   s.bucket,
+		Prefix:   s.prefix,
+		Write:    write,
+		Duration: time.Duration(s.cfg.DurationSeconds) * time.Second,
+		RoleARN:  s.cfg.RoleARN,
+	}
+	s.issuing = true
+	s.err = nil
+	return m, issueCredentials(m.tasks, m.client, m.audit, s.id, s.req)
+}
+
+func (m model) updateCredentialsMsg(msg credentialsMsg) (tea.Model, tea.Cmd) {
+	s := m.credentials
+	if msg.id != s.id {
+		return m, nil
+	}
+	s.issuing = false
+	s.creds, s.err = msg.creds, msg.err
+	return m, nil
+}
+
+func (m model) updateCredentials(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.credentials
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "esc", "backspace", "ctrl+h":
+			// the result is dropped if it arrives after leaving the page
+			s.id++
+			m.page = pageList
+			return m, nil
+		}
+		switch {
+		case s.issuing:
+		case s.creds != nil:
+			if msg.String() == "y" {
+				return m, copyToClipboard(m.clipboard, stu.ExportCommands(s.creds), "copied the export commands")
+			}
+		default:
+			switch msg.String() {
+			case "r":
+				return m.startCredentials(false)
+			case "w":
+				return m.startCredentials(true)
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m model) viewCredentials() string {
+	s := m.credentials
+	bc := breadcrumbStyle.Render(fmt.Sprintf("%s : Credentials for s3://%s/%s", m.viewBreadcrumb(), s.bucket, s.prefix))
+	lines := make([]string, 0)
+	if s.err != nil {
+		lines = append(lines, m.ui.viewError(s.err), "")
+	}
+	switch {
+	case s.issuing:
+		lines = append(lines, fmt.Sprintf("issuing %s credentials...", s.req.Access()))
+	case s.creds != nil:
+		lines = append(lines,
+			fmt.Sprintf("%s credentials, expire at %s", s.req.Access(), m.ui.format.time(s.creds.Expiration)),
+			"",
+			strings.TrimSuffix(stu.ExportCommands(s.creds), "\n"),
+			"",
+			"Press y to copy the export commands, esc to go back.",
+		)
+	default:
+		lines = append(lines, credentialsMenu...)
+	}
+	return bc + m.ui.styles.list.Render(itemStyle.Render(strings.Join(lines, "\n")))
+}
@@ -0,0 +1,141 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+type dateFilterState struct {
+	input   textinput.Model
+	current *stu.DateRange
+	matched int
+	err     error
+
+	applied *stu.DateRange
+	all     []list.Item
+}
+
+func newDateFilterState() *dateFilterState {
+	input := textinput.NewModel()
+	input.Prompt = "Modified: "
+	input.Placeholder = "older than 90 days / newer than 7d / from 2021-01-01 to 2021-12-31"
+	return &dateFilterState{
+		input: input,
+	}
+}
+
+func (s *dateFilterState) reset() {
+	s.applied = nil
+	s.all = nil
+}
+
+func (s *dateFilterState) baseItems(l list.Model) []list.Item {
+	if s.applied != nil {
+		return s.all
+	}
+	return l.Items()
+}
+
+func filterByDateRange(items []list.Item, r *stu.DateRange) []list.Item {
+	matched := make([]list.Item, 0)
+	for _, item := range items {
+		if obj, ok := item.(*stu.ObjectItem); ok && !obj.Dir && r.Contains(obj.LastModified) {
+			matched = append(matched, item)
+		}
+	}
+	return matched
+}
+
+// matchedObjects returns the objects listed by the applied filter, which the batch actions
+// (delete, download and copy) apply to instead of the selected item.
+func (m model) matchedObjects() ([]*stu.ObjectItem, bool) {
+	if m.dateFilter.applied == nil {
+		return nil, false
+	}
+	items := make([]*stu.ObjectItem, 0, len(m.list.Items()))
+	for _, item := range m.list.Items() {
+		if obj, ok := item.(*stu.ObjectItem); ok {
+			items = append(items, obj)
+		}
+	}
+	return items, true
+}
+
+func (m model) openDateFilter() (tea.Model, tea.Cmd) {
+	s := m.dateFilter
+	m.page = pageDateFilter
+	s.current = nil
+	s.err = nil
+	s.input.Reset()
+	s.input.Focus()
+	return m, textinput.Blink
+}
+
+func (m model) updateDateFilter(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.dateFilter
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "esc":
+			s.input.Blur()
+			m.page = pageList
+			return m, nil
+		case "enter":
+			if s.current == nil {
+				return m, nil
+			}
+			base := s.baseItems(m.list)
+			m.list.SetItems(filterByDateRange(base, s.current))
+			m.list.ResetSelected()
+			m.list.ResetFilter()
+			s.all = base
+			s.applied = s.current
+			s.input.Blur()
+			m.page = pageList
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	s.input, cmd = s.input.Update(msg)
+
+	s.current, s.err = nil, nil
+	if v := s.input.Value(); v != "" {
+		r, err := stu.ParseDateRange(v, time.Now())
+		if err != nil {
+			s.err = err
+		} else {
+			s.current = r
+			s.matched = len(filterByDateRange(s.baseItems(m.list), r))
+		}
+	}
+	return m, cmd
+}
+
+func (m model) clearDateFilter() model {
+	s := m.dateFilter
+	m.list.SetItems(s.all)
+	m.list.ResetSelected()
+	s.reset()
+	return m
+}
+
+func (m model) viewDateFilterStatus() string {
+	s := m.dateFilter
+	if m.page == pageDateFilter {
+		v := s.input.View()
+		if s.err != nil {
+			v += "  " + m.ui.viewError(s.err)
+		} else if s.current != nil {
+			v += fmt.Sprintf("  (matches: %s)", m.ui.format.count(s.matched))
+		}
+		return v
+	}
+	if s.applied != nil {
+		return fmt.Sprintf("Modified: %s (%s items, delete, download and copy apply to all of them)", s.applied, m.ui.format.count(len(m.list.Items())))
+	}
+	return ""
+}
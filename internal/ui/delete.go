@@ -0,0 +1,158 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+const (
+	deleteConfirmKey  = "key"
+	deleteConfirmName = "name"
+)
+
+type deleteState struct {
+	// "key" to confirm with y, "name" to confirm by typing the filename
+	confirm  string
+	input    textinput.Model
+	target   *stu.ObjectItem
+	mismatch bool
+}
+
+type deleteDoneMsg struct {
+	item *stu.ObjectItem
+	err  error
+}
+
+func newDeleteState(confirm string) *deleteState {
+	input := textinput.NewModel()
+	input.Prompt = "Filename: "
+	return &deleteState{
+		confirm: confirm,
+		input:   input,
+	}
+}
+
+func (m model) openDeleteConfirm() (tea.Model, tea.Cmd) {
+	if items, ok := m.matchedObjects(); ok {
+		if len(items) == 0 {
+			m.status = "no objects to delete"
+			return m, nil
+		}
+		return m.openDeleteMatchedConfirm(items)
+	}
+	item, ok := m.list.SelectedItem().(*stu.ObjectItem)
+	if !ok {
+		return m, nil
+	}
+	if item.Dir {
+		return m.openDeletePrefixConfirm(item)
+	}
+	s := m.delete
+	s.target = item
+	s.mismatch = false
+	m.page = pageDeleteConfirm
+	if s.confirm != deleteConfirmName {
+		return m, nil
+	}
+	s.input.SetValue("")
+	s.input.Placeholder = item.Filename()
+	s.input.Focus()
+	return m, textinput.Blink
+}
+
+func deleteObject(tasks *stu.TaskManager, client stu.Client, audit *stu.AuditLog, bucket string, item *stu.ObjectItem) tea.Cmd {
+	return taskCmd(tasks, "delete "+bucket+"/"+item.ObjectKey(), func(ctx context.Context) tea.Msg {
+		err := client.DeleteObject(ctx, bucket, item.ObjectKey())
+		if aerr := audit.Record("delete-object", bucket, item.ObjectKey(), "", err); aerr != nil && err == nil {
+			err = fmt.Errorf("object was deleted but failed to write the audit log: %w", aerr)
+		}
+		return deleteDoneMsg{item: item, err: err}
+	})
+}
+
+func (m model) startDelete() (tea.Model, tea.Cmd) {
+	m.delete.input.Blur()
+	m.page = pageList
+	m.status = fmt.Sprintf("deleting %s...", m.delete.target.Filename())
+	return m, deleteObject(m.tasks, m.client, m.audit, m.bucket, m.delete.target)
+}
+
+// updateDeleteMsg removes the item from the list instead of listing the prefix again.
+func (m model) updateDeleteMsg(msg deleteDoneMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.status = m.ui.viewError(msg.err)
+		return m, nil
+	}
+	m.objCache.Remove(m.bucket, msg.item)
+	for i, item := range m.list.Items() {
+		if item == msg.item {
+			m.list.RemoveItem(i)
+			break
+		}
+	}
+	m.status = fmt.Sprintf("deleted: %s", msg.item.Filename())
+	return m, nil
+}
+
+func (m model) updateDeleteConfirm(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.delete
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		if s.confirm == deleteConfirmName {
+			switch msg.String() {
+			case "enter":
+				if s.input.Value() != s.target.Filename() {
+					s.mismatch = true
+					return m, nil
+				}
+				return m.startDelete()
+			case "esc":
+				s.input.Blur()
+				m.page = pageList
+				return m, nil
+			}
+			var cmd tea.Cmd
+			s.input, cmd = s.input.Update(msg)
+			return m, cmd
+		}
+		switch msg.String() {
+		case "y":
+			return m.startDelete()
+		case "n", "esc", "backspace", "ctrl+h":
+			m.page = pageList
+			return m, nil
+		}
+		return m, nil
+	}
+	if s.confirm == deleteConfirmName {
+		var cmd tea.Cmd
+		s.input, cmd = s.input.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m model) viewDeleteConfirm() string {
+	s := m.delete
+	bc := breadcrumbStyle.Render(fmt.Sprintf("%s : Delete %s", m.viewBreadcrumb(), s.target.Filename()))
+	lines := []string{
+		m.ui.viewWarning(fmt.Sprintf("s3://%s/%s will be deleted.", m.bucket, s.target.ObjectKey())),
+		"",
+		"A delete marker is created instead if versioning is enabled, otherwise the object cannot be restored.",
+		"",
+	}
+	if s.confirm == deleteConfirmName {
+		lines = append(lines, "Type the filename and press enter to delete, esc to cancel.", "", s.input.View())
+		if s.mismatch {
+			lines = append(lines, "", m.ui.viewError(errors.New("the filename does not match")))
+		}
+	} else {
+		lines = append(lines, "Press y to delete, n or esc to cancel.")
+	}
+	return bc + m.ui.styles.list.Render(itemStyle.Render(strings.Join(lines, "\n")))
+}
@@ -0,0 +1,401 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+type deletePrefixState struct {
+	input    textinput.Model
+	failures list.Model
+
+	prefix string
+	// typed to confirm
+	name string
+	// objects matched by the date filter, deleted instead of the whole prefix if set
+	items []*stu.ObjectItem
+	// objects under the prefix counted before confirming
+	total   *stu.PrefixStats
+	scanned int
+
+	id       int
+	ch       chan tea.Msg
+	cancel   context.CancelFunc
+	running  bool
+	mismatch bool
+	canceled bool
+	progress stu.Progress
+	err      error
+}
+
+type deleteFailureItem struct {
+	*stu.DeleteFailure
+}
+
+func (i *deleteFailureItem) Text() string {
+	return fmt.Sprintf("%s: %s", i.Key, i.Err)
+}
+
+func (i *deleteFailureItem) FilterValue() string {
+	return i.Key
+}
+
+type deletePrefixCountMsg struct {
+	id int
+	// objects counted so far
+	counted int
+	// set when done
+	stats *stu.PrefixStats
+	done  bool
+	err   error
+}
+
+type deletePrefixProgressMsg struct {
+	id       int
+	progress stu.Progress
+}
+
+type deletePrefixDoneMsg struct {
+	id     int
+	result *stu.DeleteResult
+	err    error
+}
+
+func newDeletePrefixState(ui *uiConfig) *deletePrefixState {
+	input := textinput.NewModel()
+	input.Prompt = "Prefix: "
+	return &deletePrefixState{
+		input:    input,
+		failures: newList(ui, nil),
+		cancel:   func() {},
+	}
+}
+
+func (s *deletePrefixState) setSize(width, height int) {
+	s.failures.SetSize(width, height)
+}
+
+func (s *deletePrefixState) stop() {
+	s.cancel()
+	s.running = false
+}
+
+// waitDeletePrefixMsg waits for the messages of the current phase, counting or deleting.
+func waitDeletePrefixMsg(id int, ch <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return deletePrefixDoneMsg{id: id}
+		}
+		return msg
+	}
+}
+
+// openDeletePrefixConfirm counts the objects under the prefix, which are shown in the confirmation.
+func (m model) openDeletePrefixConfirm(item *stu.ObjectItem) (tea.Model, tea.Cmd) {
+	s := m.deletePrefix
+	s.stop()
+
+	s.id++
+	s.ch = make(chan tea.Msg)
+	s.running = true
+	s.prefix = item.ObjectKey()
+	s.name = item.Filename()
+	s.items = nil
+	s.total = nil
+	s.scanned = 0
+	s.mismatch = false
+	s.err = nil
+	s.input.SetValue("")
+	s.input.Placeholder = s.name
+	s.input.Focus()
+
+	id, ch := s.id, s.ch
+	client, bucket, prefix := m.client, m.bucket, s.prefix
+	s.cancel = m.tasks.Go(fmt.Sprintf("count %s/%s", bucket, prefix), func(ctx context.Context) {
+		defer close(ch)
+		stats, err := stu.CollectPrefixStats(ctx, client, bucket, prefix, func(p stu.Progress) {
+			select {
+			case ch <- deletePrefixCountMsg{id: id, counted: p.Items}:
+			case <-ctx.Done():
+			}
+		})
+		select {
+		case ch <- deletePrefixCountMsg{id: id, stats: stats, done: true, err: err}:
+		case <-ctx.Done():
+		}
+	})
+
+	m.page = pageDeletePrefixConfirm
+	return m, tea.Batch(waitDeletePrefixMsg(id, ch), textinput.Blink)
+}
+
+// openDeleteMatchedConfirm confirms deleting the objects matched by the date filter,
+// which are already counted in the listing.
+func (m model) openDeleteMatchedConfirm(items []*stu.ObjectItem) (tea.Model, tea.Cmd) {
+	s := m.deletePrefix
+	s.stop()
+
+	s.id++
+	s.prefix = m.currentPrefix()
+	s.name = fmt.Sprintf("objects modified %s", m.dateFilter.applied)
+	s.items = items
+	s.total = &stu.PrefixStats{Bucket: m.bucket, Prefix: s.prefix, Objects: len(items)}
+	for _, item := range items {
+		s.total.TotalSize += item.Size
+	}
+	s.mismatch = false
+	s.err = nil
+
+	m.page = pageDeletePrefixConfirm
+	return m, nil
+}
+
+func (m model) startDeletePrefix() (tea.Model, tea.Cmd) {
+	s := m.deletePrefix
+	s.stop()
+	s.input.Blur()
+
+	s.id++
+	s.ch = make(chan tea.Msg)
+	s.running = true
+	s.canceled = false
+	s.progress = stu.Progress{}
+	s.err = nil
+	s.failures.SetItems(nil)
+	s.failures.ResetSelected()
+	s.failures.ResetFilter()
+
+	id, ch := s.id, s.ch
+	client, audit, bucket, prefix, items, name := m.client, m.audit, m.bucket, s.prefix, s.items, s.name
+	s.cancel = m.tasks.Go(fmt.Sprintf("delete %s/%s", bucket, prefix), func(ctx context.Context) {
+		defer close(ch)
+		progress := func(p stu.Progress) {
+			select {
+			case ch <- deletePrefixProgressMsg{id: id, progress: p}:
+			case <-ctx.Done():
+			}
+		}
+		action := "delete-prefix"
+		var result *stu.DeleteResult
+		var err error
+		if items != nil {
+			action = "delete-matched"
+			result, err = stu.DeleteItems(ctx, client, bucket, items, progress)
+		} else {
+			result, err = stu.DeletePrefix(ctx, client, bucket, prefix, progress)
+		}
+		detail := fmt.Sprintf("%d deleted, %d failed", result.Deleted, len(result.Failed))
+		if items != nil {
+			detail = name + ": " + detail
+		}
+		if aerr := audit.Record(action, bucket, prefix, detail, err); aerr != nil && err == nil {
+			err = fmt.Errorf("objects were deleted but failed to write the audit log: %w", aerr)
+		}
+		// the result is sent even if canceled to show what has been deleted
+		ch <- deletePrefixDoneMsg{id: id, result: result, err: err}
+	})
+
+	m.page = pageDeletePrefix
+	return m, waitDeletePrefixMsg(id, ch)
+}
+
+func (m model) updateDeletePrefixMsg(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.deletePrefix
+	switch msg := msg.(type) {
+	case deletePrefixCountMsg:
+		if msg.id != s.id {
+			return m, nil
+		}
+		if !msg.done {
+			s.scanned = msg.counted
+			return m, waitDeletePrefixMsg(s.id, s.ch)
+		}
+		s.total = msg.stats
+		s.stop()
+		if msg.err != nil && !errors.Is(msg.err, context.Canceled) {
+			s.err = msg.err
+		}
+		return m, nil
+	case deletePrefixProgressMsg:
+		if msg.id != s.id {
+			return m, nil
+		}
+		s.progress = msg.progress
+		return m, waitDeletePrefixMsg(s.id, s.ch)
+	case deletePrefixDoneMsg:
+		if msg.id != s.id {
+			return m, nil
+		}
+		s.running = false
+		s.canceled = errors.Is(msg.err, context.Canceled)
+		if msg.err != nil && !s.canceled {
+			s.err = msg.err
+		}
+		if msg.result == nil {
+			return m, nil
+		}
+		s.progress = msg.result.Progress()
+		items := make([]list.Item, len(msg.result.Failed))
+		for i, f := range msg.result.Failed {
+			items[i] = &deleteFailureItem{DeleteFailure: f}
+		}
+		return m, s.failures.SetItems(items)
+	}
+	return m, nil
+}
+
+// counted reports whether all objects under the prefix have been counted.
+func (s *deletePrefixState) counted() bool {
+	return !s.running && s.total != nil && s.err == nil
+}
+
+func (m model) updateDeletePrefixConfirm(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.deletePrefix
+	if s.items != nil {
+		return m.updateDeleteMatchedConfirm(msg)
+	}
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "esc":
+			s.stop()
+			s.input.Blur()
+			m.page = pageList
+			return m, nil
+		case "enter":
+			if !s.counted() {
+				return m, nil
+			}
+			if s.input.Value() != s.name {
+				s.mismatch = true
+				return m, nil
+			}
+			if s.total.Objects == 0 {
+				s.input.Blur()
+				m.page = pageList
+				m.status = "no objects to delete"
+				return m, nil
+			}
+			return m.startDeletePrefix()
+		}
+	}
+	s.mismatch = false
+	var cmd tea.Cmd
+	s.input, cmd = s.input.Update(msg)
+	return m, cmd
+}
+
+func (m model) updateDeleteMatchedConfirm(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "y":
+			return m.startDeletePrefix()
+		case "n", "esc":
+			m.page = pageList
+			return m, nil
+		}
+	}
+	return m, nil
+}
+
+func (m model) updateDeletePrefix(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.deletePrefix
+	if msg, ok := msg.(tea.KeyMsg); ok && !s.failures.SettingFilter() {
+		switch msg.String() {
+		case "esc", "backspace", "ctrl+h":
+			if msg.String() == "esc" && s.failures.FilterState() != list.Unfiltered {
+				break
+			}
+			if s.running {
+				// the deletion stops after the current batch
+				s.cancel()
+				return m, nil
+			}
+			m.page = pageList
+			return m.reloadList()
+		}
+	}
+	var cmd tea.Cmd
+	s.failures, cmd = s.failures.Update(msg)
+	return m, cmd
+}
+
+func (m model) viewDeletePrefixConfirm() string {
+	s := m.deletePrefix
+	bc := breadcrumbStyle.Render(fmt.Sprintf("%s : Delete %s", m.viewBreadcrumb(), s.name))
+	target := fmt.Sprintf("s3://%s/%s", m.bucket, s.prefix)
+	var lines []string
+	switch {
+	case s.err != nil:
+		lines = []string{m.ui.viewError(s.err), "", "Press esc to go back."}
+	case s.items != nil:
+		lines = []string{
+			m.ui.viewWarning(fmt.Sprintf("%s %s (%s) under %s will be deleted.", m.ui.format.count(s.total.Objects), s.name, m.ui.format.size(s.total.TotalSize), target)),
+			"",
+			"Delete markers are created instead if versioning is enabled, otherwise the objects cannot be restored.",
+			"",
+			"Press y to delete, n to cancel.",
+		}
+	case s.running:
+		lines = []string{fmt.Sprintf("counting the objects under %s... %s", target, m.ui.format.count(s.scanned))}
+	default:
+		lines = []string{
+			m.ui.viewWarning(fmt.Sprintf("All %s objects (%s) under %s will be deleted.", m.ui.format.count(s.total.Objects), m.ui.format.size(s.total.TotalSize), target)),
+			"",
+			"Delete markers are created instead if versioning is enabled, otherwise the objects cannot be restored.",
+			"Objects created under the prefix after counting are deleted as well.",
+			"",
+			"Type the name of the prefix and press enter to delete, esc to cancel.",
+			"",
+			s.input.View(),
+		}
+		if s.mismatch {
+			lines = append(lines, "", m.ui.viewError(errors.New("the name does not match")))
+		}
+	}
+	return bc + m.ui.styles.list.Render(itemStyle.Render(strings.Join(lines, "\n")))
+}
+
+func (m model) viewDeletePrefixStatus() string {
+	s := m.deletePrefix
+	p := s.progress
+	summary := fmt.Sprintf("%s objects (%s) deleted, %s failed", m.ui.format.count(p.Items-p.Failed), m.ui.format.size(p.Bytes), m.ui.format.count(p.Failed))
+	switch {
+	case s.err != nil:
+		return summary + " " + m.ui.viewError(s.err)
+	case s.running:
+		return "deleting... " + m.ui.viewProgressBar(int64(p.Items), int64(s.total.Objects)) + " " + summary + m.viewThrottled()
+	case s.canceled:
+		return "canceled: " + summary
+	}
+	return "done: " + summary
+}
+
+func (c *uiConfig) viewProgressBar(n, total int64) string {
+	if total <= 0 {
+		return ""
+	}
+	if n > total {
+		n = total
+	}
+	w := int(n * histogramBarWidth / total)
+	bar := c.styles.histogramBar.Render(strings.Repeat(c.styles.histogramBarChar, w)) + strings.Repeat(" ", histogramBarWidth-w)
+	return fmt.Sprintf("[%s] %3d%%", bar, n*100/total)
+}
+
+func (m model) viewDeletePrefix() string {
+	s := m.deletePrefix
+	target := s.prefix
+	if s.items != nil {
+		target = s.name
+	}
+	bc := breadcrumbStyle.Render(fmt.Sprintf("%s : Delete %s : %s", m.viewBreadcrumb(), target, m.viewDeletePrefixStatus()))
+	return bc + m.ui.styles.list.Render(s.failures.View())
+}
@@ -0,0 +1,396 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+var (
+	detailStyle = lipgloss.NewStyle().
+		PaddingLeft(2)
+)
+
+type detailTab int
+
+const (
+	detailTabProperties detailTab = iota
+	detailTabActivity
+)
+
+type detailState struct {
+	id      int
+	item    *stu.ObjectItem
+	head    *stu.ObjectHead
+	loading bool
+	err     error
+	tab     detailTab
+
+	// recent events from CloudTrail, read when the activity tab is opened first
+	events        []*stu.ObjectEvent
+	eventsLoaded  bool
+	eventsLoading bool
+	eventsErr     error
+
+	// verification of the ETag against a local file
+	input     textinput.Model
+	verifying bool
+	cancel    context.CancelFunc
+	running   bool
+	verified  *stu.ETagVerification
+	verifyErr error
+}
+
+type detailHeadMsg struct {
+	id   int
+	head *stu.ObjectHead
+	err  error
+}
+
+type detailEventsMsg struct {
+	id     int
+	events []*stu.ObjectEvent
+	err    error
+}
+
+type detailVerifyMsg struct {
+	id     int
+	result *stu.ETagVerification
+	err    error
+}
+
+func newDetailState() *detailState {
+	input := textinput.NewModel()
+	input.Prompt = "Verify with: "
+	input.Placeholder = "local file path"
+	return &detailState{
+		input:  input,
+		cancel: func() {},
+	}
+}
+
+// leave invalidates the pending results of the object.
+func (s *detailState) leave() {
+	s.id++
+	s.cancel()
+	s.running = false
+	s.verifying = false
+	s.input.Blur()
+}
+
+func (s *detailState) etag() string {
+	if s.head != nil {
+		return s.head.ETag
+	}
+	return s.item.ETag
+}
+
+func (m model) openDetail(item *stu.ObjectItem) (tea.Model, tea.Cmd) {
+	s := m.detail
+	s.id++
+	s.item = item
+	s.head = nil
+	s.err = nil
+	s.verified = nil
+	s.verifyErr = nil
+	s.tab = detailTabProperties
+	s.events = nil
+	s.eventsLoaded = false
+	s.eventsLoading = false
+	s.eventsErr = nil
+	m.page = pageDetail
+	if head, ok := m.objCache.Head(m.bucket, item); ok {
+		s.head = head
+		s.loading = false
+		return m, nil
+	}
+	s.loading = true
+	return m, fetchDetailHead(m.tasks, s.id, m.client, m.bucket, item)
+}
+
+func fetchDetailHead(tasks *stu.TaskManager, id int, client stu.Client, bucket string, item *stu.ObjectItem) tea.Cmd {
+	return taskCmd(tasks, "head "+bucket+"/"+item.ObjectKey(), func(ctx context.Context) tea.Msg {
+		head, err := client.HeadObject(ctx, bucket, item.ObjectKey())
+		return detailHeadMsg{id: id, head: head, err: err}
+	})
+}
+
+func (m model) updateDetailMsg(msg detailHeadMsg) (tea.Model, tea.Cmd) {
+	s := m.detail
+	if msg.id != s.id {
+		return m, nil
+	}
+	s.loading = false
+	s.head = msg.head
+	s.err = msg.err
+	if msg.err == nil {
+		s.item.Head = msg.head
+		m.objCache.PutHead(m.bucket, s.item, msg.head)
+	}
+	return m, nil
+}
+
+func fetchDetailEvents(tasks *stu.TaskManager, id int, client stu.Client, bucket string, item *stu.ObjectItem) tea.Cmd {
+	return taskCmd(tasks, "activity "+bucket+"/"+item.ObjectKey(), func(ctx context.Context) tea.Msg {
+		var events []*stu.ObjectEvent
+		err := stu.ErrActivityNotConfigured
+		if r, ok := client.(stu.ObjectActivityReader); ok {
+			events, err = r.ObjectEvents(ctx, bucket, item.ObjectKey())
+		}
+		return detailEventsMsg{id: id, events: events, err: err}
+	})
+}
+
+func (m model) switchDetailTab() (tea.Model, tea.Cmd) {
+	s := m.detail
+	if s.tab == detailTabActivity {
+		s.tab = detailTabProperties
+		return m, nil
+	}
+	s.tab = detailTabActivity
+	if s.eventsLoaded || s.eventsLoading {
+		return m, nil
+	}
+	if m.offline() {
+		s.eventsErr = stu.ErrOffline
+		return m, nil
+	}
+	s.eventsLoading = true
+	return m, fetchDetailEvents(m.tasks, s.id, m.client, m.bucket, s.item)
+}
+
+func (m model) updateDetailEventsMsg(msg detailEventsMsg) (tea.Model, tea.Cmd) {
+	s := m.detail
+	if msg.id != s.id {
+		return m, nil
+	}
+	s.eventsLoading = false
+	s.eventsLoaded = msg.err == nil
+	s.events = msg.events
+	s.eventsErr = msg.err
+	return m, nil
+}
+
+func (m model) updateDetailVerifyMsg(msg detailVerifyMsg) (tea.Model, tea.Cmd) {
+	s := m.detail
+	if msg.id != s.id {
+		return m, nil
+	}
+	s.running = false
+	s.verified = msg.result
+	s.verifyErr = msg.err
+	return m, nil
+}
+
+func (m model) updateDetail(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.detail
+	if s.verifying {
+		return m.updateDetailVerifyInput(msg)
+	}
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		k := m.ui.keys.Detail
+		switch {
+		case key.Matches(msg, k.Back):
+			s.leave()
+			m.page = pageList
+			return m, nil
+		case key.Matches(msg, k.Preview):
+			s.leave()
+			return m.openPreview()
+		case key.Matches(msg, k.Help):
+			return m.openHelp()
+		case key.Matches(msg, k.Tab):
+			return m.switchDetailTab()
+		case key.Matches(msg, k.Verify):
+			if s.tab != detailTabProperties || s.running || s.etag() == "" {
+				return m, nil
+			}
+			s.verifying = true
+			s.input.SetValue(s.item.Filename())
+			s.input.CursorEnd()
+			s.input.Focus()
+			return m, textinput.Blink
+		}
+	}
+	return m, nil
+}
+
+func (m model) updateDetailVerifyInput(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.detail
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "esc":
+			s.verifying = false
+			s.input.Blur()
+			return m, nil
+		case "enter":
+			s.verifying = false
+			s.input.Blur()
+			path, err := expandPath(s.input.Value())
+			if err != nil {
+				s.verifyErr = err
+				return m, nil
+			}
+			return m, m.startVerify(path)
+		}
+	}
+	var cmd tea.Cmd
+	s.input, cmd = s.input.Update(msg)
+	return m, cmd
+}
+
+func (m model) startVerify(path string) tea.Cmd {
+	s := m.detail
+	ctx, cancel, done := m.tasks.Start("verify " + path)
+	s.cancel = cancel
+	s.running = true
+	s.verified = nil
+	s.verifyErr = nil
+	id, etag, size := s.id, s.etag(), s.item.Size
+	return func() tea.Msg {
+		defer done()
+		result, err := stu.VerifyETag(ctx, path, etag, size)
+		return detailVerifyMsg{id: id, result: result, err: err}
+	}
+}
+
+func (m model) viewDetail() string {
+	s := m.detail
+	status := ""
+	if s.loading || s.eventsLoading {
+		status = " (loading...)" + m.viewThrottled()
+	}
+	bc := breadcrumbStyle.Render(fmt.Sprintf("%s : %s%s", m.viewBreadcrumb(), s.item.Filename(), status))
+	body := m.viewDetailBody()
+	if s.tab == detailTabActivity {
+		body = m.viewDetailActivity()
+	}
+	return bc + m.ui.styles.list.Render(detailStyle.Render(viewDetailTabs(s.tab)+"\n\n"+body))
+}
+
+func viewDetailTabs(current detailTab) string {
+	names := []string{"Properties", "Activity"}
+	for i, name := range names {
+		if detailTab(i) == current {
+			names[i] = helpTitleStyle.Render("[" + name + "]")
+		} else {
+			names[i] = headStyle.Render(" " + name + " ")
+		}
+	}
+	return strings.Join(names, " ") + headStyle.Render("  (tab to switch)")
+}
+
+func (m model) viewDetailActivity() string {
+	s := m.detail
+	switch {
+	case s.eventsErr != nil:
+		if errors.Is(s.eventsErr, stu.ErrActivityNotConfigured) {
+			return m.ui.viewError(s.eventsErr) + "\n\n" + headStyle.Render("set [cloudtrail] in config.toml to read the trail of S3 data events")
+		}
+		return m.ui.viewError(s.eventsErr)
+	case s.eventsLoading:
+		return "Reading the trail..."
+	case len(s.events) == 0:
+		return "No events in the trail"
+	}
+	var b strings.Builder
+	for _, e := range s.events {
+		name := e.Name
+		if e.ErrorCode != "" {
+			name += " (" + e.ErrorCode + ")"
+		}
+		fmt.Fprintf(&b, "%s  %-28s %s\n", m.ui.format.time(e.Time), name, e.Principal)
+		fmt.Fprintf(&b, "%s\n", headStyle.Render(fmt.Sprintf("    from %s, %s", e.SourceIP, e.UserAgent)))
+	}
+	b.WriteString("\n")
+	b.WriteString(headStyle.Render("events are delivered to the trail about 5 minutes after the requests"))
+	return b.String()
+}
+
+func (m model) viewDetailBody() string {
+	s := m.detail
+	var b strings.Builder
+	if s.err != nil {
+		b.WriteString(m.ui.viewError(s.err))
+		b.WriteString("\n\n")
+	}
+	fmt.Fprintf(&b, "Key:           %s\n", s.item.ObjectKey())
+	fmt.Fprintf(&b, "Size:          %s (%s bytes)\n", m.ui.format.size(s.item.Size), m.ui.format.count(int(s.item.Size)))
+	fmt.Fprintf(&b, "Last modified: %s\n", m.ui.format.time(s.item.LastModified))
+	if s.head == nil {
+		fmt.Fprintf(&b, "ETag:          %s\n", s.item.ETag)
+		return b.String()
+	}
+	h := s.head
+	fmt.Fprintf(&b, "ETag:          %s\n", h.ETag)
+	if note := etagNote(h); note != "" {
+		fmt.Fprintf(&b, "               %s\n", headStyle.Render(note))
+	}
+	fmt.Fprintf(&b, "Content type:  %s\n", h.ContentType)
+	fmt.Fprintf(&b, "Storage class: %s\n", h.StorageClass)
+	if h.ServerSideEncryption != "" {
+		fmt.Fprintf(&b, "Encryption:    %s\n", h.ServerSideEncryption)
+	}
+	if h.ReplicationStatus != "" {
+		fmt.Fprintf(&b, "Replication:   %s\n", h.ReplicationStatus)
+	}
+	if len(h.Metadata) > 0 {
+		b.WriteString("\nMetadata:\n")
+		keys := make([]string, 0, len(h.Metadata))
+		for k := range h.Metadata {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(&b, "  %s: %s\n", k, h.Metadata[k])
+		}
+	}
+	b.WriteString("\n")
+	b.WriteString(m.viewDetailVerify())
+	return b.String()
+}
+
+// etagNote explains ETags which are not the MD5 of the content, as they never match a local md5sum.
+func etagNote(h *stu.ObjectHead) string {
+	if parts := stu.MultipartETagParts(h.ETag); parts > 0 {
+		return fmt.Sprintf("multipart upload of %d parts: MD5 of the MD5s of the parts, not the MD5 of the content", parts)
+	}
+	if h.ServerSideEncryption == "aws:kms" {
+		return "encrypted with SSE-KMS: not the MD5 of the content"
+	}
+	return ""
+}
+
+func (m model) viewDetailVerify() string {
+	s := m.detail
+	switch {
+	case s.verifying:
+		return s.input.View()
+	case s.running:
+		return "Verifying..."
+	case s.verifyErr != nil:
+		return m.ui.viewError(s.verifyErr)
+	case s.verified == nil:
+		return headStyle.Render("press v to verify the ETag with a local file")
+	}
+	r := s.verified
+	switch {
+	case r.SizeMismatch:
+		return m.ui.viewError(errors.New("size of the local file differs from the object"))
+	case !r.Match && stu.MultipartETagParts(s.etag()) > 0:
+		return m.ui.viewError(errors.New("ETag does not match with any of the likely part sizes"))
+	case !r.Match:
+		return m.ui.viewError(fmt.Errorf("ETag does not match: %s (local)", r.Local))
+	case stu.MultipartETagParts(r.Local) > 1:
+		return fmt.Sprintf("ETag matches (part size %s)", m.ui.format.size(r.PartSize))
+	}
+	return "ETag matches"
+}
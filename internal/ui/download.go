@@ -0,0 +1,173 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lusingander/stu/internal/config"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+const (
+	// objects downloaded in parallel by the batch download
+	downloadConcurrency = 4
+)
+
+type downloadDoneMsg struct {
+	item *stu.ObjectItem
+	path string
+	err  error
+}
+
+type downloadMatchedDoneMsg struct {
+	dir    string
+	result *stu.DownloadResult
+	err    error
+}
+
+func (m model) openDownloadInput() (tea.Model, tea.Cmd) {
+	if items, ok := m.matchedObjects(); ok {
+		return m.openDownloadMatchedInput(items)
+	}
+	item, ok := m.selectedFile()
+	if !ok {
+		return m, nil
+	}
+	title := fmt.Sprintf("Download %s (%s)", item.Filename(), m.ui.format.size(item.Size))
+	p, cmd := newInputPopup(title, "Save to: ", sanitizeFilename(item.Filename()), func(m model, value string) (model, tea.Cmd, error) {
+		path, err := downloadPath(value, item)
+		if err != nil {
+			return m, nil, err
+		}
+		m.status = fmt.Sprintf("downloading %s...", item.Filename())
+		return m, downloadObject(m.tasks, m.client, m.bucket, item, path, m.download), nil
+	})
+	m.popup = p
+	return m, cmd
+}
+
+// downloadPath resolves the path typed by the user, an existing directory (or empty for the current one)
+// is completed with the filename.
+// Existing files are never overwritten.
+func downloadPath(value string, item *stu.ObjectItem) (string, error) {
+	path, err := expandPath(value)
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, sanitizeFilename(item.Filename()))
+	}
+	if _, err := os.Stat(path); err == nil {
+		return "", fmt.Errorf("file already exists: %s", path)
+	}
+	return path, nil
+}
+
+func downloadObject(tasks *stu.TaskManager, client stu.Client, bucket string, item *stu.ObjectItem, path string, cfg config.DownloadConfig) tea.Cmd {
+	return taskCmd(tasks, "download "+bucket+"/"+item.ObjectKey(), func(ctx context.Context) tea.Msg {
+		err := saveObject(ctx, client, bucket, item, path, cfg)
+		return downloadDoneMsg{item: item, path: path, err: err}
+	})
+}
+
+// saveObject writes the object to a new file, large objects are downloaded in parts in parallel.
+func saveObject(ctx context.Context, client stu.Client, bucket string, item *stu.ObjectItem, path string, cfg config.DownloadConfig) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	// allocate the whole file first as parts are written at their offsets
+	err = f.Truncate(item.Size)
+	if err == nil {
+		var n int64
+		n, err = stu.DownloadObjectParallel(ctx, client, bucket, item.ObjectKey(), item.Size, f, cfg.PartSize, cfg.Concurrency, nil)
+		if err == nil {
+			// the object may have shrunk since it was listed, drop the rest of the allocation
+			err = f.Truncate(n)
+		}
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return err
+}
+
+// openDownloadMatchedInput downloads the objects matched by the date filter into a directory.
+func (m model) openDownloadMatchedInput(items []*stu.ObjectItem) (tea.Model, tea.Cmd) {
+	if len(items) == 0 {
+		m.status = "no objects to download"
+		return m, nil
+	}
+	var total int64
+	for _, item := range items {
+		total += item.Size
+	}
+	title := fmt.Sprintf("Download %d objects modified %s (%s)", len(items), m.dateFilter.applied, m.ui.format.size(total))
+	p, cmd := newInputPopup(title, "Save to: ", ".", func(m model, value string) (model, tea.Cmd, error) {
+		dir, err := downloadDir(value, m.currentPrefix(), items)
+		if err != nil {
+			return m, nil, err
+		}
+		m.status = fmt.Sprintf("downloading %d objects...", len(items))
+		return m, downloadMatched(m.tasks, m.client, m.bucket, m.currentPrefix(), items, dir), nil
+	})
+	m.popup = p
+	return m, cmd
+}
+
+// downloadDir resolves the directory typed by the user (empty for the current one).
+// Existing files are never overwritten, so it fails if any of the objects already exists in it.
+func downloadDir(value, prefix string, items []*stu.ObjectItem) (string, error) {
+	dir, err := expandPath(value)
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+		return "", fmt.Errorf("not a directory: %s", dir)
+	}
+	for _, item := range items {
+		path := filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(item.ObjectKey(), prefix)))
+		if _, err := os.Stat(path); err == nil {
+			return "", fmt.Errorf("file already exists: %s", path)
+		}
+	}
+	return dir, nil
+}
+
+func downloadMatched(tasks *stu.TaskManager, client stu.Client, bucket, prefix string, items []*stu.ObjectItem, dir string) tea.Cmd {
+	return taskCmd(tasks, fmt.Sprintf("download %d objects from %s/%s", len(items), bucket, prefix), func(ctx context.Context) tea.Msg {
+		result, err := stu.DownloadItems(ctx, client, bucket, prefix, items, dir, downloadConcurrency, nil)
+		return downloadMatchedDoneMsg{dir: dir, result: result, err: err}
+	})
+}
+
+func (m model) updateDownloadMatchedMsg(msg downloadMatchedDoneMsg) (tea.Model, tea.Cmd) {
+	r := msg.result
+	summary := fmt.Sprintf("downloaded %d objects (%s) to %s", r.Downloaded, m.ui.format.size(r.Bytes), msg.dir)
+	switch {
+	case msg.err != nil:
+		m.status = summary + " " + m.ui.viewError(msg.err)
+	case len(r.Failed) > 0:
+		f := r.Failed[0]
+		m.status = summary + " " + m.ui.viewWarning(fmt.Sprintf("%d failed (%s: %v)", len(r.Failed), f.Key, f.Err))
+	default:
+		m.status = summary
+	}
+	return m, nil
+}
+
+func (m model) updateDownloadMsg(msg downloadDoneMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.status = m.ui.viewError(msg.err)
+		return m, nil
+	}
+	m.status = fmt.Sprintf("downloaded %s to %s", msg.item.Filename(), msg.path)
+	return m, nil
+}
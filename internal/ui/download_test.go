@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lusingander/stu/internal/config"
+	"github.com/lusingander/stu/internal/mock"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+func TestSaveObject(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 100)
+	tests := []struct {
+		name       string
+		listedSize int64
+		cfg        config.DownloadConfig
+	}{
+		{name: "same size", listedSize: 1000},
+		{name: "shrunk", listedSize: 5000},
+		{name: "grown", listedSize: 10},
+		{name: "parallel same size", listedSize: 1000, cfg: config.DownloadConfig{PartSize: 64, Concurrency: 4}},
+		{name: "parallel shrunk", listedSize: 5000, cfg: config.DownloadConfig{PartSize: 64, Concurrency: 4}},
+		{name: "parallel grown", listedSize: 100, cfg: config.DownloadConfig{PartSize: 64, Concurrency: 4}},
+	}
+	client := mock.NewClient()
+	client.PutObject("bucket", &mock.Object{Key: "obj", Content: content})
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "obj")
+		// the listing is older than the object
+		item := stu.NewFileObjectItem("obj", tt.listedSize, time.Time{})
+		if err := saveObject(context.Background(), client, "bucket", item, path, tt.cfg); err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		got, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, content) {
+			t.Errorf("%s: got %d bytes, want %d bytes", tt.name, len(got), len(content))
+		}
+	}
+}
+
+func TestDownloadDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "exists.txt"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	item := func(key string) *stu.ObjectItem {
+		return stu.NewFileObjectItem(key, 1, time.Time{})
+	}
+	tests := []struct {
+		name    string
+		value   string
+		items   []*stu.ObjectItem
+		wantErr bool
+	}{
+		{"new files", dir, []*stu.ObjectItem{item("dir/a.txt"), item("dir/b.txt")}, false},
+		{"existing file", dir, []*stu.ObjectItem{item("dir/a.txt"), item("dir/exists.txt")}, true},
+		{"not a directory", filepath.Join(dir, "exists.txt"), []*stu.ObjectItem{item("dir/a.txt")}, true},
+		{"new directory", filepath.Join(dir, "new"), []*stu.ObjectItem{item("dir/exists.txt")}, false},
+	}
+	for _, tt := range tests {
+		got, err := downloadDir(tt.value, "dir/", tt.items)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: downloadDir() returned error: %v", tt.name, err)
+			continue
+		}
+		if err == nil && got != filepath.Clean(tt.value) {
+			t.Errorf("%s: downloadDir() = %q, want %q", tt.name, got, tt.value)
+		}
+	}
+}
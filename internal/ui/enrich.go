@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+const (
+	enrichConcurrency = 4
+	objectCacheSize   = 1024
+	renderCacheBytes  = 32 * 1024 * 1024
+)
+
+var (
+	headStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("244"))
+)
+
+type enrichMsg struct {
+	result *stu.HeadResult
+}
+
+func waitEnrichMsg(ch <-chan *stu.HeadResult) tea.Cmd {
+	return func() tea.Msg {
+		return enrichMsg{result: <-ch}
+	}
+}
+
+// enrichVisible requests HeadObject of files without it, the current page first.
+// Cached heads are set immediately.
+func (m model) enrichVisible() {
+	if m.enricher == nil || m.bucket == "" {
+		return
+	}
+	items := m.list.VisibleItems()
+	start, end := m.list.Paginator.GetSliceBounds(len(items))
+	visible := make([]*stu.ObjectItem, 0)
+	rest := make([]*stu.ObjectItem, 0)
+	for i, item := range items {
+		obj, ok := item.(*stu.ObjectItem)
+		if !ok || obj.Dir || obj.Head != nil {
+			continue
+		}
+		if head, ok := m.objCache.Head(m.bucket, obj); ok {
+			obj.Head = head
+			continue
+		}
+		if start <= i && i < end {
+			visible = append(visible, obj)
+		} else {
+			rest = append(rest, obj)
+		}
+	}
+	m.enricher.Enqueue(m.bucket, visible, rest)
+}
+
+func (m model) updateEnrichMsg(msg enrichMsg) (tea.Model, tea.Cmd) {
+	r := msg.result
+	if r.Err == nil && r.Bucket == m.bucket {
+		for _, item := range m.dateFilter.baseItems(m.list) {
+			if obj, ok := item.(*stu.ObjectItem); ok && obj.ObjectKey() == r.Key {
+				obj.Head = r.Head
+				m.objCache.PutHead(r.Bucket, obj, r.Head)
+				break
+			}
+		}
+	}
+	return m, waitEnrichMsg(m.enricher.Results())
+}
+
+func viewHead(h *stu.ObjectHead) string {
+	if h == nil {
+		return ""
+	}
+	ss := []string{h.StorageClass}
+	if h.ServerSideEncryption != "" {
+		ss = append(ss, h.ServerSideEncryption)
+	}
+	if h.ReplicationStatus != "" {
+		ss = append(ss, h.ReplicationStatus)
+	}
+	return headStyle.Render("  " + strings.Join(ss, ", "))
+}
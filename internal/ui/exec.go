@@ -0,0 +1,109 @@
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/termenv"
+	"golang.org/x/term"
+)
+
+const (
+	// longer than a frame of the renderer
+	handoffDelay = 50 * time.Millisecond
+)
+
+// termOutput is the output of the program, escape sequences written outside of the frames
+// (such as OSC52 of the clipboard) go to the same file so that the writes do not interleave.
+var termOutput = os.Stdout
+
+// terminal hands the terminal to an external command or suspension while the program keeps running,
+// so the commands in flight and their messages are kept as they are.
+type terminal struct {
+	input   *ttyInput
+	program *tea.Program
+	signals *terminator
+}
+
+// handoff runs fn with the terminal restored, it blocks the program until fn returns.
+// The program does not render while it is blocked, the frame written just before is waited to be flushed.
+func (t *terminal) handoff(fn func() error) error {
+	time.Sleep(handoffDelay)
+	t.signals.setHandoff(true)
+	defer t.signals.setHandoff(false)
+
+	if err := t.input.pause(); err != nil {
+		return err
+	}
+	t.program.ExitAltScreen()
+	termenv.ShowCursor()
+
+	err := fn()
+
+	if rerr := t.input.resume(); err == nil {
+		err = rerr
+	}
+	termenv.HideCursor()
+	t.program.EnterAltScreen()
+	return err
+}
+
+// repaint makes the program draw the whole screen again, as it does on resize.
+func repaint() tea.Msg {
+	w, h, err := term.GetSize(int(termOutput.Fd()))
+	if err != nil {
+		return nil
+	}
+	return tea.WindowSizeMsg{Width: w, Height: h}
+}
+
+// execRequest is a command which needs the terminal.
+type execRequest struct {
+	args    []string
+	wait    bool
+	cleanup []string
+}
+
+func (r *execRequest) run() error {
+	defer func() {
+		for _, path := range r.cleanup {
+			os.Remove(path)
+		}
+	}()
+
+	cmd := exec.Command(r.args[0], r.args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+
+	if r.wait {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "\n%s\n", err)
+		}
+		fmt.Print("\nPress Enter to return to stu...")
+		bufio.NewReader(os.Stdin).ReadString('\n')
+		return nil
+	}
+	return err
+}
+
+// runExec runs the request with the terminal, the model is not changed while it runs.
+func (m model) runExec(r *execRequest) (tea.Model, tea.Cmd) {
+	m.status = ""
+	if err := m.terminal.handoff(r.run); err != nil {
+		m.status = m.ui.viewError(err)
+	}
+	return m, repaint
+}
+
+func (m model) suspend() (tea.Model, tea.Cmd) {
+	if err := m.terminal.handoff(suspendProcess); err != nil {
+		m.status = m.ui.viewError(err)
+	}
+	return m, repaint
+}
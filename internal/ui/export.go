@@ -0,0 +1,192 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+type exportState struct {
+	input textinput.Model
+	prev  page
+	msg   string
+	err   error
+	// the existing file waiting for the confirmation to overwrite
+	overwrite string
+}
+
+func newExportState() *exportState {
+	input := textinput.NewModel()
+	input.Prompt = "Export to: "
+	input.Placeholder = "file path (.json or .csv)"
+	return &exportState{
+		input: input,
+	}
+}
+
+func (m model) defaultExportFilename() string {
+	name := "stu-"
+	if m.page == pageStats {
+		name += "stats-"
+	} else {
+		name += "report-"
+	}
+	name += m.bucket
+	if prefix := strings.TrimSuffix(m.currentPrefix(), "/"); prefix != "" {
+		name += "-" + strings.ReplaceAll(prefix, "/", "-")
+	}
+	return sanitizeFilename(name) + ".json"
+}
+
+func (m model) openExport() (tea.Model, tea.Cmd) {
+	s := m.export
+	s.prev = m.page
+	s.msg = ""
+	s.err = nil
+	s.overwrite = ""
+	s.input.SetValue(m.defaultExportFilename())
+	s.input.CursorEnd()
+	s.input.Focus()
+	m.page = pageExport
+	return m, textinput.Blink
+}
+
+func (m model) writeExport(path string) error {
+	format, err := stu.ExportFormatFromPath(path)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, func(w io.Writer) error {
+		return m.exportTo(w, format)
+	})
+}
+
+func (m model) exportTo(f io.Writer, format stu.ExportFormat) error {
+	switch m.export.prev {
+	case pageStats:
+		return stu.ExportStats(f, m.stats.stats, format)
+	case pageReport:
+		if m.report.kind == stu.ReportDuplicates {
+			return stu.ExportDuplicates(f, m.report.groups, format)
+		}
+		items := m.report.results.Items()
+		objs := make([]*stu.ObjectItem, len(items))
+		for i, item := range items {
+			objs[i] = item.(*reportResultItem).ObjectItem
+		}
+		return stu.ExportObjects(f, objs, format)
+	}
+	return errors.New("nothing to export")
+}
+
+// writeFileAtomic writes to a temporary file in the same directory and renames it to path,
+// so that the existing file is kept and no partial file is left if writing fails.
+func writeFileAtomic(path string, write func(w io.Writer) error) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	err = write(f)
+	if err == nil {
+		// the temporary file is created with 0600
+		err = f.Chmod(0644)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+func (m model) updateExport(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.export
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		if s.overwrite != "" {
+			switch msg.String() {
+			case "y":
+				return m.finishExport(s.overwrite)
+			case "n", "esc":
+				s.overwrite = ""
+				return m, nil
+			}
+			return m, nil
+		}
+		switch msg.String() {
+		case "esc":
+			s.input.Blur()
+			m.page = s.prev
+			return m, nil
+		case "enter":
+			path, err := expandPath(s.input.Value())
+			if err != nil {
+				s.err = err
+				return m, nil
+			}
+			if _, err := os.Stat(path); err == nil {
+				s.err = nil
+				s.overwrite = path
+				return m, nil
+			}
+			return m.finishExport(path)
+		}
+	}
+	var cmd tea.Cmd
+	s.input, cmd = s.input.Update(msg)
+	return m, cmd
+}
+
+func (m model) finishExport(path string) (tea.Model, tea.Cmd) {
+	s := m.export
+	s.overwrite = ""
+	if err := m.writeExport(path); err != nil {
+		s.err = err
+		return m, nil
+	}
+	s.input.Blur()
+	s.err = nil
+	s.msg = fmt.Sprintf("exported to %s", path)
+	m.page = s.prev
+	return m, nil
+}
+
+func (m model) viewExportStatus() string {
+	s := m.export
+	if m.page == pageExport && s.overwrite != "" {
+		return m.ui.viewWarning(fmt.Sprintf("%s exists, overwrite? (y/n)", s.overwrite))
+	}
+	if m.page == pageExport {
+		v := s.input.View()
+		if s.err != nil {
+			v += "  " + m.ui.viewError(s.err)
+		}
+		return v
+	}
+	if s.prev == m.page && s.msg != "" {
+		return " " + s.msg
+	}
+	return ""
+}
+
+func (m model) viewExport() string {
+	bc := breadcrumbStyle.Render(m.viewExportStatus())
+	switch m.export.prev {
+	case pageStats:
+		return bc + m.viewStatsBody()
+	case pageReport:
+		return bc + m.viewReportBody()
+	}
+	return bc
+}
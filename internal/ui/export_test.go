@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		write    func(w io.Writer) error
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:  "new file",
+			write: func(w io.Writer) error { _, err := io.WriteString(w, "new"); return err },
+			want:  "new",
+		},
+		{
+			name:     "overwrite",
+			existing: "old content",
+			write:    func(w io.Writer) error { _, err := io.WriteString(w, "new"); return err },
+			want:     "new",
+		},
+		{
+			name:    "failure leaves no file",
+			write:   func(w io.Writer) error { io.WriteString(w, "part"); return errors.New("failed") },
+			wantErr: true,
+		},
+		{
+			name:     "failure keeps the existing file",
+			existing: "old content",
+			write:    func(w io.Writer) error { io.WriteString(w, "part"); return errors.New("failed") },
+			want:     "old content",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "export.json")
+		if tt.existing != "" {
+			if err := ioutil.WriteFile(path, []byte(tt.existing), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		err := writeFileAtomic(path, tt.write)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		got, rerr := ioutil.ReadFile(path)
+		switch {
+		case tt.want == "" && !os.IsNotExist(rerr):
+			t.Errorf("%s: file is left: %q", tt.name, got)
+		case tt.want != "" && string(got) != tt.want:
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+		entries, _ := ioutil.ReadDir(dir)
+		if n := len(entries); (tt.want == "" && n != 0) || (tt.want != "" && n != 1) {
+			t.Errorf("%s: temporary file is left: %d entries", tt.name, n)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lusingander/stu/internal/config"
+)
+
+var (
+	binarySizeUnits  = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB"}
+	decimalSizeUnits = []string{"B", "KB", "MB", "GB", "TB", "PB"}
+)
+
+type formatOptions struct {
+	decimalSize        bool
+	thousandsSeparator string
+	decimalSeparator   string
+	hour12             bool
+	dateLayout         string
+	// can be replaced to render times independently of the local time zone
+	location *time.Location
+}
+
+func newFormatOptions(cfg config.FormatConfig) formatOptions {
+	return formatOptions{
+		decimalSize:        cfg.SizeUnit == "decimal",
+		thousandsSeparator: cfg.ThousandsSeparator,
+		decimalSeparator:   cfg.DecimalSeparator,
+		hour12:             cfg.Clock == "12h",
+		dateLayout:         cfg.DateFormat,
+		location:           time.Local,
+	}
+}
+
+func (o formatOptions) size(n int64) string {
+	units, base := binarySizeUnits, 1024.0
+	if o.decimalSize {
+		units, base = decimalSizeUnits, 1000.0
+	}
+	if float64(n) < base {
+		return fmt.Sprintf("%s %s", o.count(int(n)), units[0])
+	}
+	f := float64(n)
+	i := 0
+	for f >= base && i < len(units)-1 {
+		f /= base
+		i++
+	}
+	s := strconv.FormatFloat(f, 'f', 1, 64)
+	return strings.Replace(s, ".", o.decimalSeparator, 1) + " " + units[i]
+}
+
+func (o formatOptions) count(n int) string {
+	if n < 0 {
+		return "-" + o.count(-n)
+	}
+	s := strconv.Itoa(n)
+	if o.thousandsSeparator == "" || len(s) <= 3 {
+		return s
+	}
+	var b strings.Builder
+	head := len(s) % 3
+	if head > 0 {
+		b.WriteString(s[:head])
+	}
+	for i := head; i < len(s); i += 3 {
+		if b.Len() > 0 {
+			b.WriteString(o.thousandsSeparator)
+		}
+		b.WriteString(s[i : i+3])
+	}
+	return b.String()
+}
+
+func (o formatOptions) time(t time.Time) string {
+	layout := o.dateLayout + " 15:04:05"
+	if o.hour12 {
+		layout = o.dateLayout + " 03:04:05 PM"
+	}
+	return t.In(o.location).Format(layout)
+}
@@ -0,0 +1,89 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	helpTitleStyle = lipgloss.NewStyle().
+			Bold(true)
+	helpKeyStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("170"))
+)
+
+type helpState struct {
+	view viewport.Model
+	prev page
+}
+
+func newHelpState() *helpState {
+	return &helpState{}
+}
+
+func (s *helpState) setSize(width, height int) {
+	s.view.Width = width
+	s.view.Height = height
+}
+
+func (m model) openHelp() (tea.Model, tea.Cmd) {
+	s := m.help
+	s.prev = m.page
+	s.view.SetContent(viewHelpSections(m.ui.keys.helpSections()))
+	s.view.GotoTop()
+	m.page = pageHelp
+	return m, nil
+}
+
+func (m model) updateHelp(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.help
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "esc", "backspace", "ctrl+h", "?", "q":
+			m.page = s.prev
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	s.view, cmd = s.view.Update(msg)
+	return m, cmd
+}
+
+func viewHelpSections(sections []helpSection) string {
+	width := 0
+	for _, sec := range sections {
+		for _, b := range sec.bindings {
+			if !b.Enabled() {
+				continue
+			}
+			if w := lipgloss.Width(b.Help().Key); w > width {
+				width = w
+			}
+		}
+	}
+	var sb strings.Builder
+	for i, sec := range sections {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("  " + helpTitleStyle.Render(sec.title) + "\n")
+		for _, b := range sec.bindings {
+			if !b.Enabled() {
+				continue
+			}
+			h := b.Help()
+			pad := strings.Repeat(" ", width-lipgloss.Width(h.Key))
+			fmt.Fprintf(&sb, "    %s%s  %s\n", helpKeyStyle.Render(h.Key), pad, h.Desc)
+		}
+	}
+	return sb.String()
+}
+
+func (m model) viewHelp() string {
+	bc := breadcrumbStyle.Render("Help : press esc or ? to go back")
+	return bc + m.ui.styles.list.Render(m.help.view.View())
+}
@@ -0,0 +1,239 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lusingander/stu/internal/config"
+	"github.com/lusingander/stu/internal/stu"
+	"github.com/lusingander/stu/internal/tempfile"
+)
+
+type hookState struct {
+	hooks []config.HookConfig
+	menu  list.Model
+	// object selected when the menu is opened
+	target *stu.ObjectItem
+}
+
+type hookMenuItem struct {
+	hook config.HookConfig
+}
+
+func (i *hookMenuItem) Text() string {
+	if i.hook.Key != "" {
+		return fmt.Sprintf("[%s] %s", i.hook.Key, i.hook.Name)
+	}
+	return i.hook.Name
+}
+
+func (i *hookMenuItem) FilterValue() string {
+	return i.hook.Name
+}
+
+type hookDownloadedMsg struct {
+	hook   config.HookConfig
+	bucket string
+	item   *stu.ObjectItem
+	path   string
+	err    error
+}
+
+func newHookState(ui *uiConfig, hooks []config.HookConfig) *hookState {
+	items := make([]list.Item, len(hooks))
+	for i, hook := range hooks {
+		items[i] = &hookMenuItem{hook: hook}
+	}
+	return &hookState{
+		hooks: hooks,
+		menu:  newList(ui, items),
+	}
+}
+
+func (s *hookState) setSize(width, height int) {
+	s.menu.SetSize(width, height)
+}
+
+func (s *hookState) find(key string) (config.HookConfig, bool) {
+	for _, hook := range s.hooks {
+		if hook.Key != "" && hook.Key == key {
+			return hook, true
+		}
+	}
+	return config.HookConfig{}, false
+}
+
+func (m model) selectedFile() (*stu.ObjectItem, bool) {
+	i, ok := m.list.SelectedItem().(*stu.ObjectItem)
+	if !ok || i.Dir {
+		return nil, false
+	}
+	return i, true
+}
+
+func (m model) openHookMenu() (tea.Model, tea.Cmd) {
+	item, ok := m.selectedFile()
+	if !ok || len(m.hook.hooks) == 0 {
+		return m, nil
+	}
+	m.hook.target = item
+	m.hook.menu.ResetSelected()
+	m.page = pageHookMenu
+	return m, nil
+}
+
+func (m model) runHook(hook config.HookConfig, item *stu.ObjectItem) (tea.Model, tea.Cmd) {
+	if !hookNeedsFile(hook.Command) {
+		return m.execHook(hook, m.bucket, item, "")
+	}
+	m.status = fmt.Sprintf("downloading %s...", item.Filename())
+	return m, downloadForHook(m.tasks, m.temp, m.client, m.bucket, hook, item)
+}
+
+func downloadForHook(tasks *stu.TaskManager, temp *tempfile.Manager, client stu.Client, bucket string, hook config.HookConfig, item *stu.ObjectItem) tea.Cmd {
+	return taskCmd(tasks, "download "+bucket+"/"+item.ObjectKey(), func(ctx context.Context) tea.Msg {
+		path, err := downloadTemp(ctx, temp, client, bucket, item)
+		return hookDownloadedMsg{hook: hook, bucket: bucket, item: item, path: path, err: err}
+	})
+}
+
+func downloadTemp(ctx context.Context, temp *tempfile.Manager, client stu.Client, bucket string, item *stu.ObjectItem) (string, error) {
+	f, err := temp.Create(sanitizeFilename(item.Filename()))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	_, err = stu.DownloadObject(ctx, client, bucket, item.ObjectKey(), f, nil)
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+func (m model) updateHookMsg(msg hookDownloadedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.status = m.ui.viewError(msg.err)
+		return m, nil
+	}
+	return m.execHook(msg.hook, msg.bucket, msg.item, msg.path)
+}
+
+// execHook runs the hook with the downloaded file at path, or without a file if path is empty.
+func (m model) execHook(hook config.HookConfig, bucket string, item *stu.ObjectItem, path string) (tea.Model, tea.Cmd) {
+	var cleanup []string
+	if path != "" {
+		cleanup = append(cleanup, path)
+	}
+	args, err := hookArgs(hook.Command, bucket, item.ObjectKey(), path)
+	if err != nil {
+		for _, p := range cleanup {
+			os.Remove(p)
+		}
+		m.status = m.ui.viewError(err)
+		return m, nil
+	}
+	return m.runExec(&execRequest{
+		args:    args,
+		wait:    hook.Wait,
+		cleanup: cleanup,
+	})
+}
+
+// hookNeedsFile reports whether the command refers to the downloaded file,
+// the object is not downloaded otherwise.
+func hookNeedsFile(command string) bool {
+	return strings.Contains(command, "{path}")
+}
+
+func (m model) updateHookMenu(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.hook
+	if msg, ok := msg.(tea.KeyMsg); ok && !s.menu.SettingFilter() {
+		switch msg.String() {
+		case "esc", "backspace", "ctrl+h":
+			m.page = pageList
+			return m, nil
+		case "enter":
+			if i, ok := s.menu.SelectedItem().(*hookMenuItem); ok {
+				m.page = pageList
+				return m.runHook(i.hook, s.target)
+			}
+		}
+	}
+	var cmd tea.Cmd
+	s.menu, cmd = s.menu.Update(msg)
+	return m, cmd
+}
+
+func (m model) viewHookMenu() string {
+	bc := breadcrumbStyle.Render(fmt.Sprintf("%s : Run command on %s", m.viewBreadcrumb(), m.hook.target.Filename()))
+	return bc + m.ui.styles.list.Render(m.hook.menu.View())
+}
+
+// hookArgs returns the command line of the hook with the placeholders replaced.
+// cmd.exe cannot quote untrusted values safely, so on Windows the command is run without a shell
+// after splitting it into arguments at spaces outside of double quotes.
+// Values are quoted by stu, so quotes written around a placeholder in the command
+// ("{path}" or '{path}') are dropped instead of being passed literally.
+func hookArgs(command, bucket, key, path string) ([]string, error) {
+	if runtime.GOOS != "windows" {
+		var oldnew []string
+		for _, p := range [][2]string{{"{bucket}", bucket}, {"{key}", key}, {"{path}", path}} {
+			q := shellQuote(p[1])
+			oldnew = append(oldnew, `"`+p[0]+`"`, q, "'"+p[0]+"'", q, p[0], q)
+		}
+		r := strings.NewReplacer(oldnew...)
+		return []string{"sh", "-c", r.Replace(command)}, nil
+	}
+	args, err := splitCommand(command)
+	if err != nil {
+		return nil, err
+	}
+	r := strings.NewReplacer("{bucket}", bucket, "{key}", key, "{path}", path)
+	for i, arg := range args {
+		args[i] = r.Replace(arg)
+	}
+	return args, nil
+}
+
+func splitCommand(command string) ([]string, error) {
+	args := make([]string, 0)
+	var b strings.Builder
+	inArg, quoted := false, false
+	for _, r := range command {
+		switch {
+		case r == '"':
+			inArg, quoted = true, !quoted
+		case (r == ' ' || r == '\t') && !quoted:
+			if inArg {
+				args = append(args, b.String())
+				b.Reset()
+				inArg = false
+			}
+		default:
+			b.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote in command: %s", command)
+	}
+	if inArg {
+		args = append(args, b.String())
+	}
+	if len(args) == 0 {
+		return nil, errors.New("empty command")
+	}
+	return args, nil
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    []string
+		wantErr bool
+	}{
+		{name: "words", command: "code --wait {path}", want: []string{"code", "--wait", "{path}"}},
+		{name: "tabs and spaces", command: "a \t b  c", want: []string{"a", "b", "c"}},
+		{name: "quoted", command: `notepad "C:\Program Files\x.txt" {path}`, want: []string{"notepad", `C:\Program Files\x.txt`, "{path}"}},
+		{name: "quoted part", command: `cmd --name="a b"`, want: []string{"cmd", "--name=a b"}},
+		{name: "empty quoted", command: `cmd ""`, want: []string{"cmd", ""}},
+		{name: "unterminated", command: `cmd "a b`, wantErr: true},
+		{name: "empty", command: "  ", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := splitCommand(tt.command)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHookArgs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks are run by sh on other platforms only")
+	}
+	tests := []struct {
+		name    string
+		command string
+		key     string
+		path    string
+		want    string
+	}{
+		{name: "placeholders", command: "echo {bucket} {key} {path}", key: "a/b.txt", path: "/tmp/b.txt", want: "echo 'bkt' 'a/b.txt' '/tmp/b.txt'"},
+		{name: "spaces", command: "cat {path}", path: "/tmp/a b.txt", want: "cat '/tmp/a b.txt'"},
+		{name: "single quote", command: "echo {key}", key: "it's", want: `echo 'it'\''s'`},
+		{name: "injection", command: "echo {key}", key: "$(rm -rf ~); `x`", want: "echo '$(rm -rf ~); `x`'"},
+		{name: "double quoted placeholder", command: `open "{path}"`, path: "/tmp/a b", want: "open '/tmp/a b'"},
+		{name: "single quoted placeholder", command: "open '{path}'", path: "/tmp/a'b", want: `open '/tmp/a'\''b'`},
+		{name: "no placeholders", command: "ls -l", want: "ls -l"},
+	}
+	for _, tt := range tests {
+		got, err := hookArgs(tt.command, "bkt", tt.key, tt.path)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		want := []string{"sh", "-c", tt.want}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %q, want %q", tt.name, got, want)
+		}
+	}
+}
+
+func TestHookNeedsFile(t *testing.T) {
+	tests := []struct {
+		command string
+		want    bool
+	}{
+		{command: "code {path}", want: true},
+		{command: `open "{path}"`, want: true},
+		{command: "aws s3 presign s3://{bucket}/{key}", want: false},
+	}
+	for _, tt := range tests {
+		if got := hookNeedsFile(tt.command); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.command, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package ui
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/containerd/console"
+)
+
+const (
+	inputPollInterval = 50 * time.Millisecond
+)
+
+// ttyInput is the input of the program, which can be paused to hand the terminal to an external command.
+// The program reads the terminal all the time, so it is read only after polling
+// to be sure that no read is in progress while the command runs.
+type ttyInput struct {
+	file    *os.File
+	console console.Console
+
+	mu     sync.Mutex
+	paused bool
+	closed bool
+}
+
+// newTTYInput opens the terminal in raw mode, the terminal is opened directly if stdin is redirected.
+func newTTYInput() (*ttyInput, error) {
+	f, err := openTTY()
+	if err != nil {
+		return nil, err
+	}
+	in := &ttyInput{file: f}
+	// the console is nil if the input is not a terminal, like the program does
+	if c, err := openConsole(f); err == nil {
+		in.console = c
+		if err := c.SetRaw(); err != nil {
+			return nil, err
+		}
+	}
+	return in, nil
+}
+
+func (in *ttyInput) Read(p []byte) (int, error) {
+	for {
+		in.mu.Lock()
+		if in.closed {
+			in.mu.Unlock()
+			return 0, io.EOF
+		}
+		if in.paused {
+			in.mu.Unlock()
+			time.Sleep(inputPollInterval)
+			continue
+		}
+		ready, err := waitInput(in.file, inputPollInterval)
+		if err == nil && ready {
+			var n int
+			n, err = in.file.Read(p)
+			in.mu.Unlock()
+			return n, err
+		}
+		in.mu.Unlock()
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// pause stops reading and restores the terminal to cooked mode.
+func (in *ttyInput) pause() error {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.paused = true
+	if in.console != nil {
+		return in.console.Reset()
+	}
+	return nil
+}
+
+func (in *ttyInput) resume() error {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.paused = false
+	if in.console != nil {
+		return in.console.SetRaw()
+	}
+	return nil
+}
+
+// close restores the terminal, the program reading the input gets EOF.
+func (in *ttyInput) close() error {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.closed = true
+	if in.console != nil {
+		in.console.Reset()
+	}
+	if in.file != os.Stdin {
+		return in.file.Close()
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+//go:build !windows
+// +build !windows
+
+package ui
+
+import (
+	"os"
+	"time"
+
+	"github.com/containerd/console"
+	"golang.org/x/sys/unix"
+	"golang.org/x/term"
+)
+
+func openTTY() (*os.File, error) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		return os.Stdin, nil
+	}
+	return os.Open("/dev/tty")
+}
+
+func openConsole(f *os.File) (console.Console, error) {
+	return console.ConsoleFromFile(f)
+}
+
+// waitInput reports whether f can be read without blocking within the timeout.
+func waitInput(f *os.File, timeout time.Duration) (bool, error) {
+	fds := []unix.PollFd{{Fd: int32(f.Fd()), Events: unix.POLLIN}}
+	n, err := unix.Poll(fds, int(timeout/time.Millisecond))
+	if err == unix.EINTR {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,80 @@
+//go:build windows
+// +build windows
+
+package ui
+
+import (
+	"os"
+	"time"
+	"unsafe"
+
+	"github.com/containerd/console"
+	"golang.org/x/sys/windows"
+	"golang.org/x/term"
+)
+
+const (
+	keyEvent = 0x0001
+)
+
+var (
+	kernel32                  = windows.NewLazySystemDLL("kernel32.dll")
+	procPeekConsoleInputW     = kernel32.NewProc("PeekConsoleInputW")
+	procFlushConsoleInputBuff = kernel32.NewProc("FlushConsoleInputBuffer")
+)
+
+// inputRecord is INPUT_RECORD with KEY_EVENT_RECORD.
+type inputRecord struct {
+	eventType       uint16
+	_               uint16
+	keyDown         int32
+	repeatCount     uint16
+	virtualKeyCode  uint16
+	virtualScanCode uint16
+	char            uint16
+	controlKeyState uint32
+}
+
+func openTTY() (*os.File, error) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		return os.Stdin, nil
+	}
+	return os.OpenFile("CONIN$", os.O_RDWR, 0644)
+}
+
+func openConsole(f *os.File) (console.Console, error) {
+	if !term.IsTerminal(int(f.Fd())) {
+		return nil, console.ErrNotAConsole
+	}
+	// ConsoleFromFile is not supported on Windows, the console of stdin is used like the program does
+	os.Stdin = f
+	return console.Current(), nil
+}
+
+// waitInput reports whether f can be read without blocking within the timeout.
+// The console is signaled by the events which are not read as characters like key releases too,
+// so they are discarded to avoid blocking in the read.
+func waitInput(f *os.File, timeout time.Duration) (bool, error) {
+	h := windows.Handle(f.Fd())
+	event, err := windows.WaitForSingleObject(h, uint32(timeout/time.Millisecond))
+	if err != nil {
+		return false, err
+	}
+	if event != windows.WAIT_OBJECT_0 {
+		return false, nil
+	}
+	var records [16]inputRecord
+	var n uint32
+	r, _, _ := procPeekConsoleInputW.Call(uintptr(h), uintptr(unsafe.Pointer(&records[0])), uintptr(len(records)), uintptr(unsafe.Pointer(&n)))
+	if r == 0 {
+		// not a console, a read does not block
+		return true, nil
+	}
+	for _, rec := range records[:n] {
+		if rec.eventType == keyEvent && rec.keyDown != 0 && rec.char != 0 {
+			return true, nil
+		}
+	}
+	procFlushConsoleInputBuff.Call(uintptr(h))
+	return false, nil
+}
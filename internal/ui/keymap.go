@@ -0,0 +1,248 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/lusingander/stu/internal/config"
+)
+
+type listKeyMap struct {
+	Open          key.Binding
+	Filter        key.Binding
+	Back          key.Binding
+	CopyName      key.Binding
+	CopyURI       key.Binding
+	CopyARN       key.Binding
+	Search        key.Binding
+	DateFilter    key.Binding
+	ClearDates    key.Binding
+	Stats         key.Binding
+	Properties    key.Binding
+	Report        key.Binding
+	Hooks         key.Binding
+	ToggleHidden  key.Binding
+	ToggleMarkers key.Binding
+	Sort          key.Binding
+	Browser       key.Binding
+	Download      key.Binding
+	Preview       key.Binding
+	PurgePreviews key.Binding
+	Metrics       key.Binding
+	Touch         key.Binding
+	ACL           key.Binding
+	Delete        key.Binding
+	Upload        key.Binding
+	Rename        key.Binding
+	Copy          key.Binding
+	Tasks         key.Binding
+	Profiles      key.Binding
+	Credentials   key.Binding
+	Help          key.Binding
+}
+
+type detailKeyMap struct {
+	Back    key.Binding
+	Preview key.Binding
+	Verify  key.Binding
+	Tab     key.Binding
+	Help    key.Binding
+}
+
+type previewKeyMap struct {
+	Back   key.Binding
+	Mode   key.Binding
+	Follow key.Binding
+	Help   key.Binding
+}
+
+type keyMap struct {
+	List    listKeyMap
+	Detail  detailKeyMap
+	Preview previewKeyMap
+}
+
+func newBinding(help, desc string, keys ...string) key.Binding {
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(help, desc))
+}
+
+func newKeyMap() keyMap {
+	return keyMap{
+		List: listKeyMap{
+			Open:          newBinding("enter", "open", "enter"),
+			Filter:        newBinding("/", "filter by name", "/"),
+			Back:          newBinding("backspace", "go back", "backspace", "ctrl+h"),
+			CopyName:      newBinding("y", "copy the name", "y"),
+			CopyURI:       newBinding("Y", "copy the S3 URI", "Y"),
+			CopyARN:       newBinding("A", "copy the ARN", "A"),
+			Search:        newBinding("s", "search objects", "s"),
+			DateFilter:    newBinding("m", "filter by last modified", "m"),
+			ClearDates:    newBinding("esc", "clear the date filter", "esc"),
+			Stats:         newBinding("i", "show stats", "i"),
+			Properties:    newBinding("i", "show the bucket properties", "i"),
+			Report:        newBinding("r", "show reports", "r"),
+			Hooks:         newBinding("!", "run a command", "!"),
+			ToggleHidden:  newBinding(".", "show hidden buckets", "."),
+			ToggleMarkers: newBinding(".", "show folder markers", "."),
+			Sort:          newBinding("O", "change the sort order", "O"),
+			Browser:       newBinding("o", "open in the browser", "o"),
+			Download:      newBinding("S", "download", "S"),
+			Preview:       newBinding("p", "preview", "p"),
+			PurgePreviews: newBinding("P", "purge cached previews", "P"),
+			Metrics:       newBinding("u", "refresh bucket metrics", "u"),
+			Touch:         newBinding("t", "touch", "t"),
+			ACL:           newBinding("L", "set the ACL", "L"),
+			Delete:        newBinding("D", "delete", "D"),
+			Upload:        newBinding("U", "upload a local file or directory", "U"),
+			Rename:        newBinding("R", "rename the prefix", "R"),
+			Copy:          newBinding("C", "copy the prefix", "C"),
+			Tasks:         newBinding("T", "show running tasks", "T"),
+			Profiles:      newBinding("a", "switch the AWS profile", "a"),
+			Credentials:   newBinding("K", "issue credentials scoped to the prefix", "K"),
+			Help:          newBinding("?", "help", "?"),
+		},
+		Detail: detailKeyMap{
+			Back:    newBinding("esc/backspace", "go back", "esc", "backspace", "ctrl+h"),
+			Preview: newBinding("p", "preview", "p"),
+			Verify:  newBinding("v", "verify the ETag with a local file", "v"),
+			Tab:     newBinding("tab", "switch to the properties or the CloudTrail activity", "tab"),
+			Help:    newBinding("?", "help", "?"),
+		},
+		Preview: previewKeyMap{
+			Back:   newBinding("esc/backspace", "go back", "esc", "backspace", "ctrl+h"),
+			Mode:   newBinding("tab", "next mode", "tab"),
+			Follow: newBinding("F", "follow appended data", "F"),
+			Help:   newBinding("?", "help", "?"),
+		},
+	}
+}
+
+// actions returns the bindings by the action names used in keybind.toml.
+func (k *listKeyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"open":           &k.Open,
+		"filter":         &k.Filter,
+		"back":           &k.Back,
+		"copy_name":      &k.CopyName,
+		"copy_uri":       &k.CopyURI,
+		"copy_arn":       &k.CopyARN,
+		"search":         &k.Search,
+		"date_filter":    &k.DateFilter,
+		"clear_dates":    &k.ClearDates,
+		"stats":          &k.Stats,
+		"properties":     &k.Properties,
+		"report":         &k.Report,
+		"hooks":          &k.Hooks,
+		"toggle_hidden":  &k.ToggleHidden,
+		"toggle_markers": &k.ToggleMarkers,
+		"sort":           &k.Sort,
+		"browser":        &k.Browser,
+		"download":       &k.Download,
+		"preview":        &k.Preview,
+		"purge_previews": &k.PurgePreviews,
+		"metrics":        &k.Metrics,
+		"touch":          &k.Touch,
+		"acl":            &k.ACL,
+		"delete":         &k.Delete,
+		"upload":         &k.Upload,
+		"rename":         &k.Rename,
+		"copy":           &k.Copy,
+		"tasks":          &k.Tasks,
+		"profiles":       &k.Profiles,
+		"credentials":    &k.Credentials,
+		"help":           &k.Help,
+	}
+}
+
+func (k *detailKeyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"back":    &k.Back,
+		"preview": &k.Preview,
+		"verify":  &k.Verify,
+		"tab":     &k.Tab,
+		"help":    &k.Help,
+	}
+}
+
+func (k *previewKeyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"back":   &k.Back,
+		"mode":   &k.Mode,
+		"follow": &k.Follow,
+		"help":   &k.Help,
+	}
+}
+
+// apply replaces the keys of the actions set in the config.
+func (k *keyMap) apply(cfg config.KeybindConfig) error {
+	sections := []struct {
+		name    string
+		actions map[string]*key.Binding
+		keys    map[string][]string
+	}{
+		{"list", k.List.actions(), cfg.List},
+		{"detail", k.Detail.actions(), cfg.Detail},
+		{"preview", k.Preview.actions(), cfg.Preview},
+	}
+	for _, sec := range sections {
+		for action, ks := range sec.keys {
+			b, ok := sec.actions[action]
+			if !ok {
+				return fmt.Errorf("unknown keybind action: %s.%s", sec.name, action)
+			}
+			rebind(b, ks)
+		}
+	}
+	return nil
+}
+
+func rebind(b *key.Binding, ks []string) {
+	if len(ks) == 0 {
+		b.SetEnabled(false)
+		return
+	}
+	b.SetKeys(ks...)
+	b.SetHelp(strings.Join(ks, "/"), b.Help().Desc)
+	b.SetEnabled(true)
+}
+
+type helpSection struct {
+	title    string
+	bindings []key.Binding
+}
+
+// helpSections lists the bindings of each page, the list navigation is provided by the list component.
+func (k *keyMap) helpSections() []helpSection {
+	l, nav := k.List, list.DefaultKeyMap()
+	return []helpSection{
+		{
+			title: "Lists",
+			bindings: []key.Binding{
+				nav.CursorUp, nav.CursorDown, nav.PrevPage, nav.NextPage, nav.GoToStart, nav.GoToEnd,
+				nav.Filter, nav.ClearFilter, nav.Quit,
+			},
+		},
+		{
+			title:    "Bucket list",
+			bindings: []key.Binding{l.Open, l.Filter, l.CopyName, l.CopyURI, l.CopyARN, l.Properties, l.ToggleHidden, l.Metrics, l.Hooks, l.Tasks, l.Profiles, l.Credentials, l.PurgePreviews, l.Help},
+		},
+		{
+			title: "Object list",
+			bindings: []key.Binding{
+				l.Open, l.Filter, l.Back, l.CopyName, l.CopyURI, l.CopyARN, l.Search, l.DateFilter, l.ClearDates, l.Stats, l.Report,
+				l.Hooks, l.ToggleMarkers, l.Sort, l.Browser, l.Download, l.Preview, l.PurgePreviews, l.Touch, l.ACL, l.Delete, l.Upload, l.Rename,
+				l.Copy, l.Tasks, l.Profiles, l.Credentials, l.Help,
+			},
+		},
+		{
+			title:    "Detail",
+			bindings: []key.Binding{k.Detail.Back, k.Detail.Preview, k.Detail.Verify, k.Detail.Tab, k.Detail.Help},
+		},
+		{
+			title:    "Preview",
+			bindings: []key.Binding{k.Preview.Back, k.Preview.Mode, k.Preview.Follow, k.Preview.Help},
+		},
+	}
+}
@@ -0,0 +1,41 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/lusingander/stu/internal/stu"
+)
+
+// keyWarning holds the warnings of a new key typed by the user,
+// which is accepted by pressing enter again without changing it.
+type keyWarning struct {
+	key     string
+	message string
+}
+
+// check returns false if the key must not be used yet, with the reason set to the warning or err.
+func (w *keyWarning) check(key string) (bool, error) {
+	warnings, err := stu.CheckKey(key)
+	if err != nil {
+		w.message = ""
+		return false, err
+	}
+	if len(warnings) == 0 || (w.message != "" && w.key == key) {
+		w.message = ""
+		return true, nil
+	}
+	w.key = key
+	w.message = strings.Join(warnings, ", ") + " (press enter again to continue)"
+	return false, nil
+}
+
+func (w *keyWarning) reset() {
+	w.message = ""
+}
+
+func (w *keyWarning) view(c *uiConfig) string {
+	if w.message == "" {
+		return ""
+	}
+	return c.viewWarning(w.message)
+}
@@ -0,0 +1,184 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lusingander/stu/internal/stu"
+	"github.com/mattn/go-runewidth"
+)
+
+var (
+	metricsStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("244"))
+)
+
+// implemented by clients which persist the metrics (see internal/cache)
+type metricsReader interface {
+	BucketMetrics(bucket string) (*stu.BucketMetrics, bool)
+}
+
+type metricsWriter interface {
+	PutBucketMetrics(bucket string, metrics *stu.BucketMetrics) error
+}
+
+type metricsState struct {
+	id      int
+	ch      chan tea.Msg
+	cancel  context.CancelFunc
+	running bool
+	item    *stu.BucketItem
+	objects int
+}
+
+type metricsProgressMsg struct {
+	id      int
+	objects int
+}
+
+type metricsDoneMsg struct {
+	id    int
+	stats *stu.PrefixStats
+	err   error
+}
+
+func newMetricsState() *metricsState {
+	return &metricsState{
+		cancel: func() {},
+	}
+}
+
+func (s *metricsState) stop() {
+	s.cancel()
+	s.running = false
+}
+
+func waitMetricsMsg(id int, ch <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return metricsDoneMsg{id: id}
+		}
+		return msg
+	}
+}
+
+func (m model) fillBucketMetrics(buckets []*stu.BucketItem) {
+	r, ok := m.client.(metricsReader)
+	if !m.ui.bucketMetrics || !ok {
+		return
+	}
+	for _, bucket := range buckets {
+		if metrics, ok := r.BucketMetrics(bucket.BucketName()); ok {
+			bucket.Metrics = metrics
+		}
+	}
+}
+
+// saveBucketMetrics keeps the stats of a whole bucket for the bucket list.
+func (m model) saveBucketMetrics(stats *stu.PrefixStats) (*stu.BucketMetrics, error) {
+	metrics := &stu.BucketMetrics{
+		Objects:   stats.Objects,
+		Size:      stats.TotalSize,
+		UpdatedAt: time.Now(),
+	}
+	if w, ok := m.client.(metricsWriter); ok {
+		if err := w.PutBucketMetrics(stats.Bucket, metrics); err != nil {
+			return metrics, err
+		}
+	}
+	return metrics, nil
+}
+
+// refreshBucketMetrics counts the objects of the selected bucket in the background.
+func (m model) refreshBucketMetrics() (tea.Model, tea.Cmd) {
+	item, ok := m.list.SelectedItem().(*stu.BucketItem)
+	if !ok || !m.ui.bucketMetrics {
+		return m, nil
+	}
+	s := m.metrics
+	s.stop()
+
+	s.id++
+	s.ch = make(chan tea.Msg)
+	s.running = true
+	s.item = item
+	s.objects = 0
+
+	id, ch := s.id, s.ch
+	client, bucket := m.client, item.BucketName()
+	s.cancel = m.tasks.Go("metrics "+bucket, func(ctx context.Context) {
+		defer close(ch)
+		stats, err := stu.CollectPrefixStats(ctx, client, bucket, "", func(p stu.Progress) {
+			select {
+			case ch <- metricsProgressMsg{id: id, objects: p.Items}:
+			case <-ctx.Done():
+			}
+		})
+		select {
+		case ch <- metricsDoneMsg{id: id, stats: stats, err: err}:
+		case <-ctx.Done():
+		}
+	})
+
+	m.status = fmt.Sprintf("counting objects in %s...", bucket)
+	return m, waitMetricsMsg(id, ch)
+}
+
+func (m model) updateMetricsMsg(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.metrics
+	switch msg := msg.(type) {
+	case metricsProgressMsg:
+		if msg.id != s.id {
+			return m, nil
+		}
+		s.objects = msg.objects
+		m.status = fmt.Sprintf("counting objects in %s: %s%s", s.item.BucketName(), m.ui.format.count(s.objects), m.viewThrottled())
+		return m, waitMetricsMsg(s.id, s.ch)
+	case metricsDoneMsg:
+		if msg.id != s.id {
+			return m, nil
+		}
+		s.stop()
+		if msg.err != nil {
+			if !errors.Is(msg.err, context.Canceled) {
+				m.status = m.ui.viewError(msg.err)
+			}
+			return m, nil
+		}
+		if msg.stats != nil {
+			metrics, err := m.saveBucketMetrics(msg.stats)
+			s.item.Metrics = metrics
+			if err != nil {
+				m.status = m.ui.viewError(err)
+				return m, nil
+			}
+			m.status = fmt.Sprintf("updated %s", s.item.BucketName())
+		}
+	}
+	return m, nil
+}
+
+// viewBucketMetrics aligns the columns after the longest bucket name in the list.
+func (c *uiConfig) viewBucketMetrics(l list.Model, item *stu.BucketItem) string {
+	width := 0
+	for _, i := range l.Items() {
+		if b, ok := i.(*stu.BucketItem); ok {
+			if w := runewidth.StringWidth(b.BucketName()); w > width {
+				width = w
+			}
+		}
+	}
+	pad := strings.Repeat(" ", width-runewidth.StringWidth(item.BucketName()))
+	if item.Metrics == nil {
+		return pad + metricsStyle.Render(fmt.Sprintf("  %12s  %10s", "-", "-"))
+	}
+	mt := item.Metrics
+	return pad + metricsStyle.Render(fmt.Sprintf("  %12s  %10s  (%s)", c.format.count(mt.Objects), c.format.size(mt.Size), c.format.time(mt.UpdatedAt)))
+}
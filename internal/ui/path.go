@@ -0,0 +1,32 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// characters not allowed in file names on Windows
+var invalidFilenameReplacer = strings.NewReplacer(
+	"<", "_", ">", "_", ":", "_", "\"", "_", "|", "_", "?", "_", "*", "_", "\\", "_", "/", "_",
+)
+
+func sanitizeFilename(name string) string {
+	name = invalidFilenameReplacer.Replace(name)
+	// trailing dots and spaces are stripped silently on Windows
+	return strings.TrimRight(name, ". ")
+}
+
+// expandPath resolves "~" and separators so that paths typed by the user work on every OS,
+// including Windows drive-letter paths such as C:\Users\foo or C:/Users/foo.
+func expandPath(p string) (string, error) {
+	p = strings.TrimSpace(p)
+	if p == "~" || strings.HasPrefix(p, "~/") || strings.HasPrefix(p, `~\`) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		p = filepath.Join(home, p[1:])
+	}
+	return filepath.Clean(filepath.FromSlash(p)), nil
+}
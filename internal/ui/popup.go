@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	popupMaxWidth = 72
+)
+
+var (
+	popupStyle = lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("63")).
+			Padding(0, 1)
+	popupTitleStyle = lipgloss.NewStyle().
+			Bold(true)
+)
+
+// popup is a dialog shown over the list view, which receives all messages while it is open.
+type popup interface {
+	update(m model, msg tea.Msg) (tea.Model, tea.Cmd)
+	view(c *uiConfig, width int) string
+}
+
+// inputPopup asks for a line of text.
+// The popup stays open with the error if submit fails, e.g. to fix the value.
+type inputPopup struct {
+	title  string
+	input  textinput.Model
+	err    error
+	submit func(m model, value string) (model, tea.Cmd, error)
+}
+
+func newInputPopup(title, prompt, value string, submit func(model, string) (model, tea.Cmd, error)) (*inputPopup, tea.Cmd) {
+	input := textinput.NewModel()
+	input.Prompt = prompt
+	input.SetValue(value)
+	input.CursorEnd()
+	input.Focus()
+	return &inputPopup{
+		title:  title,
+		input:  input,
+		submit: submit,
+	}, textinput.Blink
+}
+
+func (p *inputPopup) update(m model, msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "esc":
+			m.popup = nil
+			return m, nil
+		case "enter":
+			m.popup = nil
+			next, cmd, err := p.submit(m, p.input.Value())
+			if err != nil {
+				p.err = err
+				m.popup = p
+				return m, nil
+			}
+			return next, cmd
+		}
+		p.err = nil
+	}
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	return m, cmd
+}
+
+func (p *inputPopup) view(c *uiConfig, width int) string {
+	p.input.Width = width - lipgloss.Width(p.input.Prompt) - 1
+	s := popupTitleStyle.Render(p.title) + "\n\n" + p.input.View()
+	if p.err != nil {
+		s += "\n" + c.viewError(p.err)
+	}
+	return s
+}
+
+// overlayPopup replaces the lines in the middle of the base view with the popup.
+func (c *uiConfig) overlayPopup(base string, p popup, width int) string {
+	w := width - 4
+	if w > popupMaxWidth {
+		w = popupMaxWidth
+	}
+	style := popupStyle
+	if c.accessible {
+		style = style.Copy().BorderStyle(asciiBorder).UnsetBorderForeground()
+	}
+	box := style.Width(w).Render(p.view(c, w-2))
+	lines := strings.Split(base, "\n")
+	boxLines := strings.Split(box, "\n")
+	top := (len(lines) - len(boxLines)) / 2
+	if top < 0 {
+		top = 0
+	}
+	for i, l := range boxLines {
+		l = lipgloss.PlaceHorizontal(width, lipgloss.Center, l)
+		if top+i < len(lines) {
+			lines[top+i] = l
+		} else {
+			lines = append(lines, l)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,289 @@
+package ui
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lusingander/stu/internal/config"
+	"github.com/lusingander/stu/internal/preview"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+const (
+	previewTailLines      = 1000
+	previewFollowInterval = 2 * time.Second
+)
+
+type previewState struct {
+	id        int
+	maxBytes  int64
+	item      *stu.ObjectItem
+	modes     []preview.Mode
+	mode      int
+	data      []byte
+	truncated bool
+	// end of the read bytes in the object, for following
+	offset  int64
+	follow  bool
+	loading bool
+	err     error
+	view    viewport.Model
+}
+
+type previewLoadedMsg struct {
+	id        int
+	data      []byte
+	truncated bool
+	offset    int64
+	err       error
+}
+
+type previewFollowMsg struct {
+	id int
+}
+
+type previewAppendedMsg struct {
+	id     int
+	data   []byte
+	offset int64
+	end    int64
+	err    error
+}
+
+func newPreviewState(cfg config.PreviewConfig) *previewState {
+	return &previewState{
+		maxBytes: cfg.MaxBytes,
+	}
+}
+
+func (s *previewState) setSize(width, height int) {
+	s.view.Width = width
+	s.view.Height = height
+}
+
+func (s *previewState) currentMode() preview.Mode {
+	return s.modes[s.mode]
+}
+
+func (m model) openPreview() (tea.Model, tea.Cmd) {
+	item, ok := m.selectedFile()
+	if !ok {
+		return m, nil
+	}
+	s := m.preview
+	s.id++
+	s.item = item
+	s.modes = preview.Modes(item.ObjectKey())
+	s.mode = 0
+	s.data = nil
+	s.follow = false
+	s.err = nil
+	s.view.SetContent("")
+	s.view.GotoTop()
+	m.page = pagePreview
+
+	mode := s.currentMode()
+	if !mode.Tail {
+		if data, truncated, ok := m.objCache.Preview(m.bucket, item); ok && (!mode.Full || !truncated) {
+			s.loading = false
+			s.data, s.truncated, s.offset = data, truncated, int64(len(data))
+			m.renderPreview()
+			return m, nil
+		}
+	}
+	s.loading = true
+	return m, loadPreview(m.tasks, s.id, m.client, m.bucket, item, mode, s.maxBytes)
+}
+
+func loadPreview(tasks *stu.TaskManager, id int, client stu.Client, bucket string, item *stu.ObjectItem, mode preview.Mode, max int64) tea.Cmd {
+	return taskCmd(tasks, "preview "+bucket+"/"+item.ObjectKey(), func(ctx context.Context) tea.Msg {
+		var data []byte
+		var truncated bool
+		var err error
+		switch {
+		case mode.Full:
+			// the decoders of whole files hold them in memory as well, so the same limit applies
+			data, truncated, err = stu.ReadObjectHead(ctx, client, bucket, item, max)
+			if err == nil && truncated {
+				err = fmt.Errorf("the %s preview needs the whole object, which is larger than the preview limit (preview.max_bytes)", mode.Name)
+			}
+		case mode.Tail:
+			data, truncated, err = stu.ReadObjectTail(ctx, client, bucket, item, max)
+		default:
+			data, truncated, err = stu.ReadObjectHead(ctx, client, bucket, item, max)
+		}
+		offset := int64(len(data))
+		if mode.Tail {
+			offset = item.Size
+		}
+		return previewLoadedMsg{id: id, data: data, truncated: truncated, offset: offset, err: err}
+	})
+}
+
+func (m model) updatePreviewMsg(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.preview
+	switch msg := msg.(type) {
+	case previewLoadedMsg:
+		if msg.id != s.id {
+			return m, nil
+		}
+		s.loading = false
+		s.err = msg.err
+		if msg.err != nil {
+			return m, nil
+		}
+		s.data, s.truncated, s.offset = msg.data, msg.truncated, msg.offset
+		if !s.currentMode().Tail {
+			m.objCache.PutPreview(m.bucket, s.item, msg.data, msg.truncated)
+		}
+		m.renderPreview()
+		if s.currentMode().Tail {
+			s.view.GotoBottom()
+		}
+	case previewFollowMsg:
+		if msg.id != s.id || !s.follow {
+			return m, nil
+		}
+		return m, readAppended(m.tasks, s.id, m.client, m.bucket, s.item.ObjectKey(), s.offset, s.maxBytes)
+	case previewAppendedMsg:
+		if msg.id != s.id || !s.follow {
+			return m, nil
+		}
+		s.err = msg.err
+		if msg.err == nil && msg.end != s.offset {
+			atBottom := s.view.AtBottom()
+			if msg.offset == s.offset {
+				s.data = append(s.data, msg.data...)
+			} else {
+				s.data = msg.data
+				s.truncated = msg.offset > 0
+			}
+			if over := int64(len(s.data)) - s.maxBytes; over > 0 {
+				s.data = s.data[over:]
+				s.truncated = true
+			}
+			s.offset = msg.end
+			m.renderPreview()
+			if atBottom {
+				s.view.GotoBottom()
+			}
+		}
+		return m, followPreview(s.id)
+	}
+	return m, nil
+}
+
+func readAppended(tasks *stu.TaskManager, id int, client stu.Client, bucket, key string, offset, max int64) tea.Cmd {
+	return taskCmd(tasks, "follow "+bucket+"/"+key, func(ctx context.Context) tea.Msg {
+		data, newOffset, size, err := stu.ReadAppended(ctx, client, bucket, key, offset, max)
+		return previewAppendedMsg{id: id, data: data, offset: newOffset, end: size, err: err}
+	})
+}
+
+func followPreview(id int) tea.Cmd {
+	return tea.Tick(previewFollowInterval, func(time.Time) tea.Msg {
+		return previewFollowMsg{id: id}
+	})
+}
+
+// renderPreview renders the data with the current mode, the result is cached unless the object is followed.
+func (m model) renderPreview() {
+	s := m.preview
+	mode := s.currentMode()
+	data := s.data
+	if mode.Tail {
+		data = preview.TailLines(data, s.truncated, previewTailLines)
+	}
+	var content string
+	var err error
+	switch {
+	case mode.Name == "raw" && isBinary(data):
+		content = fmt.Sprintf("binary object (%s), preview is not available", m.ui.format.size(s.item.Size))
+	case s.follow:
+		content, err = mode.Render(data)
+	default:
+		content, err = m.rendered.Render(m.bucket, s.item.ObjectKey(), s.item.ETag, mode, data)
+	}
+	s.err = err
+	s.view.SetContent(content)
+}
+
+func isBinary(data []byte) bool {
+	if bytes.IndexByte(data, 0) >= 0 {
+		return true
+	}
+	// the end may be cut in the middle of a character
+	if len(data) > utf8.UTFMax {
+		data = data[:len(data)-utf8.UTFMax]
+	}
+	return !utf8.Valid(data)
+}
+
+func (m model) updatePreview(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.preview
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		k := m.ui.keys.Preview
+		switch {
+		case key.Matches(msg, k.Back):
+			s.id++
+			m.page = pageList
+			return m, nil
+		case key.Matches(msg, k.Help):
+			return m.openHelp()
+		case key.Matches(msg, k.Mode):
+			if s.loading || len(s.modes) < 2 {
+				return m, nil
+			}
+			prev := s.currentMode()
+			s.mode = (s.mode + 1) % len(s.modes)
+			if mode := s.currentMode(); mode.Tail != prev.Tail || (mode.Full && s.truncated) {
+				s.id++
+				s.loading = true
+				s.follow = false
+				return m, loadPreview(m.tasks, s.id, m.client, m.bucket, s.item, mode, s.maxBytes)
+			}
+			m.renderPreview()
+			return m, nil
+		case key.Matches(msg, k.Follow):
+			if s.loading || !s.currentMode().Tail {
+				return m, nil
+			}
+			s.follow = !s.follow
+			if s.follow {
+				s.view.GotoBottom()
+				return m, followPreview(s.id)
+			}
+			m.renderPreview()
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	s.view, cmd = s.view.Update(msg)
+	return m, cmd
+}
+
+func (m model) viewPreview() string {
+	s := m.preview
+	status := s.currentMode().Name
+	switch {
+	case s.loading:
+		status += ", loading..." + m.viewThrottled()
+	case s.follow:
+		status += ", following"
+	case s.truncated && s.currentMode().Tail:
+		status += fmt.Sprintf(", last %s of %s", m.ui.format.size(int64(len(s.data))), m.ui.format.size(s.item.Size))
+	case s.truncated:
+		status += fmt.Sprintf(", first %s of %s", m.ui.format.size(int64(len(s.data))), m.ui.format.size(s.item.Size))
+	}
+	bc := breadcrumbStyle.Render(fmt.Sprintf("%s : %s (%s)", m.viewBreadcrumb(), s.item.Filename(), status))
+	if s.err != nil {
+		return bc + m.ui.styles.list.Render(itemStyle.Render(m.ui.viewError(s.err)))
+	}
+	return bc + m.ui.styles.list.Render(s.view.View())
+}
@@ -0,0 +1,143 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+// ProfileSwitcher creates clients for the profiles of the shared config.
+type ProfileSwitcher interface {
+	// Current returns the profile of the initial client, or empty if it does not use a profile.
+	Current() string
+	Profiles() ([]string, error)
+	Client(profile string) (stu.Client, error)
+}
+
+type profilesState struct {
+	switcher ProfileSwitcher
+	current  string
+	profiles list.Model
+	prev     page
+}
+
+type profileItem struct {
+	name    string
+	current bool
+}
+
+func (i *profileItem) Text() string {
+	if i.current {
+		return i.name + " (current)"
+	}
+	return i.name
+}
+
+func (i *profileItem) FilterValue() string {
+	return i.name
+}
+
+func newProfilesState(ui *uiConfig, switcher ProfileSwitcher) *profilesState {
+	s := &profilesState{
+		switcher: switcher,
+		profiles: newList(ui, nil),
+	}
+	if switcher != nil {
+		s.current = switcher.Current()
+	}
+	return s
+}
+
+func (s *profilesState) setSize(width, height int) {
+	s.profiles.SetSize(width, height)
+}
+
+func (m model) openProfiles() (tea.Model, tea.Cmd) {
+	s := m.profiles
+	if s.switcher == nil {
+		m.status = "profiles cannot be switched in offline mode"
+		return m, nil
+	}
+	profiles, err := s.switcher.Profiles()
+	if err != nil {
+		m.status = m.ui.viewError(err)
+		return m, nil
+	}
+	items := make([]list.Item, len(profiles))
+	for i, p := range profiles {
+		items[i] = &profileItem{name: p, current: p == s.current}
+	}
+	s.prev = m.page
+	s.profiles.ResetSelected()
+	s.profiles.ResetFilter()
+	m.status = ""
+	m.page = pageProfiles
+	return m, s.profiles.SetItems(items)
+}
+
+// switchProfile replaces the client and shows the buckets of the profile.
+// The current client is kept if the buckets cannot be listed.
+func (m model) switchProfile(profile string) (tea.Model, tea.Cmd) {
+	client, err := m.profiles.switcher.Client(profile)
+	if err != nil {
+		m.status = m.ui.viewError(err)
+		return m, nil
+	}
+	next := m
+	next.client = client
+	items, err := next.listBuckets()
+	if err != nil {
+		m.status = m.ui.viewError(err)
+		return m, nil
+	}
+	m = next
+	if m.enricher != nil {
+		m.enricher.SetClient(client)
+	}
+	m.objCache = stu.NewObjectCache(objectCacheSize)
+	m.rendered.Purge()
+	m.profiles.current = profile
+	m.bucket = ""
+	m.breadcrumbs = make([]*stu.ObjectItem, 0)
+	m.resetList(items)
+	m.status = fmt.Sprintf("switched to profile %s", profile)
+	m.page = pageList
+	return m, nil
+}
+
+func (m model) updateProfiles(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.profiles
+	if msg, ok := msg.(tea.KeyMsg); ok && !s.profiles.SettingFilter() {
+		switch msg.String() {
+		case "esc", "backspace", "ctrl+h":
+			if msg.String() == "esc" && s.profiles.FilterState() != list.Unfiltered {
+				break
+			}
+			m.page = s.prev
+			return m, nil
+		case "enter":
+			if i, ok := s.profiles.SelectedItem().(*profileItem); ok {
+				return m.switchProfile(i.name)
+			}
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	s.profiles, cmd = s.profiles.Update(msg)
+	return m, cmd
+}
+
+func (m model) viewProfiles() string {
+	s := m.profiles
+	status := "Select a profile"
+	if m.status != "" {
+		status += " : " + m.status
+	}
+	bc := breadcrumbStyle.Render(fmt.Sprintf("Profiles : %s", status))
+	if len(s.profiles.Items()) == 0 {
+		return bc + m.ui.styles.list.Render(emptyStyle.Height(s.profiles.Height()).Render("No profiles in the shared config"))
+	}
+	return bc + m.ui.styles.list.Render(s.profiles.View())
+}
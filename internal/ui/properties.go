@@ -0,0 +1,203 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lusingander/stu/internal/preview"
+	"github.com/lusingander/stu/internal/stu"
+	"github.com/mattn/go-runewidth"
+)
+
+type propertiesState struct {
+	view    viewport.Model
+	id      int
+	bucket  string
+	loading bool
+
+	policy         string
+	policyErr      error
+	policyWarnings []string
+
+	cors    []*stu.CORSRule
+	corsErr error
+}
+
+type propertiesMsg struct {
+	id        int
+	policy    string
+	policyErr error
+	cors      []*stu.CORSRule
+	corsErr   error
+}
+
+func newPropertiesState() *propertiesState {
+	return &propertiesState{}
+}
+
+func (s *propertiesState) setSize(width, height int) {
+	s.view.Width = width
+	s.view.Height = height
+}
+
+func (m model) openProperties() (tea.Model, tea.Cmd) {
+	item, ok := m.list.SelectedItem().(*stu.BucketItem)
+	if !ok {
+		return m, nil
+	}
+	if m.offline() {
+		m.status = stu.ErrOffline.Error()
+		return m, nil
+	}
+	s := m.properties
+	s.id++
+	s.bucket = item.BucketName()
+	s.loading = true
+	s.policy, s.policyErr, s.policyWarnings = "", nil, nil
+	s.cors, s.corsErr = nil, nil
+	s.view.SetContent(m.viewPropertiesBody())
+	s.view.GotoTop()
+	m.page = pageProperties
+	return m, fetchProperties(m.tasks, s.id, m.client, s.bucket)
+}
+
+func fetchProperties(tasks *stu.TaskManager, id int, client stu.Client, bucket string) tea.Cmd {
+	return taskCmd(tasks, "properties "+bucket, func(ctx context.Context) tea.Msg {
+		msg := propertiesMsg{id: id, policyErr: stu.ErrPropertiesNotSupported, corsErr: stu.ErrPropertiesNotSupported}
+		if r, ok := client.(stu.BucketPolicyReader); ok {
+			msg.policy, msg.policyErr = r.GetBucketPolicy(ctx, bucket)
+		}
+		if r, ok := client.(stu.BucketCORSReader); ok {
+			msg.cors, msg.corsErr = r.GetBucketCORS(ctx, bucket)
+		}
+		return msg
+	})
+}
+
+func (m model) updatePropertiesMsg(msg propertiesMsg) (tea.Model, tea.Cmd) {
+	s := m.properties
+	if msg.id != s.id {
+		return m, nil
+	}
+	s.loading = false
+	s.policyErr = msg.policyErr
+	if msg.policyErr == nil && msg.policy != "" {
+		s.policy = msg.policy
+		if formatted, err := stu.FormatBucketPolicy(msg.policy); err == nil {
+			s.policy = formatted
+		}
+		s.policyWarnings, s.policyErr = stu.LintBucketPolicy(msg.policy)
+	}
+	s.cors, s.corsErr = msg.cors, msg.corsErr
+	s.view.SetContent(m.viewPropertiesBody())
+	return m, nil
+}
+
+func (m model) updateProperties(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.properties
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "esc", "backspace", "ctrl+h":
+			s.id++
+			m.page = pageList
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	s.view, cmd = s.view.Update(msg)
+	return m, cmd
+}
+
+func (m model) viewPropertiesBody() string {
+	s := m.properties
+	if s.loading {
+		return "  loading..."
+	}
+	var b strings.Builder
+	b.WriteString("  " + helpTitleStyle.Render("Bucket policy") + "\n\n")
+	switch {
+	case s.policyErr != nil:
+		b.WriteString("  " + m.ui.viewError(s.policyErr) + "\n")
+	case s.policy == "":
+		b.WriteString("  No bucket policy\n")
+	default:
+		for _, w := range s.policyWarnings {
+			b.WriteString("  " + m.ui.viewWarning(w) + "\n")
+		}
+		if len(s.policyWarnings) > 0 {
+			b.WriteString("\n")
+		}
+		for _, line := range strings.Split(preview.HighlightJSON(s.policy), "\n") {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+
+	b.WriteString("\n  " + helpTitleStyle.Render("CORS rules") + "\n\n")
+	switch {
+	case s.corsErr != nil:
+		b.WriteString("  " + m.ui.viewError(s.corsErr) + "\n")
+	case len(s.cors) == 0:
+		b.WriteString("  No CORS configuration\n")
+	default:
+		b.WriteString(viewCORSRules(s.cors))
+	}
+	return b.String()
+}
+
+var corsColumns = []string{"ID", "Origins", "Methods", "Allowed headers", "Expose headers", "Max age"}
+
+// viewCORSRules shows one row per rule, lists are joined with commas.
+func viewCORSRules(rules []*stu.CORSRule) string {
+	rows := make([][]string, 0, len(rules)+1)
+	rows = append(rows, corsColumns)
+	for _, r := range rules {
+		maxAge := "-"
+		if r.MaxAgeSeconds > 0 {
+			maxAge = fmt.Sprintf("%ds", r.MaxAgeSeconds)
+		}
+		rows = append(rows, []string{
+			orDash(r.ID),
+			orDash(strings.Join(r.AllowedOrigins, ", ")),
+			orDash(strings.Join(r.AllowedMethods, ", ")),
+			orDash(strings.Join(r.AllowedHeaders, ", ")),
+			orDash(strings.Join(r.ExposeHeaders, ", ")),
+			maxAge,
+		})
+	}
+	widths := make([]int, len(corsColumns))
+	for _, row := range rows {
+		for i, cell := range row {
+			if w := runewidth.StringWidth(cell); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+	var b strings.Builder
+	for i, row := range rows {
+		cells := make([]string, len(row))
+		for j, cell := range row {
+			cells[j] = runewidth.FillRight(cell, widths[j])
+		}
+		line := strings.TrimRight(strings.Join(cells, "  "), " ")
+		if i == 0 {
+			line = helpTitleStyle.Render(line)
+		}
+		b.WriteString("  " + line + "\n")
+	}
+	return b.String()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func (m model) viewProperties() string {
+	bc := breadcrumbStyle.Render(fmt.Sprintf("%s : Properties of %s (read-only)", m.viewBreadcrumb(), m.properties.bucket))
+	return bc + m.ui.styles.list.Render(m.properties.view.View())
+}
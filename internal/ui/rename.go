@@ -0,0 +1,293 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+const (
+	// the preview shows only the first objects, all objects are counted
+	renamePreviewLimit = 1000
+)
+
+type renameState struct {
+	input   textinput.Model
+	warning keyWarning
+	preview list.Model
+
+	from string
+	to   string
+
+	id      int
+	ch      chan tea.Msg
+	cancel  context.CancelFunc
+	running bool
+	// false during the dry run
+	executing bool
+	count     int
+	err       error
+}
+
+type renamePreviewItem struct {
+	src string
+	dst string
+}
+
+func (i *renamePreviewItem) Text() string {
+	return fmt.Sprintf("%s -> %s", i.src, i.dst)
+}
+
+func (i *renamePreviewItem) FilterValue() string {
+	return i.src
+}
+
+type renamePreviewMsg struct {
+	id   int
+	item *renamePreviewItem
+}
+
+type renameProgressMsg struct {
+	id    int
+	count int
+}
+
+type renameDoneMsg struct {
+	id  int
+	err error
+}
+
+func newRenameState(ui *uiConfig) *renameState {
+	input := textinput.NewModel()
+	input.Prompt = "Rename to: "
+	input.Placeholder = "new prefix"
+	return &renameState{
+		input:   input,
+		preview: newList(ui, nil),
+		cancel:  func() {},
+	}
+}
+
+func (s *renameState) setSize(width, height int) {
+	s.preview.SetSize(width, height)
+}
+
+func (s *renameState) stop() {
+	s.cancel()
+	s.running = false
+}
+
+func waitRenameMsg(id int, ch <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return renameDoneMsg{id: id}
+		}
+		return msg
+	}
+}
+
+func (m model) openRenameInput() (tea.Model, tea.Cmd) {
+	i, ok := m.list.SelectedItem().(*stu.ObjectItem)
+	if !ok || !i.Dir {
+		return m, nil
+	}
+	s := m.rename
+	s.from = i.ObjectKey()
+	s.err = nil
+	s.warning.reset()
+	s.input.SetValue(s.from)
+	s.input.CursorEnd()
+	s.input.Focus()
+	m.page = pageRenameInput
+	return m, textinput.Blink
+}
+
+func (m model) startRename(execute bool) (tea.Model, tea.Cmd) {
+	s := m.rename
+	s.stop()
+
+	s.id++
+	s.ch = make(chan tea.Msg)
+	s.running = true
+	s.executing = execute
+	s.count = 0
+	s.err = nil
+	if !execute {
+		s.preview.SetItems(nil)
+		s.preview.ResetSelected()
+		s.preview.ResetFilter()
+	}
+
+	id, ch := s.id, s.ch
+	client, bucket, from, to := m.client, m.bucket, s.from, s.to
+	name := fmt.Sprintf("rename %s/%s to %s", bucket, from, to)
+	if !execute {
+		name += " (dry run)"
+	}
+	s.cancel = m.tasks.Go(name, func(ctx context.Context) {
+		defer close(ch)
+		send := func(msg tea.Msg) {
+			select {
+			case ch <- msg:
+			case <-ctx.Done():
+			}
+		}
+		var err error
+		if execute {
+			_, err = stu.RenamePrefix(ctx, client, bucket, from, to, func(p stu.Progress) {
+				send(renameProgressMsg{id: id, count: p.Items})
+			})
+		} else {
+			err = stu.PlanRename(ctx, client, bucket, from, to, func(item *stu.ObjectItem, dst string) error {
+				send(renamePreviewMsg{id: id, item: &renamePreviewItem{src: item.ObjectKey(), dst: dst}})
+				return nil
+			})
+		}
+		send(renameDoneMsg{id: id, err: err})
+	})
+
+	m.page = pageRenamePreview
+	return m, waitRenameMsg(id, ch)
+}
+
+func (m model) updateRenameMsg(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.rename
+	switch msg := msg.(type) {
+	case renamePreviewMsg:
+		if msg.id != s.id {
+			return m, nil
+		}
+		s.count++
+		var cmd tea.Cmd
+		if s.count <= renamePreviewLimit {
+			cmd = s.preview.InsertItem(len(s.preview.Items()), msg.item)
+		}
+		return m, tea.Batch(cmd, waitRenameMsg(s.id, s.ch))
+	case renameProgressMsg:
+		if msg.id != s.id {
+			return m, nil
+		}
+		s.count = msg.count
+		return m, waitRenameMsg(s.id, s.ch)
+	case renameDoneMsg:
+		if msg.id != s.id {
+			return m, nil
+		}
+		// already stopped by the user
+		stopped := !s.running
+		s.stop()
+		if msg.err != nil && !errors.Is(msg.err, context.Canceled) {
+			s.err = msg.err
+		}
+		if s.executing && s.err == nil && !stopped {
+			m.page = pageList
+			m.status = fmt.Sprintf("moved %s objects to %s", m.ui.format.count(s.count), s.to)
+			return m.reloadList()
+		}
+		return m, nil
+	}
+	return m, nil
+}
+
+func (m model) updateRenameInput(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.rename
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "esc":
+			s.input.Blur()
+			m.page = pageList
+			return m, nil
+		case "enter":
+			to := strings.TrimSpace(s.input.Value())
+			if to != "" && !strings.HasSuffix(to, "/") {
+				to += "/"
+			}
+			if err := stu.ValidateRename(s.from, to); err != nil {
+				s.err = err
+				return m, nil
+			}
+			if ok, err := s.warning.check(to); !ok {
+				s.err = err
+				return m, nil
+			}
+			s.to = to
+			s.input.Blur()
+			return m.startRename(false)
+		}
+	}
+	s.err = nil
+	s.warning.reset()
+	var cmd tea.Cmd
+	s.input, cmd = s.input.Update(msg)
+	return m, cmd
+}
+
+func (m model) updateRenamePreview(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.rename
+	if msg, ok := msg.(tea.KeyMsg); ok && !s.preview.SettingFilter() {
+		switch msg.String() {
+		case "esc", "backspace", "ctrl+h":
+			if msg.String() == "esc" && s.preview.FilterState() != list.Unfiltered {
+				break
+			}
+			if s.running {
+				s.stop()
+				return m, nil
+			}
+			m.page = pageList
+			if s.executing {
+				// some objects may have been moved already
+				return m.reloadList()
+			}
+			return m, nil
+		case "y":
+			// running again resumes the interrupted rename because moved objects are no longer listed
+			if !s.running && (s.executing || (s.err == nil && s.count > 0)) {
+				return m.startRename(true)
+			}
+		}
+	}
+	var cmd tea.Cmd
+	s.preview, cmd = s.preview.Update(msg)
+	return m, cmd
+}
+
+func (m model) viewRenameStatus() string {
+	s := m.rename
+	switch {
+	case s.err != nil:
+		return m.ui.viewError(s.err)
+	case !s.executing && s.running:
+		return fmt.Sprintf("listing... (%s objects)%s", m.ui.format.count(s.count), m.viewThrottled())
+	case !s.executing && s.count == 0:
+		return "no objects to move"
+	case !s.executing:
+		return fmt.Sprintf("dry run: %s objects will be moved, press y to execute", m.ui.format.count(s.count))
+	case s.running:
+		return fmt.Sprintf("moving... (%s objects)%s", m.ui.format.count(s.count), m.viewThrottled())
+	}
+	return fmt.Sprintf("stopped after moving %s objects, press y to resume", m.ui.format.count(s.count))
+}
+
+func (m model) viewRename() string {
+	s := m.rename
+	if m.page == pageRenameInput {
+		v := s.input.View()
+		if s.err != nil {
+			v += "  " + m.ui.viewError(s.err)
+		} else if w := s.warning.view(m.ui); w != "" {
+			v += "  " + w
+		}
+		bc := breadcrumbStyle.Render(fmt.Sprintf("%s : %s", m.viewBreadcrumb(), v))
+		return bc + m.ui.styles.list.Render(m.list.View())
+	}
+	bc := breadcrumbStyle.Render(fmt.Sprintf("%s : Rename %s : %s", m.viewBreadcrumb(), s.from, m.viewRenameStatus()))
+	return bc + m.ui.styles.list.Render(s.preview.View())
+}
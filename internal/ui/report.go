@@ -0,0 +1,277 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+const (
+	reportLimit = 100
+)
+
+type reportState struct {
+	menu    list.Model
+	results list.Model
+
+	id      int
+	kind    stu.ReportKind
+	ch      chan tea.Msg
+	cancel  context.CancelFunc
+	running bool
+	scanned int
+	err     error
+	// results of ReportDuplicates
+	groups []*stu.DuplicateGroup
+}
+
+type reportMenuItem struct {
+	kind stu.ReportKind
+}
+
+func (i *reportMenuItem) Text() string {
+	if i.kind == stu.ReportDuplicates {
+		return i.kind.String()
+	}
+	return fmt.Sprintf("Top %d: %s", reportLimit, i.kind)
+}
+
+func (i *reportMenuItem) FilterValue() string {
+	return i.kind.String()
+}
+
+type reportResultItem struct {
+	*stu.ObjectItem
+	kind   stu.ReportKind
+	format formatOptions
+}
+
+func (i *reportResultItem) Text() string {
+	switch i.kind {
+	case stu.ReportNewest:
+		return fmt.Sprintf("%s  %s", i.format.time(i.LastModified), i.ObjectKey())
+	case stu.ReportDuplicates:
+		return "    " + i.ObjectKey()
+	}
+	return fmt.Sprintf("%10s  %s", i.format.size(i.Size), i.ObjectKey())
+}
+
+func (i *reportResultItem) FilterValue() string {
+	return i.ObjectKey()
+}
+
+type duplicateGroupItem struct {
+	*stu.DuplicateGroup
+	format formatOptions
+}
+
+func (i *duplicateGroupItem) Text() string {
+	return fmt.Sprintf("%10s wasted  %d x %s  %s", i.format.size(i.Wasted()), len(i.Items), i.format.size(i.Size), i.ETag)
+}
+
+func (i *duplicateGroupItem) FilterValue() string {
+	return i.ETag
+}
+
+type reportProgressMsg struct {
+	id      int
+	scanned int
+}
+
+type reportDoneMsg struct {
+	id     int
+	items  []*stu.ObjectItem
+	groups []*stu.DuplicateGroup
+	err    error
+}
+
+func newReportState(ui *uiConfig) *reportState {
+	menu := newList(ui, []list.Item{
+		&reportMenuItem{kind: stu.ReportLargest},
+		&reportMenuItem{kind: stu.ReportNewest},
+		&reportMenuItem{kind: stu.ReportDuplicates},
+	})
+	menu.SetFilteringEnabled(false)
+	return &reportState{
+		menu:    menu,
+		results: newList(ui, nil),
+		cancel:  func() {},
+	}
+}
+
+func (s *reportState) setSize(width, height int) {
+	s.menu.SetSize(width, height)
+	s.results.SetSize(width, height)
+}
+
+func (s *reportState) stop() {
+	s.cancel()
+	s.running = false
+}
+
+func waitReportMsg(id int, ch <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return reportDoneMsg{id: id}
+		}
+		return msg
+	}
+}
+
+func (m model) openReportMenu() (tea.Model, tea.Cmd) {
+	m.report.menu.ResetSelected()
+	m.page = pageReportMenu
+	return m, nil
+}
+
+func (m model) startReport(kind stu.ReportKind) (tea.Model, tea.Cmd) {
+	s := m.report
+	s.stop()
+
+	s.id++
+	s.kind = kind
+	s.ch = make(chan tea.Msg)
+	s.running = true
+	s.scanned = 0
+	s.err = nil
+	s.groups = nil
+	s.results.SetItems(nil)
+	s.results.ResetSelected()
+	s.results.ResetFilter()
+	m.export.msg = ""
+
+	id, ch := s.id, s.ch
+	client, bucket, prefix := m.client, m.bucket, m.currentPrefix()
+	s.cancel = m.tasks.Go(fmt.Sprintf("report %s/%s", bucket, prefix), func(ctx context.Context) {
+		defer close(ch)
+		progress := func(p stu.Progress) {
+			select {
+			case ch <- reportProgressMsg{id: id, scanned: p.Items}:
+			case <-ctx.Done():
+			}
+		}
+		done := reportDoneMsg{id: id}
+		if kind == stu.ReportDuplicates {
+			done.groups, done.err = stu.FindDuplicates(ctx, client, bucket, prefix, progress)
+		} else {
+			done.items, done.err = stu.TopObjects(ctx, client, bucket, prefix, kind, reportLimit, progress)
+		}
+		select {
+		case ch <- done:
+		case <-ctx.Done():
+		}
+	})
+
+	m.page = pageReport
+	return m, waitReportMsg(id, ch)
+}
+
+func (m model) updateReportMsg(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.report
+	switch msg := msg.(type) {
+	case reportProgressMsg:
+		if msg.id != s.id {
+			return m, nil
+		}
+		s.scanned = msg.scanned
+		return m, waitReportMsg(s.id, s.ch)
+	case reportDoneMsg:
+		if msg.id != s.id {
+			return m, nil
+		}
+		s.stop()
+		if msg.err != nil && !errors.Is(msg.err, context.Canceled) {
+			s.err = msg.err
+		}
+		s.groups = msg.groups
+		items := make([]list.Item, 0, len(msg.items))
+		for _, item := range msg.items {
+			items = append(items, &reportResultItem{ObjectItem: item, kind: s.kind, format: m.ui.format})
+		}
+		for _, g := range msg.groups {
+			items = append(items, &duplicateGroupItem{DuplicateGroup: g, format: m.ui.format})
+			for _, item := range g.Items {
+				items = append(items, &reportResultItem{ObjectItem: item, kind: s.kind, format: m.ui.format})
+			}
+		}
+		return m, s.results.SetItems(items)
+	}
+	return m, nil
+}
+
+func (m model) updateReportMenu(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.report
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "esc", "backspace", "ctrl+h":
+			m.page = pageList
+			return m, nil
+		case "enter":
+			if i, ok := s.menu.SelectedItem().(*reportMenuItem); ok {
+				return m.startReport(i.kind)
+			}
+		}
+	}
+	var cmd tea.Cmd
+	s.menu, cmd = s.menu.Update(msg)
+	return m, cmd
+}
+
+func (m model) updateReport(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.report
+	if msg, ok := msg.(tea.KeyMsg); ok && !s.results.SettingFilter() {
+		switch msg.String() {
+		case "esc", "backspace", "ctrl+h":
+			if msg.String() == "esc" && s.results.FilterState() != list.Unfiltered {
+				break
+			}
+			if s.running {
+				s.stop()
+				return m, nil
+			}
+			m.page = pageReportMenu
+			return m, nil
+		case "x":
+			if !s.running {
+				return m.openExport()
+			}
+		}
+	}
+	var cmd tea.Cmd
+	s.results, cmd = s.results.Update(msg)
+	return m, cmd
+}
+
+func (m model) viewReport() string {
+	s := m.report
+	if m.page == pageReportMenu {
+		bc := breadcrumbStyle.Render(m.viewBreadcrumb() + " : Reports")
+		return bc + m.ui.styles.list.Render(s.menu.View())
+	}
+	var status string
+	switch {
+	case s.err != nil:
+		status = m.ui.viewError(s.err)
+	case s.running:
+		status = fmt.Sprintf("scanning... (%s objects)%s", m.ui.format.count(s.scanned), m.viewThrottled())
+	case s.kind == stu.ReportDuplicates:
+		var wasted int64
+		for _, g := range s.groups {
+			wasted += g.Wasted()
+		}
+		status = fmt.Sprintf("done (%s groups, %s wasted)", m.ui.format.count(len(s.groups)), m.ui.format.size(wasted))
+	default:
+		status = fmt.Sprintf("done (%s results)", m.ui.format.count(len(s.results.Items())))
+	}
+	bc := breadcrumbStyle.Render(fmt.Sprintf("%s : %s %s%s", m.viewBreadcrumb(), s.kind, status, m.viewExportStatus()))
+	return bc + m.viewReportBody()
+}
+
+func (m model) viewReportBody() string {
+	return m.ui.styles.list.Render(m.report.results.View())
+}
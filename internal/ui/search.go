@@ -0,0 +1,278 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+const (
+	searchConcurrency      = 8
+	searchProgressInterval = 100 * time.Millisecond
+)
+
+type searchState struct {
+	input   textinput.Model
+	results list.Model
+
+	id      int
+	query   *stu.SearchQuery
+	ch      chan tea.Msg
+	cancel  context.CancelFunc
+	running bool
+	scanned int
+	found   int
+	// objects whose tags or metadata could not be fetched
+	failures int
+	failed   []*stu.SearchFailure
+	err      error
+}
+
+type searchResultItem struct {
+	*stu.ObjectItem
+}
+
+func (i *searchResultItem) Text() string {
+	return i.ObjectKey()
+}
+
+func (i *searchResultItem) FilterValue() string {
+	return i.ObjectKey()
+}
+
+type searchResultMsg struct {
+	id   int
+	item *stu.ObjectItem
+}
+
+type searchProgressMsg struct {
+	id       int
+	progress stu.Progress
+}
+
+type searchDoneMsg struct {
+	id     int
+	result *stu.SearchResult
+	err    error
+}
+
+func newSearchState(ui *uiConfig) *searchState {
+	input := textinput.NewModel()
+	input.Prompt = "Search: "
+	input.Placeholder = "tag:key=value or meta:key=value"
+	return &searchState{
+		input:   input,
+		results: newList(ui, nil),
+		cancel:  func() {},
+	}
+}
+
+func (s *searchState) setSize(width, height int) {
+	s.results.SetSize(width, height)
+}
+
+func (s *searchState) stop() {
+	s.cancel()
+	s.running = false
+}
+
+func waitSearchMsg(id int, ch <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return searchDoneMsg{id: id}
+		}
+		return msg
+	}
+}
+
+func (m model) openSearchInput() (tea.Model, tea.Cmd) {
+	m.page = pageSearchInput
+	m.search.err = nil
+	m.search.input.Reset()
+	m.search.input.Focus()
+	return m, textinput.Blink
+}
+
+func (m model) startSearch(q *stu.SearchQuery) (tea.Model, tea.Cmd) {
+	s := m.search
+	s.stop()
+
+	s.id++
+	s.query = q
+	s.ch = make(chan tea.Msg)
+	s.running = true
+	s.scanned = 0
+	s.found = 0
+	s.failures = 0
+	s.failed = nil
+	s.err = nil
+	s.results.SetItems(nil)
+	s.results.ResetSelected()
+	s.results.ResetFilter()
+
+	id, ch := s.id, s.ch
+	client, bucket, prefix := m.client, m.bucket, m.currentPrefix()
+	s.cancel = m.tasks.Go(fmt.Sprintf("search %s/%s", bucket, prefix), func(ctx context.Context) {
+		defer close(ch)
+		var mu sync.Mutex
+		var sent time.Time
+		result, err := stu.Search(ctx, client, bucket, prefix, q, searchConcurrency, func(item *stu.ObjectItem) {
+			select {
+			case ch <- searchResultMsg{id: id, item: item}:
+			case <-ctx.Done():
+			}
+		}, func(p stu.Progress) {
+			mu.Lock()
+			now := time.Now()
+			throttled := now.Sub(sent) < searchProgressInterval
+			if !throttled {
+				sent = now
+			}
+			mu.Unlock()
+			if throttled {
+				return
+			}
+			// dropped while the UI is busy, the final count comes with the done message
+			select {
+			case ch <- searchProgressMsg{id: id, progress: p}:
+			default:
+			}
+		})
+		select {
+		case ch <- searchDoneMsg{id: id, result: result, err: err}:
+		case <-ctx.Done():
+		}
+	})
+
+	m.page = pageSearchResult
+	return m, waitSearchMsg(id, ch)
+}
+
+func (m model) updateSearchMsg(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.search
+	switch msg := msg.(type) {
+	case searchResultMsg:
+		if msg.id != s.id {
+			return m, nil
+		}
+		s.found++
+		cmd := s.results.InsertItem(len(s.results.Items()), &searchResultItem{msg.item})
+		return m, tea.Batch(cmd, waitSearchMsg(s.id, s.ch))
+	case searchProgressMsg:
+		if msg.id != s.id {
+			return m, nil
+		}
+		// progress may arrive out of order from the workers
+		if msg.progress.Items > s.scanned {
+			s.scanned = msg.progress.Items
+			s.failures = msg.progress.Failed
+		}
+		return m, waitSearchMsg(s.id, s.ch)
+	case searchDoneMsg:
+		if msg.id != s.id {
+			return m, nil
+		}
+		s.stop()
+		if msg.result != nil {
+			s.scanned = msg.result.Scanned + len(msg.result.Failed)
+			s.failures = len(msg.result.Failed)
+			s.failed = msg.result.Failed
+		}
+		if msg.err != nil && !errors.Is(msg.err, context.Canceled) {
+			s.err = msg.err
+		}
+	}
+	return m, nil
+}
+
+func (m model) updateSearchInput(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.search
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "esc":
+			s.input.Blur()
+			m.page = pageList
+			return m, nil
+		case "enter":
+			q, err := stu.ParseSearchQuery(s.input.Value())
+			if err != nil {
+				s.err = err
+				return m, nil
+			}
+			s.input.Blur()
+			return m.startSearch(q)
+		}
+	}
+	var cmd tea.Cmd
+	s.input, cmd = s.input.Update(msg)
+	return m, cmd
+}
+
+func (m model) updateSearchResult(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.search
+	if msg, ok := msg.(tea.KeyMsg); ok && !s.results.SettingFilter() {
+		switch msg.String() {
+		case "esc":
+			if s.results.FilterState() != list.Unfiltered {
+				break
+			}
+			if s.running {
+				s.stop()
+				return m, nil
+			}
+			m.page = pageList
+			return m, nil
+		case "s":
+			return m.openSearchInput()
+		}
+	}
+	var cmd tea.Cmd
+	s.results, cmd = s.results.Update(msg)
+	return m, cmd
+}
+
+func (m model) viewSearchStatus() string {
+	s := m.search
+	if m.page == pageSearchInput {
+		v := s.input.View()
+		if s.err != nil {
+			v += "  " + m.ui.viewError(s.err)
+		}
+		return v
+	}
+	if s.err != nil {
+		return m.ui.viewError(s.err)
+	}
+	status := "done"
+	if s.running {
+		status = "searching..." + m.viewThrottled()
+	}
+	v := fmt.Sprintf("Search: %s (%s scanned: %s, found: %s)", s.query, status, m.ui.format.count(s.scanned), m.ui.format.count(s.found))
+	if s.failures > 0 {
+		w := fmt.Sprintf("%s objects could not be checked", m.ui.format.count(s.failures))
+		if len(s.failed) > 0 {
+			w += fmt.Sprintf(" (%s: %v)", s.failed[0].Key, s.failed[0].Err)
+		}
+		v += "  " + m.ui.viewWarning(w)
+	}
+	return v
+}
+
+func (m model) viewSearch() string {
+	bc := breadcrumbStyle.Render(m.viewBreadcrumb() + " : " + m.viewSearchStatus())
+	var l string
+	if m.page == pageSearchResult {
+		l = m.ui.styles.list.Render(m.search.results.View())
+	} else {
+		l = m.ui.styles.list.Render(m.list.View())
+	}
+	return bc + l
+}
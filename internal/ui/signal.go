@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// terminator quits the running program on SIGINT and SIGTERM,
+// so that the terminal is restored and temporary files are removed before exit.
+type terminator struct {
+	ch chan os.Signal
+
+	mu      sync.Mutex
+	program *tea.Program
+	// an external command is using the terminal
+	handoff bool
+}
+
+func newTerminator() *terminator {
+	t := &terminator{ch: make(chan os.Signal, 1)}
+	signal.Notify(t.ch, os.Interrupt, syscall.SIGTERM)
+	go t.wait()
+	return t
+}
+
+func (t *terminator) wait() {
+	for sig := range t.ch {
+		t.mu.Lock()
+		if sig == os.Interrupt && t.handoff {
+			// ctrl+c while running an external command interrupts only the command
+			t.mu.Unlock()
+			continue
+		}
+		if t.program != nil {
+			// Send blocks if the program exits before receiving the message
+			go t.program.Quit()
+		}
+		t.mu.Unlock()
+	}
+}
+
+func (t *terminator) setProgram(p *tea.Program) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.program = p
+}
+
+// setHandoff is called around running an external command.
+// The program also quits on SIGINT, which is meant for the terminal not in raw mode,
+// so the signal is taken from it to be handled here only.
+func (t *terminator) setHandoff(handoff bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if handoff {
+		signal.Ignore(os.Interrupt)
+		signal.Notify(t.ch, os.Interrupt, syscall.SIGTERM)
+	}
+	t.handoff = handoff
+}
+
+func (t *terminator) stop() {
+	signal.Stop(t.ch)
+	close(t.ch)
+}
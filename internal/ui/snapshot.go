@@ -0,0 +1,32 @@
+package ui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lusingander/stu/internal/config"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+// RenderSnapshot renders the view at a fixed size after applying the keys, without starting the program.
+// Combined with a deterministic client (see internal/mock), the output can be compared with golden files
+// to catch layout regressions. Times are rendered in UTC regardless of the local time zone.
+// Commands returned by Update are not executed, so pages loaded asynchronously are rendered in their initial state.
+func RenderSnapshot(client stu.Client, cfg *config.Config, width, height int, keys ...string) (string, error) {
+	m, err := newModel(client, cfg, nil)
+	if err != nil {
+		return "", err
+	}
+	defer m.tasks.Shutdown(taskShutdownTimeout)
+	m.ui.format.location = time.UTC
+	var ret tea.Model = m
+	ret, _ = ret.Update(tea.WindowSizeMsg{Width: width, Height: height})
+	for _, k := range keys {
+		key, err := parseControlKey(k)
+		if err != nil {
+			return "", err
+		}
+		ret, _ = ret.Update(key)
+	}
+	return ret.View(), nil
+}
@@ -0,0 +1,91 @@
+package ui_test
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lusingander/stu/internal/config"
+	"github.com/lusingander/stu/internal/mock"
+	"github.com/lusingander/stu/internal/ui"
+	"github.com/muesli/termenv"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestMain(m *testing.M) {
+	// colors depend on the terminal running the tests
+	lipgloss.SetColorProfile(termenv.Ascii)
+	os.Exit(m.Run())
+}
+
+func TestRenderSnapshot(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []string
+	}{
+		{"bucket_list", nil},
+		{"object_list", []string{"down", "enter"}},
+		{"object_list_dir", []string{"down", "enter", "down", "enter"}},
+		{"object_list_back", []string{"down", "enter", "down", "enter", "backspace"}},
+		{"delete_confirm", []string{"down", "enter", "D"}},
+		{"help", []string{"?"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			got, err := ui.RenderSnapshot(mock.NewFixtureClient(), cfg, 100, 30, tt.keys...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertGolden(t, tt.name, got)
+		})
+	}
+}
+
+func TestRenderSnapshotAccessible(t *testing.T) {
+	cfg := config.Default()
+	cfg.UI.Accessible = true
+	got, err := ui.RenderSnapshot(mock.NewFixtureClient(), cfg, 100, 30, "down", "enter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "object_list_accessible", got)
+
+	// the styles of accessible mode must not leak into other models
+	got, err = ui.RenderSnapshot(mock.NewFixtureClient(), config.Default(), 100, 30, "down", "enter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "object_list", got)
+}
+
+// assertGolden compares the view with testdata/snapshots/<name>.golden,
+// which is written by `go test ./internal/ui -update`.
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", "snapshots", name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		t.Logf("wrote %s", path)
+		return
+	}
+	want, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		t.Fatalf("%s does not exist, run with -update to create it", path)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("%s does not match, run with -update if the change is intended\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
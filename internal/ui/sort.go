@@ -0,0 +1,60 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+// listSort is the order of the object list, the listing order is kept unless enabled.
+type listSort struct {
+	enabled bool
+	key     stu.SortKey
+	desc    bool
+}
+
+var sortKeyNames = map[stu.SortKey]string{
+	stu.SortByName:         "name",
+	stu.SortBySize:         "size",
+	stu.SortByLastModified: "last modified",
+}
+
+// next cycles name, size and last modified in ascending and descending order, then the listing order.
+func (s listSort) next() listSort {
+	switch {
+	case !s.enabled:
+		return listSort{enabled: true, key: stu.SortByName}
+	case !s.desc:
+		s.desc = true
+		return s
+	case s.key == stu.SortByLastModified:
+		return listSort{}
+	}
+	return listSort{enabled: true, key: s.key + 1}
+}
+
+func (s listSort) apply(items []*stu.ObjectItem) []*stu.ObjectItem {
+	if !s.enabled {
+		return items
+	}
+	return stu.SortObjects(items, s.key, s.desc)
+}
+
+func (s listSort) String() string {
+	if !s.enabled {
+		return ""
+	}
+	order := "asc"
+	if s.desc {
+		order = "desc"
+	}
+	return "sort: " + sortKeyNames[s.key] + " " + order
+}
+
+func (m model) toggleSort() (tea.Model, tea.Cmd) {
+	m.sort = m.sort.next()
+	// the order is shown in the breadcrumb while sorted
+	if !m.sort.enabled {
+		m.status = "sort: listing order"
+	}
+	return m.reloadList()
+}
@@ -0,0 +1,188 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+const (
+	histogramBarWidth = 40
+)
+
+var (
+	statsStyle = lipgloss.NewStyle().
+		PaddingLeft(2)
+)
+
+type statsState struct {
+	id      int
+	ch      chan tea.Msg
+	cancel  context.CancelFunc
+	running bool
+	stats   *stu.PrefixStats
+	err     error
+}
+
+type statsProgressMsg struct {
+	id       int
+	progress stu.Progress
+}
+
+type statsDoneMsg struct {
+	id    int
+	stats *stu.PrefixStats
+	err   error
+}
+
+func newStatsState() *statsState {
+	return &statsState{
+		cancel: func() {},
+	}
+}
+
+func (s *statsState) stop() {
+	s.cancel()
+	s.running = false
+}
+
+func waitStatsMsg(id int, ch <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return statsDoneMsg{id: id}
+		}
+		return msg
+	}
+}
+
+func (m model) openStats() (tea.Model, tea.Cmd) {
+	s := m.stats
+	s.stop()
+
+	s.id++
+	s.ch = make(chan tea.Msg)
+	s.running = true
+	s.stats = stu.NewPrefixStats(m.bucket, m.currentPrefix())
+	s.err = nil
+	m.export.msg = ""
+
+	id, ch := s.id, s.ch
+	client, bucket, prefix := m.client, m.bucket, m.currentPrefix()
+	s.cancel = m.tasks.Go(fmt.Sprintf("stats %s/%s", bucket, prefix), func(ctx context.Context) {
+		defer close(ch)
+		stats, err := stu.CollectPrefixStats(ctx, client, bucket, prefix, func(p stu.Progress) {
+			select {
+			case ch <- statsProgressMsg{id: id, progress: p}:
+			case <-ctx.Done():
+			}
+		})
+		select {
+		case ch <- statsDoneMsg{id: id, stats: stats, err: err}:
+		case <-ctx.Done():
+		}
+	})
+
+	m.page = pageStats
+	return m, waitStatsMsg(id, ch)
+}
+
+func (m model) updateStatsMsg(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.stats
+	switch msg := msg.(type) {
+	case statsProgressMsg:
+		if msg.id != s.id {
+			return m, nil
+		}
+		// the size distribution is shown when done
+		s.stats.Objects = msg.progress.Items
+		s.stats.TotalSize = msg.progress.Bytes
+		return m, waitStatsMsg(s.id, s.ch)
+	case statsDoneMsg:
+		if msg.id != s.id {
+			return m, nil
+		}
+		s.stop()
+		if msg.stats != nil {
+			s.stats = msg.stats
+		}
+		if msg.err != nil && !errors.Is(msg.err, context.Canceled) {
+			s.err = msg.err
+		}
+		if msg.err == nil && msg.stats != nil && msg.stats.Prefix == "" && m.ui.bucketMetrics {
+			if _, err := m.saveBucketMetrics(msg.stats); err != nil {
+				s.err = err
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m model) updateStats(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "esc", "backspace", "ctrl+h":
+			m.stats.stop()
+			m.page = pageList
+			return m, nil
+		case "x":
+			if !m.stats.running {
+				return m.openExport()
+			}
+		case "q", "ctrl+c":
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+func (m model) viewStats() string {
+	s := m.stats
+	status := "done"
+	if s.running {
+		status = "collecting..." + m.viewThrottled()
+	}
+	bc := breadcrumbStyle.Render(fmt.Sprintf("%s : Stats (%s)%s", m.viewBreadcrumb(), status, m.viewExportStatus()))
+	return bc + m.viewStatsBody()
+}
+
+func (m model) viewStatsBody() string {
+	s := m.stats
+	var b strings.Builder
+	if s.err != nil {
+		b.WriteString(m.ui.viewError(s.err))
+		b.WriteString("\n\n")
+	}
+	fmt.Fprintf(&b, "Objects:    %s\n", m.ui.format.count(s.stats.Objects))
+	fmt.Fprintf(&b, "Total size: %s\n", m.ui.format.size(s.stats.TotalSize))
+	if !s.running {
+		b.WriteString("\nSize distribution:\n")
+		b.WriteString(m.ui.viewHistogram(s.stats.Histogram))
+	}
+
+	return m.ui.styles.list.Render(statsStyle.Render(b.String()))
+}
+
+func (c *uiConfig) viewHistogram(buckets []*stu.SizeBucket) string {
+	maxCount := 0
+	for _, bucket := range buckets {
+		if bucket.Count > maxCount {
+			maxCount = bucket.Count
+		}
+	}
+	var b strings.Builder
+	for _, bucket := range buckets {
+		w := 0
+		if maxCount > 0 {
+			w = bucket.Count * histogramBarWidth / maxCount
+		}
+		bar := c.styles.histogramBar.Render(strings.Repeat(c.styles.histogramBarChar, w))
+		fmt.Fprintf(&b, "  %-12s %10s %12s %s\n", bucket.Label, c.format.count(bucket.Count), c.format.size(bucket.Bytes), bar)
+	}
+	return b.String()
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+// +build !windows
+
+package ui
+
+import (
+	"syscall"
+)
+
+const (
+	suspendSupported = true
+)
+
+// suspendProcess stops the process group like ctrl+z in a cooked terminal
+// and returns after it is resumed by SIGCONT.
+func suspendProcess() error {
+	return syscall.Kill(0, syscall.SIGTSTP)
+}
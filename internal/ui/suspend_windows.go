@@ -0,0 +1,12 @@
+//go:build windows
+// +build windows
+
+package ui
+
+const (
+	suspendSupported = false
+)
+
+func suspendProcess() error {
+	return nil
+}
@@ -0,0 +1,128 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+const (
+	taskShutdownTimeout = 3 * time.Second
+	taskRefreshInterval = time.Second
+)
+
+type tasksState struct {
+	tasks list.Model
+	prev  page
+	id    int
+}
+
+type taskItem struct {
+	stu.Task
+}
+
+func (i *taskItem) Text() string {
+	elapsed := time.Since(i.Started).Truncate(time.Second)
+	return fmt.Sprintf("#%d %s (%s)", i.ID, i.Name, elapsed)
+}
+
+func (i *taskItem) FilterValue() string {
+	return i.Name
+}
+
+type tasksRefreshMsg struct {
+	id int
+}
+
+func newTasksState(ui *uiConfig) *tasksState {
+	return &tasksState{
+		tasks: newList(ui, nil),
+	}
+}
+
+func (s *tasksState) setSize(width, height int) {
+	s.tasks.SetSize(width, height)
+}
+
+// taskCmd runs fn as a task of the manager in the goroutine of the command.
+func taskCmd(tasks *stu.TaskManager, name string, fn func(ctx context.Context) tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		ctx, _, done := tasks.Start(name)
+		defer done()
+		return fn(ctx)
+	}
+}
+
+func refreshTasks(id int) tea.Cmd {
+	return tea.Tick(taskRefreshInterval, func(time.Time) tea.Msg {
+		return tasksRefreshMsg{id: id}
+	})
+}
+
+func (m model) openTasks() (tea.Model, tea.Cmd) {
+	s := m.tasksPage
+	s.id++
+	s.prev = m.page
+	m.status = ""
+	s.tasks.ResetSelected()
+	s.tasks.ResetFilter()
+	m.page = pageTasks
+	return m, tea.Batch(m.reloadTasks(), refreshTasks(s.id))
+}
+
+func (m model) reloadTasks() tea.Cmd {
+	tasks := m.tasks.Tasks()
+	items := make([]list.Item, len(tasks))
+	for i, t := range tasks {
+		items[i] = &taskItem{Task: t}
+	}
+	return m.tasksPage.tasks.SetItems(items)
+}
+
+func (m model) updateTasksMsg(msg tasksRefreshMsg) (tea.Model, tea.Cmd) {
+	if msg.id != m.tasksPage.id || m.page != pageTasks {
+		return m, nil
+	}
+	return m, tea.Batch(m.reloadTasks(), refreshTasks(msg.id))
+}
+
+func (m model) updateTasks(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.tasksPage
+	if msg, ok := msg.(tea.KeyMsg); ok && !s.tasks.SettingFilter() {
+		switch msg.String() {
+		case "esc", "backspace", "ctrl+h":
+			if msg.String() == "esc" && s.tasks.FilterState() != list.Unfiltered {
+				break
+			}
+			s.id++
+			m.page = s.prev
+			return m, nil
+		case "x":
+			if i, ok := s.tasks.SelectedItem().(*taskItem); ok {
+				m.tasks.Cancel(i.ID)
+				m.status = fmt.Sprintf("canceled task #%d", i.ID)
+			}
+			return m, m.reloadTasks()
+		}
+	}
+	var cmd tea.Cmd
+	s.tasks, cmd = s.tasks.Update(msg)
+	return m, cmd
+}
+
+func (m model) viewTasks() string {
+	s := m.tasksPage
+	status := fmt.Sprintf("%d running tasks, press x to cancel the selected one", len(s.tasks.Items()))
+	if m.status != "" {
+		status += " : " + m.status
+	}
+	bc := breadcrumbStyle.Render(fmt.Sprintf("Tasks : %s", status))
+	if len(s.tasks.Items()) == 0 {
+		return bc + m.ui.styles.list.Render(emptyStyle.Height(s.tasks.Height()).Render("No running tasks"))
+	}
+	return bc + m.ui.styles.list.Render(s.tasks.View())
+}
@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	toastDuration = 2 * time.Second
+)
+
+var (
+	toastStyle = lipgloss.NewStyle().
+		Padding(0, 1).
+		Foreground(lipgloss.Color("230")).
+		Background(lipgloss.Color("63"))
+)
+
+// toastState is a notification shown at the bottom of every page for a while.
+type toastState struct {
+	id   int
+	text string
+}
+
+type toastExpiredMsg struct {
+	id int
+}
+
+func (m model) showToast(text string) (tea.Model, tea.Cmd) {
+	s := m.toast
+	s.id++
+	s.text = text
+	id := s.id
+	return m, tea.Tick(toastDuration, func(time.Time) tea.Msg {
+		return toastExpiredMsg{id: id}
+	})
+}
+
+func (m model) updateToastMsg(msg toastExpiredMsg) (tea.Model, tea.Cmd) {
+	if msg.id == m.toast.id {
+		m.toast.text = ""
+	}
+	return m, nil
+}
+
+// overlayToast replaces the last line of the view with the toast aligned to the right.
+func (c *uiConfig) overlayToast(base string, text string, width, height int) string {
+	if text == "" || height <= 0 {
+		return base
+	}
+	style := toastStyle
+	if c.accessible {
+		style = style.Copy().UnsetForeground().UnsetBackground().Reverse(true)
+	}
+	toast := lipgloss.PlaceHorizontal(width, lipgloss.Right, style.MaxWidth(width).Render(text))
+	lines := strings.Split(base, "\n")
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	lines[height-1] = toast
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+var touchSideEffects = []string{
+	"The object is copied onto itself to update its last modified time.",
+	"",
+	"  - lifecycle rules count the age of the object from now",
+	"  - event notifications (s3:ObjectCreated:Copy) and replication are triggered",
+	"  - a new version is created if versioning is enabled",
+	"  - ACL is reset to the default of the bucket",
+	"  - objects larger than 5 GB cannot be copied in a single request",
+	"",
+	"Press y to continue, n or esc to cancel.",
+}
+
+type touchState struct {
+	target *stu.ObjectItem
+}
+
+type touchDoneMsg struct {
+	item *stu.ObjectItem
+	err  error
+}
+
+func newTouchState() *touchState {
+	return &touchState{}
+}
+
+func (m model) openTouchConfirm() (tea.Model, tea.Cmd) {
+	item, ok := m.selectedFile()
+	if !ok {
+		return m, nil
+	}
+	m.touch.target = item
+	m.page = pageTouchConfirm
+	return m, nil
+}
+
+func touchObject(tasks *stu.TaskManager, client stu.Client, bucket string, item *stu.ObjectItem) tea.Cmd {
+	return taskCmd(tasks, "touch "+bucket+"/"+item.ObjectKey(), func(ctx context.Context) tea.Msg {
+		err := client.TouchObject(ctx, bucket, item.ObjectKey())
+		return touchDoneMsg{item: item, err: err}
+	})
+}
+
+func (m model) updateTouchMsg(msg touchDoneMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.status = m.ui.viewError(msg.err)
+		return m, nil
+	}
+	m.status = fmt.Sprintf("touched: %s", msg.item.Filename())
+	return m.reloadList()
+}
+
+func (m model) updateTouchConfirm(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "y", "enter":
+			m.page = pageList
+			m.status = fmt.Sprintf("touching %s...", m.touch.target.Filename())
+			return m, touchObject(m.tasks, m.client, m.bucket, m.touch.target)
+		case "n", "esc", "backspace", "ctrl+h":
+			m.page = pageList
+			return m, nil
+		}
+	}
+	return m, nil
+}
+
+func (m model) viewTouchConfirm() string {
+	bc := breadcrumbStyle.Render(fmt.Sprintf("%s : Touch %s", m.viewBreadcrumb(), m.touch.target.Filename()))
+	return bc + m.ui.styles.list.Render(itemStyle.Render(strings.Join(touchSideEffects, "\n")))
+}
@@ -0,0 +1,33 @@
+package ui
+
+import (
+	"github.com/lusingander/stu/internal/config"
+)
+
+// uiConfig is the part of the config which changes how the pages are rendered and operated.
+// The model holds it instead of the package, so that models created with different configs,
+// such as the ones of the snapshot tests, do not affect each other.
+type uiConfig struct {
+	format        formatOptions
+	keys          keyMap
+	accessible    bool
+	bucketMetrics bool
+	styles        uiStyles
+}
+
+func newUIConfig(cfg *config.Config) (*uiConfig, error) {
+	c := &uiConfig{
+		format:        newFormatOptions(cfg.Format),
+		keys:          newKeyMap(),
+		accessible:    cfg.UI.Accessible,
+		bucketMetrics: cfg.UI.BucketMetrics,
+		styles:        newStyles(),
+	}
+	if c.accessible {
+		c.styles = accessibleStyles(c.styles)
+	}
+	if err := c.keys.apply(cfg.Keybind); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,389 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lusingander/stu/internal/config"
+	"github.com/lusingander/stu/internal/stu"
+)
+
+const (
+	uploadFileProgressInterval = 100 * time.Millisecond
+)
+
+type uploadState struct {
+	input       textinput.Model
+	failures    list.Model
+	concurrency int
+
+	path   string
+	prefix string
+	// nil until the files are listed
+	files []*stu.UploadFile
+	total int64
+	// bytes read of the files being uploaded
+	inFlight map[*stu.UploadFile]int64
+	// keys with the warnings of stu.CheckKey, uploaded after confirming
+	warnings   []string
+	confirming bool
+
+	id int
+	ch chan tea.Msg
+	// buffered so that the result is kept even if the page is left
+	done     chan tea.Msg
+	confirm  chan struct{}
+	cancel   context.CancelFunc
+	running  bool
+	canceled bool
+	progress stu.Progress
+	err      error
+}
+
+type uploadFailureItem struct {
+	*stu.UploadFailure
+}
+
+func (i *uploadFailureItem) Text() string {
+	return fmt.Sprintf("%s: %s", i.Rel, i.Err)
+}
+
+func (i *uploadFailureItem) FilterValue() string {
+	return i.Rel
+}
+
+type uploadPlanMsg struct {
+	id       int
+	files    []*stu.UploadFile
+	warnings []string
+}
+
+type uploadFileMsg struct {
+	id   int
+	file *stu.UploadFile
+	read int64
+}
+
+type uploadProgressMsg struct {
+	id       int
+	progress stu.Progress
+}
+
+type uploadDoneMsg struct {
+	id     int
+	result *stu.UploadResult
+	err    error
+}
+
+func newUploadState(ui *uiConfig, cfg config.UploadConfig) *uploadState {
+	input := textinput.NewModel()
+	input.Prompt = "Upload: "
+	input.Placeholder = "local file or directory"
+	return &uploadState{
+		input:       input,
+		failures:    newList(ui, nil),
+		concurrency: cfg.Concurrency,
+		cancel:      func() {},
+	}
+}
+
+func (s *uploadState) setSize(width, height int) {
+	s.failures.SetSize(width, height)
+}
+
+func (s *uploadState) stop() {
+	s.cancel()
+	s.running = false
+}
+
+func waitUploadMsg(ch, done <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case msg, ok := <-ch:
+			if !ok {
+				return <-done
+			}
+			return msg
+		case msg := <-done:
+			return msg
+		}
+	}
+}
+
+func (m model) openUploadInput() (tea.Model, tea.Cmd) {
+	if m.bucket == "" {
+		return m, nil
+	}
+	s := m.upload
+	s.err = nil
+	s.input.SetValue("")
+	s.input.Focus()
+	m.page = pageUploadInput
+	return m, textinput.Blink
+}
+
+func (m model) startUpload() (tea.Model, tea.Cmd) {
+	s := m.upload
+	s.stop()
+
+	s.id++
+	s.ch = make(chan tea.Msg)
+	s.done = make(chan tea.Msg, 1)
+	s.confirm = make(chan struct{})
+	s.running = true
+	s.canceled = false
+	s.prefix = m.currentPrefix()
+	s.files = nil
+	s.total = 0
+	s.inFlight = make(map[*stu.UploadFile]int64)
+	s.warnings = nil
+	s.confirming = false
+	s.progress = stu.Progress{}
+	s.err = nil
+	s.failures.SetItems(nil)
+	s.failures.ResetSelected()
+	s.failures.ResetFilter()
+
+	id, ch, done, confirm := s.id, s.ch, s.done, s.confirm
+	client, audit, bucket, prefix, path, concurrency := m.client, m.audit, m.bucket, s.prefix, s.path, s.concurrency
+	s.cancel = m.tasks.Go(fmt.Sprintf("upload %s to %s/%s", path, bucket, prefix), func(ctx context.Context) {
+		defer close(ch)
+		send := func(msg tea.Msg) {
+			select {
+			case ch <- msg:
+			case <-ctx.Done():
+			}
+		}
+		uploader, ok := client.(stu.Uploader)
+		if !ok {
+			done <- uploadDoneMsg{id: id, err: stu.ErrUploadNotSupported}
+			return
+		}
+		files, err := stu.PlanUpload(path)
+		if err != nil {
+			done <- uploadDoneMsg{id: id, err: err}
+			return
+		}
+		warnings := stu.UploadKeyWarnings(files, prefix)
+		send(uploadPlanMsg{id: id, files: files, warnings: warnings})
+		if len(warnings) > 0 {
+			select {
+			case <-confirm:
+			case <-ctx.Done():
+				done <- uploadDoneMsg{id: id, err: ctx.Err()}
+				return
+			}
+		}
+
+		var mu sync.Mutex
+		sent := make(map[string]time.Time)
+		result, err := stu.UploadFiles(ctx, uploader, files, bucket, prefix, concurrency, func(p stu.Progress) {
+			mu.Lock()
+			delete(sent, p.Key)
+			mu.Unlock()
+			send(uploadProgressMsg{id: id, progress: p})
+		}, func(file *stu.UploadFile, read int64) {
+			mu.Lock()
+			now := time.Now()
+			throttled := now.Sub(sent[file.Rel]) < uploadFileProgressInterval
+			if !throttled {
+				sent[file.Rel] = now
+			}
+			mu.Unlock()
+			if throttled {
+				return
+			}
+			// dropped while the UI is busy so that the upload is not slowed down by rendering
+			select {
+			case ch <- uploadFileMsg{id: id, file: file, read: read}:
+			default:
+			}
+		})
+		detail := fmt.Sprintf("%s: %d uploaded, %d failed", path, result.Uploaded, len(result.Failed))
+		if aerr := audit.Record("upload", bucket, prefix, detail, err); aerr != nil && err == nil {
+			err = fmt.Errorf("files were uploaded but failed to write the audit log: %w", aerr)
+		}
+		// the result is kept even if canceled to show what has been uploaded
+		done <- uploadDoneMsg{id: id, result: result, err: err}
+	})
+
+	m.page = pageUpload
+	return m, waitUploadMsg(ch, done)
+}
+
+func (m model) updateUploadMsg(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.upload
+	switch msg := msg.(type) {
+	case uploadPlanMsg:
+		if msg.id != s.id {
+			return m, nil
+		}
+		s.files = msg.files
+		for _, f := range msg.files {
+			s.total += f.Size
+		}
+		s.warnings = msg.warnings
+		s.confirming = len(msg.warnings) > 0
+		return m, waitUploadMsg(s.ch, s.done)
+	case uploadFileMsg:
+		if msg.id != s.id {
+			return m, nil
+		}
+		s.inFlight[msg.file] = msg.read
+		return m, waitUploadMsg(s.ch, s.done)
+	case uploadProgressMsg:
+		if msg.id != s.id {
+			return m, nil
+		}
+		// progress may arrive out of order from the workers
+		if msg.progress.Items > s.progress.Items {
+			s.progress = msg.progress
+		}
+		for f := range s.inFlight {
+			if f.Rel == msg.progress.Key {
+				delete(s.inFlight, f)
+			}
+		}
+		return m, waitUploadMsg(s.ch, s.done)
+	case uploadDoneMsg:
+		if msg.id != s.id {
+			return m, nil
+		}
+		s.running = false
+		s.confirming = false
+		s.inFlight = make(map[*stu.UploadFile]int64)
+		s.canceled = errors.Is(msg.err, context.Canceled)
+		if msg.err != nil && !s.canceled {
+			s.err = msg.err
+		}
+		if msg.result == nil {
+			return m, nil
+		}
+		s.progress = msg.result.Progress()
+		items := make([]list.Item, len(msg.result.Failed))
+		for i, f := range msg.result.Failed {
+			items[i] = &uploadFailureItem{UploadFailure: f}
+		}
+		return m, s.failures.SetItems(items)
+	}
+	return m, nil
+}
+
+func (m model) updateUploadInput(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.upload
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "esc":
+			s.input.Blur()
+			m.page = pageList
+			return m, nil
+		case "enter":
+			path, err := expandPath(s.input.Value())
+			if err != nil {
+				s.err = err
+				return m, nil
+			}
+			s.path = path
+			s.input.Blur()
+			return m.startUpload()
+		}
+	}
+	s.err = nil
+	var cmd tea.Cmd
+	s.input, cmd = s.input.Update(msg)
+	return m, cmd
+}
+
+func (m model) updateUpload(msg tea.Msg) (tea.Model, tea.Cmd) {
+	s := m.upload
+	if msg, ok := msg.(tea.KeyMsg); ok && !s.failures.SettingFilter() {
+		switch msg.String() {
+		case "enter":
+			if s.confirming {
+				s.confirming = false
+				close(s.confirm)
+				return m, nil
+			}
+		case "esc", "backspace", "ctrl+h":
+			if msg.String() == "esc" && s.failures.FilterState() != list.Unfiltered {
+				break
+			}
+			if s.running {
+				// multipart uploads in flight are aborted
+				s.cancel()
+				return m, nil
+			}
+			m.page = pageList
+			return m.reloadList()
+		}
+	}
+	var cmd tea.Cmd
+	s.failures, cmd = s.failures.Update(msg)
+	return m, cmd
+}
+
+func (m model) viewUploadStatus() string {
+	s := m.upload
+	p := s.progress
+	summary := fmt.Sprintf("%s files (%s) uploaded, %s failed", m.ui.format.count(p.Items-p.Failed), m.ui.format.size(p.Bytes), m.ui.format.count(p.Failed))
+	switch {
+	case s.err != nil:
+		return summary + " " + m.ui.viewError(s.err)
+	case s.running && s.files == nil:
+		return "listing files..."
+	case s.confirming:
+		return m.ui.viewWarning(fmt.Sprintf("%s keys have warnings", m.ui.format.count(len(s.warnings)))) + ", press enter to upload anyway, esc to cancel"
+	case s.running:
+		return fmt.Sprintf("uploading... %s %s of %s files (%s)%s",
+			m.ui.viewProgressBar(int64(p.Items), int64(len(s.files))), m.ui.format.count(p.Items), m.ui.format.count(len(s.files)), m.ui.format.size(s.total), m.viewThrottled())
+	case s.canceled:
+		return "canceled: " + summary
+	}
+	return "done: " + summary
+}
+
+// viewUploadFiles shows the progress of each file being uploaded.
+func (m model) viewUploadFiles() string {
+	s := m.upload
+	files := make([]*stu.UploadFile, 0, len(s.inFlight))
+	for f := range s.inFlight {
+		files = append(files, f)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Rel < files[j].Rel
+	})
+	lines := make([]string, len(files))
+	for i, f := range files {
+		lines[i] = fmt.Sprintf("%s %s (%s)", m.ui.viewProgressBar(s.inFlight[f], f.Size), f.Rel, m.ui.format.size(f.Size))
+	}
+	return itemStyle.Render(strings.Join(lines, "\n"))
+}
+
+func (m model) viewUpload() string {
+	s := m.upload
+	if m.page == pageUploadInput {
+		v := s.input.View()
+		if s.err != nil {
+			v += "  " + m.ui.viewError(s.err)
+		}
+		bc := breadcrumbStyle.Render(fmt.Sprintf("%s : %s", m.viewBreadcrumb(), v))
+		return bc + m.ui.styles.list.Render(m.list.View())
+	}
+	dst := fmt.Sprintf("s3://%s/%s", m.bucket, s.prefix)
+	bc := breadcrumbStyle.Render(fmt.Sprintf("%s : Upload %s to %s : %s", m.viewBreadcrumb(), s.path, dst, m.viewUploadStatus()))
+	if s.confirming {
+		return bc + m.ui.styles.list.Render(itemStyle.Render(strings.Join(s.warnings, "\n")))
+	}
+	if s.running {
+		return bc + m.ui.styles.list.Render(m.viewUploadFiles())
+	}
+	return bc + m.ui.styles.list.Render(s.failures.View())
+}
@@ -0,0 +1,19 @@
+package version
+
+import (
+	"runtime/debug"
+)
+
+// Version can be set with -ldflags "-X github.com/lusingander/stu/internal/version.Version=..."
+// and falls back to the module version in the build info.
+var Version = ""
+
+func Get() string {
+	if Version != "" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "(devel)"
+}
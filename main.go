@@ -1,11 +1,19 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 
 	"github.com/lusingander/stu/internal/aws"
+	"github.com/lusingander/stu/internal/cache"
+	"github.com/lusingander/stu/internal/config"
+	"github.com/lusingander/stu/internal/stu"
 	"github.com/lusingander/stu/internal/ui"
+	"github.com/lusingander/stu/internal/version"
 	"github.com/mattn/go-runewidth"
 )
 
@@ -14,12 +22,169 @@ func setup() {
 }
 
 func run(args []string) error {
+	fs := flag.NewFlagSet(args[0], flag.ExitOnError)
+	showVersion := fs.Bool("version", false, "print the version and exit")
+	offline := fs.Bool("offline", false, "browse only the cached listings without any requests")
+	profile := fs.String("profile", "", "use the profile of the shared config instead of the connection's auth")
+	region := fs.String("region", "", "use the region instead of the one of the shared config")
+	readOnly := fs.Bool("read-only", false, "disable the actions which modify or delete objects in all buckets")
+	endpointURL := fs.String("endpoint-url", os.Getenv("AWS_ENDPOINT_URL"), "use the endpoint instead of the AWS endpoints, e.g. http://localhost:4566")
+	fs.Parse(args[1:])
+
+	if *showVersion {
+		fmt.Printf("stu %s\n", version.Get())
+		return nil
+	}
+
 	setup()
-	client, err := aws.NewS3Client()
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	conn, err := cfg.CurrentConnection()
 	if err != nil {
 		return err
 	}
-	return ui.Start(client)
+	if cfg.Audit.Path == "" {
+		dir, err := cache.Dir()
+		if err != nil {
+			return err
+		}
+		cfg.Audit.Path = filepath.Join(dir, "audit.log")
+	}
+	if *readOnly {
+		cfg.ReadOnly = true
+	}
+	if *profile != "" {
+		conn = profileConnection(conn, *profile)
+	}
+	if *endpointURL != "" || *region != "" {
+		c := *conn
+		if *endpointURL != "" {
+			c.EndpointURL = *endpointURL
+		}
+		if *region != "" {
+			c.Region = *region
+		}
+		conn = &c
+	}
+	if fs.NArg() > 0 {
+		if *offline {
+			return errors.New("commands cannot be run in offline mode")
+		}
+		return runCommand(cfg, conn, fs.Args())
+	}
+	if *offline {
+		store, err := cache.Open(conn.Name)
+		if err != nil {
+			return err
+		}
+		return ui.Start(cache.NewOfflineClient(store), cfg, nil)
+	}
+	client, err := newClient(cfg, conn)
+	if err != nil {
+		return err
+	}
+	return ui.Start(client, cfg, &profileSwitcher{cfg: cfg, conn: conn})
+}
+
+func newClient(cfg *config.Config, conn *config.ConnectionConfig) (stu.Client, error) {
+	store, err := cache.Open(conn.Name)
+	if err != nil {
+		return nil, err
+	}
+	client, err := newS3Client(cfg, conn)
+	if err != nil {
+		return nil, err
+	}
+	return cache.NewRecordingClient(client, store), nil
+}
+
+func newS3Client(cfg *config.Config, conn *config.ConnectionConfig) (*aws.S3Client, error) {
+	buckets, err := bucketOverrides(cfg.BucketOverrides, cfg.ReadOnly)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := aws.NewAuthProvider(&conn.Auth)
+	if err != nil {
+		return nil, err
+	}
+	return aws.NewS3Client(&aws.Options{
+		Auth:             auth,
+		Limiter:          stu.NewRateLimiter(cfg.S3.RateLimit),
+		CorrectClockSkew: cfg.S3.CorrectClockSkew,
+		Version:          version.Get(),
+		Headers:          conn.Headers,
+		Buckets:          buckets,
+		EndpointURL:      conn.EndpointURL,
+		Region:           conn.Region,
+		Trail:            trailOptions(cfg.CloudTrail),
+	})
+}
+
+func trailOptions(cfg config.CloudTrailConfig) *aws.TrailOptions {
+	if cfg.Bucket == "" {
+		return nil
+	}
+	return &aws.TrailOptions{
+		Bucket:         cfg.Bucket,
+		Prefix:         cfg.Prefix,
+		OrganizationID: cfg.OrganizationID,
+		AccountID:      cfg.AccountID,
+		Region:         cfg.Region,
+		Days:           cfg.Days,
+	}
+}
+
+// profileConnection replaces the auth of the connection with the profile,
+// the listings are cached separately for each profile.
+func profileConnection(conn *config.ConnectionConfig, profile string) *config.ConnectionConfig {
+	c := *conn
+	c.Name = "profile-" + profile
+	c.Auth = config.AuthConfig{Provider: "profile", Profile: profile}
+	return &c
+}
+
+type profileSwitcher struct {
+	cfg  *config.Config
+	conn *config.ConnectionConfig
+}
+
+func (s *profileSwitcher) Current() string {
+	if s.conn.Auth.Provider == "profile" {
+		return s.conn.Auth.Profile
+	}
+	return ""
+}
+
+func (*profileSwitcher) Profiles() ([]string, error) {
+	return aws.Profiles()
+}
+
+func (s *profileSwitcher) Client(profile string) (stu.Client, error) {
+	return newClient(s.cfg, profileConnection(s.conn, profile))
+}
+
+// bucketOverrides makes every bucket read-only if readOnly is set, including the buckets without overrides.
+func bucketOverrides(cfgs []config.BucketOverrideConfig, readOnly bool) (*stu.BucketOverrides, error) {
+	o := stu.NewBucketOverrides()
+	for _, c := range cfgs {
+		err := o.Add(c.Name, &stu.BucketSettings{
+			StorageClass:  c.StorageClass,
+			RequesterPays: c.RequesterPays,
+			SSEKMSKeyID:   c.SSEKMSKeyID,
+			ReadOnly:      c.ReadOnly || readOnly,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if readOnly {
+		if err := o.Add("*", &stu.BucketSettings{ReadOnly: true}); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
 }
 
 func main() {